@@ -0,0 +1,171 @@
+package backpacktest
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/enums"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/types"
+)
+
+// collateralAsset is the asset collateral is valued in. Other assets are
+// valued at the price of their market against it, or zero without one.
+const collateralAsset = "USDC"
+
+// SetPositions replaces the open futures positions of the account. The
+// server does not trade futures; positions are reported as given.
+func (s *Server) SetPositions(positions ...types.Position) {
+	s.mu.Lock()
+	s.positions = append([]types.Position(nil), positions...)
+	s.mu.Unlock()
+}
+
+// Deposit credits the account with amount of asset and records a confirmed
+// deposit.
+func (s *Server) Deposit(asset, amount string) {
+	qty := types.MustParseDecimal(amount)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b := s.balanceLocked(asset)
+	b.available = b.available.Add(qty)
+	s.deposits = append(s.deposits, types.Deposit{
+		ID:        int32(len(s.deposits) + 1),
+		Source:    enums.DepositSourceAdministrator,
+		Status:    enums.DepositStatusConfirmed,
+		Symbol:    enums.CustodyAsset(asset),
+		Quantity:  qty.String(),
+		CreatedAt: time.Now().UTC().Format(timeLayout),
+	})
+}
+
+func (s *Server) positionsFor(query map[string]string) []types.Position {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	positions := []types.Position{}
+	for _, p := range s.positions {
+		if query["symbol"] == "" || p.Symbol == query["symbol"] {
+			positions = append(positions, p)
+		}
+	}
+	return positions
+}
+
+// account returns the account settings, with no fees and no leverage.
+func (s *Server) account() types.Account {
+	return types.Account{
+		AutoBorrowSettlements: true,
+		AutoRealizePnl:        true,
+		AutoRepayBorrows:      true,
+		BorrowLimit:           "0",
+		FuturesMakerFee:       "0",
+		FuturesTakerFee:       "0",
+		LeverageLimit:         "1",
+		LimitOrders:           1000,
+		PositionLimit:         "0",
+		SpotMakerFee:          "0",
+		SpotTakerFee:          "0",
+		TriggerOrders:         1000,
+	}
+}
+
+// collateral values the balances of the account in collateralAsset, each
+// with full weight, and adds the unrealized PnL of the open positions. The
+// margin fraction is only reported while positions are open.
+func (s *Server) collateral() types.MarginAccountSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var available, locked, pnl, exposure types.Decimal
+	items := []types.CollateralItem{}
+	for asset, b := range s.balances {
+		mark := s.valueLocked(asset)
+		total := b.available.Add(b.locked)
+		notional := total.Mul(mark)
+		available = available.Add(b.available.Mul(mark))
+		locked = locked.Add(b.locked.Mul(mark))
+		items = append(items, types.CollateralItem{
+			Symbol:            enums.CustodyAsset(asset),
+			AssetMarkPrice:    mark.String(),
+			TotalQuantity:     total.String(),
+			BalanceNotional:   notional.String(),
+			CollateralWeight:  "1",
+			CollateralValue:   notional.String(),
+			OpenOrderQuantity: b.locked.String(),
+			LendQuantity:      "0",
+			AvailableQuantity: b.available.String(),
+		})
+	}
+	for _, p := range s.positions {
+		if v, err := types.ParseDecimal(p.PnlUnrealized); err == nil {
+			pnl = pnl.Add(v)
+		}
+		if v, err := types.ParseDecimal(p.NetExposureNotional); err == nil {
+			exposure = exposure.Add(v.Abs())
+		}
+	}
+
+	assets := available.Add(locked)
+	equity := assets.Add(pnl)
+	summary := types.MarginAccountSummary{
+		AssetsValue:        assets.String(),
+		BorrowLiability:    "0",
+		Collateral:         items,
+		IMF:                "0",
+		UnsettledEquity:    pnl.String(),
+		LiabilitiesValue:   "0",
+		MMF:                "0",
+		NetEquity:          equity.String(),
+		NetEquityAvailable: available.Add(pnl).String(),
+		NetEquityLocked:    locked.String(),
+		NetExposureFutures: exposure.String(),
+		PnlUnrealized:      pnl.String(),
+	}
+	if exposure.IsPositive() {
+		summary.MarginFraction = equity.Div(exposure, 8).String()
+	}
+	return summary
+}
+
+// valueLocked returns the price of an asset in collateralAsset.
+func (s *Server) valueLocked(asset string) types.Decimal {
+	if asset == collateralAsset {
+		return types.NewDecimalFromInt(1)
+	}
+	for _, m := range s.markets {
+		if string(m.BaseSymbol) == asset && string(m.QuoteSymbol) == collateralAsset {
+			return s.prices[m.Symbol]
+		}
+	}
+	return types.Decimal{}
+}
+
+// depositHistory returns the deposits of the account, newest first.
+func (s *Server) depositHistory(query map[string]string) []types.Deposit {
+	s.mu.Lock()
+	deposits := make([]types.Deposit, 0, len(s.deposits))
+	for i := len(s.deposits) - 1; i >= 0; i-- {
+		d := s.deposits[i]
+		if inRange(d.CreatedAt, query) {
+			deposits = append(deposits, d)
+		}
+	}
+	s.mu.Unlock()
+	return page(deposits, query)
+}
+
+// inRange reports whether a record time is within the from and to query
+// parameters, in milliseconds.
+func inRange(timestamp string, query map[string]string) bool {
+	t, err := time.Parse(timeLayout, timestamp)
+	if err != nil {
+		return true
+	}
+	if from, err := strconv.ParseInt(query["from"], 10, 64); err == nil && t.UnixMilli() < from {
+		return false
+	}
+	if to, err := strconv.ParseInt(query["to"], 10, 64); err == nil && t.UnixMilli() > to {
+		return false
+	}
+	return true
+}
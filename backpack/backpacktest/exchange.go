@@ -0,0 +1,681 @@
+package backpacktest
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/enums"
+	bperrors "github.com/solomeowl/backpack-exchange-sdk-go/backpack/errors"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/types"
+)
+
+// timeLayout is the format of the timestamps of fills and history records.
+const timeLayout = "2006-01-02T15:04:05.000"
+
+// balance is the holding of an asset.
+type balance struct {
+	available types.Decimal
+	locked    types.Decimal
+}
+
+// order is an order known to the server.
+//
+// Orders execute against the market price set with SetPrice rather than
+// against each other: a marketable order fills entirely at the market price
+// as taker, and a resting limit order fills entirely at its limit price as
+// maker once the market price reaches it.
+type order struct {
+	types.Order
+
+	market       types.Market
+	price        types.Decimal
+	quantity     types.Decimal
+	quoteQty     types.Decimal
+	executed     types.Decimal
+	executedQuot types.Decimal
+	locked       types.Decimal // Funds locked by the resting order
+	triggerPrice types.Decimal
+	triggerAbove bool // Whether the trigger fires on the price rising to triggerPrice
+}
+
+func (o *order) open() bool {
+	switch o.Status {
+	case enums.OrderStatusNew, enums.OrderStatusPartiallyFilled, enums.OrderStatusTriggerPending:
+		return true
+	}
+	return false
+}
+
+// SetBalance sets the available balance of an asset, keeping locked funds.
+func (s *Server) SetBalance(asset, amount string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.balanceLocked(asset).available = types.MustParseDecimal(amount)
+}
+
+// Balances returns the balances of the account.
+func (s *Server) Balances() types.Balances {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	balances := make(types.Balances, len(s.balances))
+	for asset, b := range s.balances {
+		balances[asset] = types.Balance{Available: b.available.String(), Locked: b.locked.String(), Staked: "0"}
+	}
+	return balances
+}
+
+func (s *Server) balanceLocked(asset string) *balance {
+	b := s.balances[asset]
+	if b == nil {
+		b = &balance{}
+		s.balances[asset] = b
+	}
+	return b
+}
+
+// SetMarketState changes the order book state of a market, e.g. to test
+// orders rejected while a market is closed.
+func (s *Server) SetMarketState(symbol string, state enums.OrderBookState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.markets {
+		if s.markets[i].Symbol == symbol {
+			s.markets[i].OrderBookState = state
+		}
+	}
+}
+
+// SetPrice sets the market price of a symbol. Marketable orders fill at this
+// price, resting limit orders it reaches fill at their limit price, and
+// trigger orders it crosses are triggered.
+func (s *Server) SetPrice(symbol, price string) {
+	p := types.MustParseDecimal(price)
+
+	s.mu.Lock()
+	s.prices[symbol] = p
+	var updates []types.WSOrderUpdate
+	for _, o := range s.orders {
+		if o.Symbol != symbol || !o.open() {
+			continue
+		}
+		switch {
+		case o.Status == enums.OrderStatusTriggerPending:
+			if (o.triggerAbove && !p.LessThan(o.triggerPrice)) || (!o.triggerAbove && !p.GreaterThan(o.triggerPrice)) {
+				updates = append(updates, s.triggerLocked(o)...)
+			}
+		case o.Side == enums.SideBid && !o.price.LessThan(p), o.Side == enums.SideAsk && !o.price.GreaterThan(p):
+			updates = append(updates, s.fillLocked(o, o.quantity.Sub(o.executed), o.price, true))
+		}
+	}
+	s.mu.Unlock()
+
+	s.publishOrderUpdates(updates)
+}
+
+// Order returns an order by ID, including closed orders.
+func (s *Server) Order(id string) (types.Order, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, o := range s.orders {
+		if o.ID == id {
+			return o.Order, true
+		}
+	}
+	return types.Order{}, false
+}
+
+// OpenOrders returns the open orders of a symbol, or of all symbols if
+// symbol is empty, oldest first.
+func (s *Server) OpenOrders(symbol string) []types.Order {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	orders := []types.Order{}
+	for _, o := range s.orders {
+		if o.open() && (symbol == "" || o.Symbol == symbol) {
+			orders = append(orders, o.Order)
+		}
+	}
+	return orders
+}
+
+// Fills returns every fill of the account, oldest first.
+func (s *Server) Fills() []types.Fill {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]types.Fill{}, s.fills...)
+}
+
+func (s *Server) marketLocked(symbol string) (types.Market, *apiError) {
+	for _, m := range s.markets {
+		if m.Symbol == symbol {
+			return m, nil
+		}
+	}
+	return types.Market{}, errorf(http.StatusBadRequest, bperrors.ErrCodeInvalidMarket, "market %q not found", symbol)
+}
+
+func (s *Server) execute(params map[string]any) (*types.Order, *apiError) {
+	s.mu.Lock()
+	o, updates, apiErr := s.executeLocked(params)
+	s.mu.Unlock()
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	s.publishOrderUpdates(updates)
+	return &o, nil
+}
+
+func (s *Server) executeBatch(orders []map[string]any) []types.BatchOrderResult {
+	results := make([]types.BatchOrderResult, len(orders))
+	var updates []types.WSOrderUpdate
+
+	s.mu.Lock()
+	for i, params := range orders {
+		o, u, apiErr := s.executeLocked(params)
+		if apiErr != nil {
+			results[i].Error = apiErr.message
+			continue
+		}
+		results[i].Order = &o
+		updates = append(updates, u...)
+	}
+	s.mu.Unlock()
+
+	s.publishOrderUpdates(updates)
+	return results
+}
+
+func (s *Server) executeLocked(params map[string]any) (types.Order, []types.WSOrderUpdate, *apiError) {
+	var p types.ExecuteOrderParams
+	data, _ := json.Marshal(params)
+	if err := json.Unmarshal(data, &p); err != nil {
+		return types.Order{}, nil, errorf(http.StatusBadRequest, bperrors.ErrCodeInvalidClientRequest, "invalid order: %v", err)
+	}
+
+	m, apiErr := s.marketLocked(p.Symbol)
+	if apiErr != nil {
+		return types.Order{}, nil, apiErr
+	}
+	if m.OrderBookState == enums.OrderBookStateClosed || m.OrderBookState == enums.OrderBookStateCancelOnly {
+		return types.Order{}, nil, errorf(http.StatusBadRequest, bperrors.ErrCodeTradingPaused, "market %s is %s", m.Symbol, m.OrderBookState)
+	}
+	if p.Side != enums.SideBid && p.Side != enums.SideAsk {
+		return types.Order{}, nil, errorf(http.StatusBadRequest, bperrors.ErrCodeInvalidOrder, "invalid side %q", p.Side)
+	}
+
+	o := &order{market: m}
+	o.Order = types.Order{
+		CreatedAt:             types.TimeString(strconv.FormatInt(time.Now().UnixMilli(), 10)),
+		OrderType:             p.OrderType,
+		SelfTradePrevention:   p.SelfTradePrevention,
+		Side:                  p.Side,
+		Symbol:                p.Symbol,
+		TimeInForce:           p.TimeInForce,
+		Price:                 p.Price,
+		Quantity:              p.Quantity,
+		QuoteQuantity:         p.QuoteQuantity,
+		TriggerPrice:          p.TriggerPrice,
+		TriggerBy:             p.TriggerBy,
+		TriggerQuantity:       p.TriggerQuantity,
+		PostOnly:              p.PostOnly != nil && *p.PostOnly,
+		ExecutedQuantity:      "0",
+		ExecutedQuoteQuantity: "0",
+	}
+	if o.SelfTradePrevention == "" {
+		o.SelfTradePrevention = enums.SelfTradePreventionRejectTaker
+	}
+	if o.TimeInForce == "" {
+		o.TimeInForce = enums.TimeInForceGTC
+	}
+	if p.ClientID != nil {
+		o.ClientID = *p.ClientID
+	}
+	if apiErr := o.parse(p); apiErr != nil {
+		return types.Order{}, nil, apiErr
+	}
+
+	s.nextID++
+	o.ID = strconv.FormatInt(s.nextID, 10)
+	s.orders = append(s.orders, o)
+
+	if p.TriggerPrice != "" {
+		current, ok := s.prices[o.Symbol]
+		o.triggerAbove = !ok || o.triggerPrice.GreaterThan(current)
+		o.Status = enums.OrderStatusTriggerPending
+		return o.Order, []types.WSOrderUpdate{o.update("triggerPlaced")}, nil
+	}
+	if m.OrderBookState == enums.OrderBookStatePostOnly && !o.PostOnly {
+		return s.rejectLocked(o, enums.OrderExpiryReasonPostOnlyMode)
+	}
+	if m.OrderBookState == enums.OrderBookStateLimitOnly && o.OrderType != enums.OrderTypeLimit {
+		return s.rejectLocked(o, enums.OrderExpiryReasonInvalidPrice)
+	}
+	updates, apiErr := s.placeLocked(o)
+	if apiErr != nil {
+		s.orders = s.orders[:len(s.orders)-1]
+		return types.Order{}, nil, apiErr
+	}
+	return o.Order, updates, nil
+}
+
+// parse validates the numeric fields of an order against its market filters.
+func (o *order) parse(p types.ExecuteOrderParams) *apiError {
+	f := o.market.Filters
+	decimal := func(field, value string) (types.Decimal, *apiError) {
+		d, err := types.ParseDecimal(value)
+		if err != nil || !d.IsPositive() {
+			return types.Decimal{}, errorf(http.StatusBadRequest, bperrors.ErrCodeInvalidClientRequest, "invalid %s %q", field, value)
+		}
+		return d, nil
+	}
+
+	var apiErr *apiError
+	switch {
+	case p.Quantity != "":
+		if o.quantity, apiErr = decimal("quantity", p.Quantity); apiErr != nil {
+			return apiErr
+		}
+		if step, err := types.ParseDecimal(f.Quantity.StepSize); err == nil && step.IsPositive() && !o.quantity.IsMultipleOf(step) {
+			return errorf(http.StatusBadRequest, bperrors.ErrCodeInvalidQuantity, "quantity %s is not a multiple of step size %s", p.Quantity, f.Quantity.StepSize)
+		}
+		if min, err := types.ParseDecimal(f.Quantity.MinQuantity); err == nil && o.quantity.LessThan(min) {
+			return errorf(http.StatusBadRequest, bperrors.ErrCodeInvalidQuantity, "quantity %s is below the minimum %s", p.Quantity, f.Quantity.MinQuantity)
+		}
+	case p.QuoteQuantity != "" && p.OrderType == enums.OrderTypeMarket:
+		if o.quoteQty, apiErr = decimal("quoteQuantity", p.QuoteQuantity); apiErr != nil {
+			return apiErr
+		}
+	case p.TriggerQuantity != "" && p.TriggerPrice != "":
+	default:
+		return errorf(http.StatusBadRequest, bperrors.ErrCodeInvalidClientRequest, "quantity is required")
+	}
+
+	if p.OrderType == enums.OrderTypeLimit {
+		if o.price, apiErr = decimal("price", p.Price); apiErr != nil {
+			return apiErr
+		}
+		if tick, err := types.ParseDecimal(f.Price.TickSize); err == nil && tick.IsPositive() && !o.price.IsMultipleOf(tick) {
+			return errorf(http.StatusBadRequest, bperrors.ErrCodeInvalidOrder, "price %s is not a multiple of tick size %s", p.Price, f.Price.TickSize)
+		}
+	} else if p.OrderType != enums.OrderTypeMarket {
+		return errorf(http.StatusBadRequest, bperrors.ErrCodeInvalidOrder, "invalid order type %q", p.OrderType)
+	}
+
+	if p.TriggerPrice != "" {
+		if o.triggerPrice, apiErr = decimal("triggerPrice", p.TriggerPrice); apiErr != nil {
+			return apiErr
+		}
+	}
+	return nil
+}
+
+// placeLocked executes a new or triggered order: it fills at the market
+// price if it is marketable, and otherwise rests or expires.
+func (s *Server) placeLocked(o *order) ([]types.WSOrderUpdate, *apiError) {
+	base, quote := string(o.market.BaseSymbol), string(o.market.QuoteSymbol)
+	// A zero price leaves the market unpriced rather than dividing by it.
+	mark, priced := s.prices[o.Symbol]
+	priced = priced && mark.IsPositive()
+
+	marketable := priced && (o.OrderType == enums.OrderTypeMarket ||
+		(o.Side == enums.SideBid && !o.price.LessThan(mark)) ||
+		(o.Side == enums.SideAsk && !o.price.GreaterThan(mark)))
+
+	if o.quantity.IsZero() && priced {
+		o.quantity = o.quoteQty.Div(mark, 8)
+		if step, err := types.ParseDecimal(o.market.Filters.Quantity.StepSize); err == nil && step.IsPositive() {
+			o.quantity = o.quantity.QuantizeDown(step)
+		}
+	}
+
+	switch {
+	case marketable && o.PostOnly:
+		return nil, errorf(http.StatusBadRequest, bperrors.ErrCodeInvalidOrder, "order would immediately match and take")
+	case marketable:
+		cost := o.quantity.Mul(mark)
+		if o.Side == enums.SideBid && s.balanceLocked(quote).available.LessThan(cost) ||
+			o.Side == enums.SideAsk && s.balanceLocked(base).available.LessThan(o.quantity) {
+			return nil, errorf(http.StatusBadRequest, bperrors.ErrCodeInsufficientFunds, "insufficient funds")
+		}
+		o.Status = enums.OrderStatusNew
+		accepted := o.update("orderAccepted")
+		return []types.WSOrderUpdate{accepted, s.fillLocked(o, o.quantity, mark, false)}, nil
+	case o.OrderType == enums.OrderTypeMarket:
+		o.Status = enums.OrderStatusExpired
+		o.ExpiryReason = enums.OrderExpiryReasonInsufficientLiquidity
+		return []types.WSOrderUpdate{o.update("orderExpired")}, nil
+	case o.TimeInForce == enums.TimeInForceIOC || o.TimeInForce == enums.TimeInForceFOK:
+		o.Status = enums.OrderStatusExpired
+		o.ExpiryReason = enums.OrderExpiryReasonImmediateOrCancel
+		if o.TimeInForce == enums.TimeInForceFOK {
+			o.ExpiryReason = enums.OrderExpiryReasonFillOrKill
+		}
+		return []types.WSOrderUpdate{o.update("orderExpired")}, nil
+	}
+
+	// Rest on the book, locking the funds the order may spend.
+	asset, amount := base, o.quantity
+	if o.Side == enums.SideBid {
+		asset, amount = quote, o.quantity.Mul(o.price)
+	}
+	b := s.balanceLocked(asset)
+	if b.available.LessThan(amount) {
+		return nil, errorf(http.StatusBadRequest, bperrors.ErrCodeInsufficientFunds, "insufficient funds")
+	}
+	b.available = b.available.Sub(amount)
+	b.locked = b.locked.Add(amount)
+	o.locked = amount
+	o.Status = enums.OrderStatusNew
+	s.updateID++
+	return []types.WSOrderUpdate{o.update("orderAccepted")}, nil
+}
+
+// rejectLocked expires a new order without executing it.
+func (s *Server) rejectLocked(o *order, reason enums.OrderExpiryReason) (types.Order, []types.WSOrderUpdate, *apiError) {
+	o.Status = enums.OrderStatusExpired
+	o.ExpiryReason = reason
+	return o.Order, []types.WSOrderUpdate{o.update("orderExpired")}, nil
+}
+
+// triggerLocked turns a triggered order into a market or limit order.
+func (s *Server) triggerLocked(o *order) []types.WSOrderUpdate {
+	if o.quantity.IsZero() && o.TriggerQuantity != "" {
+		if q, err := types.ParseDecimal(o.TriggerQuantity); err == nil {
+			o.quantity = q
+			o.Quantity = o.TriggerQuantity
+		}
+	}
+	o.Status = enums.OrderStatusNew
+	updates := []types.WSOrderUpdate{o.update("triggered")}
+
+	placed, apiErr := s.placeLocked(o)
+	if apiErr != nil {
+		o.Status = enums.OrderStatusTriggerFailed
+		return append(updates, o.update("triggerFailed"))
+	}
+	return append(updates, placed...)
+}
+
+// fillLocked fills qty of an order at price and settles the balances.
+func (s *Server) fillLocked(o *order, qty, price types.Decimal, maker bool) types.WSOrderUpdate {
+	base, quote := s.balanceLocked(string(o.market.BaseSymbol)), s.balanceLocked(string(o.market.QuoteSymbol))
+	notional := qty.Mul(price)
+
+	if o.Side == enums.SideBid {
+		if maker {
+			quote.locked = quote.locked.Sub(notional)
+			o.locked = o.locked.Sub(notional)
+		} else {
+			quote.available = quote.available.Sub(notional)
+		}
+		base.available = base.available.Add(qty)
+	} else {
+		if maker {
+			base.locked = base.locked.Sub(qty)
+			o.locked = o.locked.Sub(qty)
+		} else {
+			base.available = base.available.Sub(qty)
+		}
+		quote.available = quote.available.Add(notional)
+	}
+
+	o.executed = o.executed.Add(qty)
+	o.executedQuot = o.executedQuot.Add(notional)
+	o.ExecutedQuantity = o.executed.String()
+	o.ExecutedQuoteQuantity = o.executedQuot.String()
+	o.Status = enums.OrderStatusFilled
+	if o.executed.LessThan(o.quantity) {
+		o.Status = enums.OrderStatusPartiallyFilled
+	}
+	if maker {
+		s.updateID++
+	}
+
+	s.nextFill++
+	feeSymbol := string(o.market.BaseSymbol)
+	if o.Side == enums.SideAsk {
+		feeSymbol = string(o.market.QuoteSymbol)
+	}
+	fill := types.Fill{
+		Fee:       "0",
+		FeeSymbol: feeSymbol,
+		IsMaker:   maker,
+		OrderID:   o.ID,
+		Price:     price.String(),
+		Quantity:  qty.String(),
+		Side:      o.Side,
+		Symbol:    o.Symbol,
+		Timestamp: time.Now().UTC().Format(timeLayout),
+		TradeID:   s.nextFill,
+	}
+	if o.ClientID != 0 {
+		fill.ClientID = strconv.FormatUint(uint64(o.ClientID), 10)
+	}
+	s.fills = append(s.fills, fill)
+
+	u := o.update("orderFill")
+	u.TradeID = &fill.TradeID
+	u.FillQuantity = fill.Quantity
+	u.FillPrice = fill.Price
+	u.IsMaker = &maker
+	u.Fee = fill.Fee
+	u.FeeSymbol = fill.FeeSymbol
+	return u
+}
+
+// cancelLocked cancels an open order and releases its locked funds.
+func (s *Server) cancelLocked(o *order) types.WSOrderUpdate {
+	if o.locked.IsPositive() {
+		asset := string(o.market.BaseSymbol)
+		if o.Side == enums.SideBid {
+			asset = string(o.market.QuoteSymbol)
+		}
+		b := s.balanceLocked(asset)
+		b.locked = b.locked.Sub(o.locked)
+		b.available = b.available.Add(o.locked)
+		o.locked = types.Decimal{}
+		s.updateID++
+	}
+	o.Status = enums.OrderStatusCancelled
+	return o.update("orderCancelled")
+}
+
+// findLocked returns the open order of a symbol with the given order ID or
+// client ID.
+func (s *Server) findLocked(symbol, orderID, clientID string) *order {
+	for _, o := range s.orders {
+		if !o.open() || o.Symbol != symbol {
+			continue
+		}
+		if (orderID != "" && o.ID == orderID) || (orderID == "" && clientID != "" && strconv.FormatUint(uint64(o.ClientID), 10) == clientID) {
+			return o
+		}
+	}
+	return nil
+}
+
+func (s *Server) getOrder(query map[string]string) (*types.Order, *apiError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o := s.findLocked(query["symbol"], query["orderId"], query["clientId"])
+	if o == nil {
+		return nil, errorf(http.StatusNotFound, bperrors.ErrCodeResourceNotFound, "order not found")
+	}
+	return &o.Order, nil
+}
+
+func (s *Server) cancel(params map[string]any) (*types.Order, *apiError) {
+	symbol, _ := params["symbol"].(string)
+	orderID, _ := params["orderId"].(string)
+	var clientID string
+	if id, ok := params["clientId"].(float64); ok {
+		clientID = strconv.FormatFloat(id, 'f', -1, 64)
+	}
+
+	s.mu.Lock()
+	o := s.findLocked(symbol, orderID, clientID)
+	if o == nil {
+		s.mu.Unlock()
+		return nil, errorf(http.StatusNotFound, bperrors.ErrCodeResourceNotFound, "order not found")
+	}
+	update := s.cancelLocked(o)
+	result := o.Order
+	s.mu.Unlock()
+
+	s.publishOrderUpdates([]types.WSOrderUpdate{update})
+	return &result, nil
+}
+
+func (s *Server) cancelAll(params map[string]any) ([]types.Order, *apiError) {
+	symbol, _ := params["symbol"].(string)
+	orderType, _ := params["orderType"].(string)
+
+	s.mu.Lock()
+	cancelled := []types.Order{}
+	var updates []types.WSOrderUpdate
+	for _, o := range s.orders {
+		if !o.open() || o.Symbol != symbol {
+			continue
+		}
+		conditional := o.Status == enums.OrderStatusTriggerPending
+		if (orderType == string(enums.CancelOrderTypeResting) && conditional) ||
+			(orderType == string(enums.CancelOrderTypeConditional) && !conditional) {
+			continue
+		}
+		updates = append(updates, s.cancelLocked(o))
+		cancelled = append(cancelled, o.Order)
+	}
+	s.mu.Unlock()
+
+	s.publishOrderUpdates(updates)
+	return cancelled, nil
+}
+
+func (s *Server) orderHistory(query map[string]string) []types.Order {
+	s.mu.Lock()
+	var orders []types.Order
+	for i := len(s.orders) - 1; i >= 0; i-- {
+		o := s.orders[i]
+		if (query["symbol"] == "" || o.Symbol == query["symbol"]) && (query["orderId"] == "" || o.ID == query["orderId"]) {
+			orders = append(orders, o.Order)
+		}
+	}
+	s.mu.Unlock()
+	return page(orders, query)
+}
+
+func (s *Server) fillHistory(query map[string]string) []types.Fill {
+	s.mu.Lock()
+	var fills []types.Fill
+	for i := len(s.fills) - 1; i >= 0; i-- {
+		f := s.fills[i]
+		if (query["symbol"] == "" || f.Symbol == query["symbol"]) && (query["orderId"] == "" || f.OrderID == query["orderId"]) {
+			fills = append(fills, f)
+		}
+	}
+	s.mu.Unlock()
+	return page(fills, query)
+}
+
+// page applies the limit and offset query parameters to newest-first
+// history, reversing it if sortDirection is Asc.
+func page[T any](items []T, query map[string]string) []T {
+	if query["sortDirection"] == string(enums.SortDirectionAsc) {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+	offset, _ := strconv.Atoi(query["offset"])
+	limit, err := strconv.Atoi(query["limit"])
+	if err != nil || limit <= 0 {
+		limit = 100
+	}
+	if offset >= len(items) {
+		return []T{}
+	}
+	items = items[offset:]
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return items
+}
+
+// depth aggregates the resting limit orders of a symbol into an order book.
+func (s *Server) depth(symbol string) (*types.OrderBook, *apiError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, apiErr := s.marketLocked(symbol); apiErr != nil {
+		return nil, apiErr
+	}
+
+	bids, asks := make(map[string]types.Decimal), make(map[string]types.Decimal)
+	for _, o := range s.orders {
+		if o.Symbol != symbol || (o.Status != enums.OrderStatusNew && o.Status != enums.OrderStatusPartiallyFilled) || o.OrderType != enums.OrderTypeLimit {
+			continue
+		}
+		levels := asks
+		if o.Side == enums.SideBid {
+			levels = bids
+		}
+		levels[o.price.String()] = levels[o.price.String()].Add(o.quantity.Sub(o.executed))
+	}
+
+	return &types.OrderBook{
+		Bids:         sortLevels(bids),
+		Asks:         sortLevels(asks),
+		LastUpdateID: strconv.FormatInt(s.updateID, 10),
+		Timestamp:    time.Now().UnixMicro(),
+	}, nil
+}
+
+// sortLevels lists price levels in ascending price order, as the REST API does.
+func sortLevels(levels map[string]types.Decimal) [][]string {
+	prices := make([]types.Decimal, 0, len(levels))
+	for p := range levels {
+		prices = append(prices, types.MustParseDecimal(p))
+	}
+	sort.Slice(prices, func(i, j int) bool { return prices[i].LessThan(prices[j]) })
+
+	result := make([][]string, len(prices))
+	for i, p := range prices {
+		result[i] = []string{p.String(), levels[p.String()].String()}
+	}
+	return result
+}
+
+// update describes the current state of an order as an order update event.
+func (o *order) update(event string) types.WSOrderUpdate {
+	now := time.Now().UnixMicro()
+	u := types.WSOrderUpdate{
+		Event:                 event,
+		EventTime:             now,
+		Symbol:                o.Symbol,
+		Side:                  o.Side,
+		OrderType:             string(o.OrderType),
+		TimeInForce:           string(o.TimeInForce),
+		Quantity:              o.Quantity,
+		QuoteQuantity:         o.QuoteQuantity,
+		Price:                 o.Price,
+		TriggerPrice:          o.TriggerPrice,
+		TriggerQuantity:       o.TriggerQuantity,
+		Status:                o.Status,
+		ExpiryReason:          o.ExpiryReason,
+		OrderID:               types.WSID(o.ID),
+		ExecutedQuantity:      o.ExecutedQuantity,
+		ExecutedQuoteQuantity: o.ExecutedQuoteQuantity,
+		SelfTradePrevention:   o.SelfTradePrevention,
+		EngineTimestamp:       now,
+	}
+	if o.ClientID != 0 {
+		clientID := o.ClientID
+		u.ClientID = &clientID
+	}
+	if o.PostOnly {
+		postOnly := true
+		u.PostOnly = &postOnly
+	}
+	return u
+}
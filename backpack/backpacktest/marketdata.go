@@ -0,0 +1,243 @@
+package backpacktest
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/enums"
+	bperrors "github.com/solomeowl/backpack-exchange-sdk-go/backpack/errors"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/types"
+)
+
+// Public market data is derived from the account's fills, which are the
+// only trades on the fake exchange, and from the prices set with SetPrice.
+
+// trade is a fill of a market, as public market data sees it.
+type trade struct {
+	types.Fill
+	time     time.Time
+	price    types.Decimal
+	quantity types.Decimal
+}
+
+// tradesLocked returns the trades of a symbol since a time, oldest first.
+func (s *Server) tradesLocked(symbol string, since time.Time) []trade {
+	var trades []trade
+	for _, f := range s.fills {
+		if f.Symbol != symbol {
+			continue
+		}
+		t, err := time.Parse(timeLayout, f.Timestamp)
+		if err != nil || t.Before(since) {
+			continue
+		}
+		trades = append(trades, trade{
+			Fill:     f,
+			time:     t,
+			price:    types.MustParseDecimal(f.Price),
+			quantity: types.MustParseDecimal(f.Quantity),
+		})
+	}
+	return trades
+}
+
+func (s *Server) ticker(symbol string, interval enums.TickerInterval) (*types.Ticker, *apiError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, apiErr := s.marketLocked(symbol); apiErr != nil {
+		return nil, apiErr
+	}
+	return s.tickerLocked(symbol, interval), nil
+}
+
+func (s *Server) tickers(interval enums.TickerInterval) []types.Ticker {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tickers := make([]types.Ticker, 0, len(s.markets))
+	for _, m := range s.markets {
+		tickers = append(tickers, *s.tickerLocked(m.Symbol, interval))
+	}
+	return tickers
+}
+
+// tickerLocked summarizes the trades of a symbol over the last day, or week
+// for TickerInterval1w. Without trades the ticker shows the market price.
+func (s *Server) tickerLocked(symbol string, interval enums.TickerInterval) *types.Ticker {
+	period := 24 * time.Hour
+	if interval == enums.TickerInterval1w {
+		period = 7 * 24 * time.Hour
+	}
+	trades := s.tradesLocked(symbol, time.Now().Add(-period))
+
+	price := s.prices[symbol]
+	k := summarize(trades, price)
+	change := k.last.Sub(k.first)
+	var percent types.Decimal
+	if k.first.IsPositive() {
+		percent = change.Mul(types.NewDecimalFromInt(100)).Div(k.first, 2)
+	}
+	return &types.Ticker{
+		Symbol:             symbol,
+		FirstPrice:         k.first.String(),
+		LastPrice:          k.last.String(),
+		PriceChange:        change.String(),
+		PriceChangePercent: percent.String(),
+		High:               k.high.String(),
+		Low:                k.low.String(),
+		Volume:             k.volume.String(),
+		QuoteVolume:        k.quoteVolume.String(),
+		Trades:             strconv.Itoa(len(trades)),
+	}
+}
+
+// recentTrades returns the latest trades of a symbol, oldest first.
+func (s *Server) recentTrades(query map[string]string) ([]types.Trade, *apiError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, apiErr := s.marketLocked(query["symbol"]); apiErr != nil {
+		return nil, apiErr
+	}
+	trades := s.tradesLocked(query["symbol"], time.Time{})
+	limit, err := strconv.Atoi(query["limit"])
+	if err != nil || limit <= 0 {
+		limit = 100
+	}
+	if len(trades) > limit {
+		trades = trades[len(trades)-limit:]
+	}
+
+	result := make([]types.Trade, len(trades))
+	for i, t := range trades {
+		result[i] = types.Trade{
+			ID:            t.TradeID,
+			Price:         t.Price,
+			Quantity:      t.Quantity,
+			QuoteQuantity: t.price.Mul(t.quantity).String(),
+			Timestamp:     t.time.UnixMilli(),
+			IsBuyerMaker:  t.IsMaker == (t.Side == enums.SideBid),
+		}
+	}
+	return result, nil
+}
+
+// klineIntervals are the durations of the fixed-length kline intervals.
+var klineIntervals = map[enums.KlineInterval]time.Duration{
+	enums.KlineInterval1m:  time.Minute,
+	enums.KlineInterval3m:  3 * time.Minute,
+	enums.KlineInterval5m:  5 * time.Minute,
+	enums.KlineInterval15m: 15 * time.Minute,
+	enums.KlineInterval30m: 30 * time.Minute,
+	enums.KlineInterval1h:  time.Hour,
+	enums.KlineInterval2h:  2 * time.Hour,
+	enums.KlineInterval4h:  4 * time.Hour,
+	enums.KlineInterval6h:  6 * time.Hour,
+	enums.KlineInterval8h:  8 * time.Hour,
+	enums.KlineInterval12h: 12 * time.Hour,
+	enums.KlineInterval1d:  24 * time.Hour,
+	enums.KlineInterval3d:  3 * 24 * time.Hour,
+	enums.KlineInterval1w:  7 * 24 * time.Hour,
+}
+
+// klines aggregates the trades of a symbol into candles between startTime
+// and endTime, in seconds. Intervals without trades are left out.
+func (s *Server) klines(query map[string]string) ([]types.Kline, *apiError) {
+	interval := enums.KlineInterval(query["interval"])
+	length, fixed := klineIntervals[interval]
+	if !fixed && interval != enums.KlineInterval1Month {
+		return nil, errorf(http.StatusBadRequest, bperrors.ErrCodeInvalidClientRequest, "invalid interval %q", interval)
+	}
+	start, err := strconv.ParseInt(query["startTime"], 10, 64)
+	if err != nil {
+		return nil, errorf(http.StatusBadRequest, bperrors.ErrCodeInvalidClientRequest, "invalid startTime %q", query["startTime"])
+	}
+	end := time.Now()
+	if v, err := strconv.ParseInt(query["endTime"], 10, 64); err == nil {
+		end = time.Unix(v, 0)
+	}
+	// Candles start on interval boundaries: the zero time is a Monday, so
+	// truncation also aligns weeks.
+	bucket := func(t time.Time) (time.Time, time.Time) {
+		if fixed {
+			t = t.Truncate(length)
+			return t, t.Add(length)
+		}
+		t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return t, t.AddDate(0, 1, 0)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, apiErr := s.marketLocked(query["symbol"]); apiErr != nil {
+		return nil, apiErr
+	}
+	klines := []types.Kline{}
+	trades := s.tradesLocked(query["symbol"], time.Unix(start, 0))
+	for i := 0; i < len(trades) && trades[i].time.Before(end); {
+		from, to := bucket(trades[i].time)
+		j := i
+		for j < len(trades) && trades[j].time.Before(to) && trades[j].time.Before(end) {
+			j++
+		}
+		k := summarize(trades[i:j], types.Decimal{})
+		klines = append(klines, types.Kline{
+			Start:       from.Format("2006-01-02T15:04:05"),
+			End:         to.Format("2006-01-02T15:04:05"),
+			Open:        k.first.String(),
+			High:        k.high.String(),
+			Low:         k.low.String(),
+			Close:       k.last.String(),
+			Volume:      k.volume.String(),
+			QuoteVolume: k.quoteVolume.String(),
+			Trades:      strconv.Itoa(j - i),
+		})
+		i = j
+	}
+	return klines, nil
+}
+
+// markPrices returns the prices of the markets of a type, PERP by default,
+// that have one.
+func (s *Server) markPrices(query map[string]string) []types.MarkPrice {
+	marketType := enums.MarketType(query["marketType"])
+	if marketType == "" {
+		marketType = enums.MarketTypePerp
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prices := []types.MarkPrice{}
+	for _, m := range s.markets {
+		price, ok := s.prices[m.Symbol]
+		if !ok || m.MarketType != marketType || (query["symbol"] != "" && m.Symbol != query["symbol"]) {
+			continue
+		}
+		prices = append(prices, types.MarkPrice{Symbol: m.Symbol, MarkPrice: price.String(), IndexPrice: price.String()})
+	}
+	return prices
+}
+
+// candle is the summary of a run of trades.
+type candle struct {
+	first, last, high, low types.Decimal
+	volume, quoteVolume    types.Decimal
+}
+
+// summarize summarizes trades, or reports price throughout if there are none.
+func summarize(trades []trade, price types.Decimal) candle {
+	if len(trades) == 0 {
+		return candle{first: price, last: price, high: price, low: price}
+	}
+	k := candle{first: trades[0].price, last: trades[len(trades)-1].price, high: trades[0].price, low: trades[0].price}
+	for _, t := range trades {
+		if t.price.GreaterThan(k.high) {
+			k.high = t.price
+		}
+		if t.price.LessThan(k.low) {
+			k.low = t.price
+		}
+		k.volume = k.volume.Add(t.quantity)
+		k.quoteVolume = k.quoteVolume.Add(t.price.Mul(t.quantity))
+	}
+	return k
+}
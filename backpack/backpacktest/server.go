@@ -0,0 +1,467 @@
+// Package backpacktest provides an in-process fake Backpack Exchange for
+// tests. It serves the REST endpoints called by the SDK's services and the
+// WebSocket subscription protocol from an httptest.Server, verifies request
+// signatures, keeps balances and orders in memory, and can inject errors,
+// latency and disconnects.
+package backpacktest
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/enums"
+	bperrors "github.com/solomeowl/backpack-exchange-sdk-go/backpack/errors"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/types"
+)
+
+// Fault describes an injected failure of REST requests.
+type Fault struct {
+	Method string // HTTP method to match; empty matches any
+	Path   string // Endpoint path to match, e.g. "api/v1/order"; empty matches any
+
+	Status  int    // HTTP status of the error response; defaults to 500 if Code is set
+	Code    string // Error code of the response, e.g. "TOO_MANY_REQUESTS"
+	Message string // Error message of the response
+
+	Delay time.Duration // Extra latency before responding; alone, it only slows requests down
+	Drop  bool          // Close the connection instead of responding
+	Times int           // Number of matching requests to fail; zero fails all until ClearFaults
+}
+
+// Server is a fake Backpack Exchange. It is safe for concurrent use.
+type Server struct {
+	srv       *httptest.Server
+	publicKey string
+	secretKey string
+	upgrader  websocket.Upgrader
+
+	mu       sync.Mutex
+	keys     map[string]ed25519.PublicKey
+	latency  time.Duration
+	faults   []*Fault
+	markets  []types.Market
+	prices   map[string]types.Decimal
+	balances map[string]*balance
+	orders   []*order
+	fills    []types.Fill
+	nextID   int64
+	nextFill int64
+	updateID int64
+
+	// Account state reported as set by SetPositions and Deposit.
+	positions []types.Position
+	deposits  []types.Deposit
+
+	wsMu  sync.Mutex
+	conns map[*wsConn]bool
+}
+
+// Option is a functional option for configuring a Server.
+type Option func(*Server)
+
+// WithMarkets sets the markets listed by the server, replacing the default
+// SOL_USDC and BTC_USDC spot markets.
+func WithMarkets(markets ...types.Market) Option {
+	return func(s *Server) {
+		s.markets = append([]types.Market(nil), markets...)
+	}
+}
+
+// WithBalance credits the account with an available balance of asset.
+func WithBalance(asset, amount string) Option {
+	return func(s *Server) {
+		s.balances[asset] = &balance{available: types.MustParseDecimal(amount)}
+	}
+}
+
+// WithPrice sets the initial price of a market. See SetPrice.
+func WithPrice(symbol, price string) Option {
+	return func(s *Server) {
+		s.prices[symbol] = types.MustParseDecimal(price)
+	}
+}
+
+// NewServer starts a fake exchange with a freshly generated API key pair,
+// available through Credentials. Close must be called to shut it down.
+func NewServer(opts ...Option) *Server {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		panic(fmt.Sprintf("backpacktest: failed to generate key: %v", err))
+	}
+
+	s := &Server{
+		publicKey: base64.StdEncoding.EncodeToString(publicKey),
+		secretKey: base64.StdEncoding.EncodeToString(privateKey.Seed()),
+		keys:      make(map[string]ed25519.PublicKey),
+		markets:   DefaultMarkets(),
+		prices:    make(map[string]types.Decimal),
+		balances:  make(map[string]*balance),
+		conns:     make(map[*wsConn]bool),
+	}
+	s.keys[s.publicKey] = publicKey
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// DefaultMarkets returns the markets listed by a Server by default.
+func DefaultMarkets() []types.Market {
+	return []types.Market{
+		spotMarket("SOL", "USDC", "0.01", "0.01"),
+		spotMarket("BTC", "USDC", "0.1", "0.00001"),
+	}
+}
+
+func spotMarket(base, quote, tickSize, stepSize string) types.Market {
+	return types.Market{
+		Symbol:      base + "_" + quote,
+		BaseSymbol:  enums.CustodyAsset(base),
+		QuoteSymbol: enums.CustodyAsset(quote),
+		MarketType:  enums.MarketTypeSpot,
+		Filters: types.OrderBookFilters{
+			Price:    types.PriceFilter{MinPrice: tickSize, TickSize: tickSize},
+			Quantity: types.QuantityFilter{MinQuantity: stepSize, StepSize: stepSize},
+		},
+		OrderBookState: enums.OrderBookStateOpen,
+		Visible:        true,
+	}
+}
+
+// Close shuts down the server and closes every WebSocket connection.
+func (s *Server) Close() {
+	s.DisconnectAll()
+	s.srv.Close()
+}
+
+// URL returns the base URL of the REST API, for backpack.WithBaseURL.
+func (s *Server) URL() string {
+	return s.srv.URL
+}
+
+// WSURL returns the URL of the WebSocket API, for websocket.WithWSURL.
+func (s *Server) WSURL() string {
+	return "ws" + strings.TrimPrefix(s.srv.URL, "http")
+}
+
+// Credentials returns the API key pair accepted by the server, for
+// backpack.WithCredentials.
+func (s *Server) Credentials() (publicKey, secretKey string) {
+	return s.publicKey, s.secretKey
+}
+
+// AddKey makes the server accept requests signed by another key, given as
+// a base64-encoded ED25519 public key.
+func (s *Server) AddKey(publicKey string) error {
+	key, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("backpacktest: invalid public key")
+	}
+	s.mu.Lock()
+	s.keys[publicKey] = ed25519.PublicKey(key)
+	s.mu.Unlock()
+	return nil
+}
+
+// SetLatency delays every REST response by d.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	s.latency = d
+	s.mu.Unlock()
+}
+
+// Inject makes matching REST requests fail as described by f. Faults are
+// checked in the order they were injected.
+func (s *Server) Inject(f Fault) {
+	s.mu.Lock()
+	s.faults = append(s.faults, &f)
+	s.mu.Unlock()
+}
+
+// ClearFaults removes every injected fault.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	s.faults = nil
+	s.mu.Unlock()
+}
+
+// fault returns the injected fault matching a request, if any, consuming one
+// of its occurrences.
+func (s *Server) fault(method, path string) (Fault, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, f := range s.faults {
+		if (f.Method != "" && f.Method != method) || (f.Path != "" && f.Path != path) {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+		return *f, true
+	}
+	return Fault{}, false
+}
+
+// apiError is an error response of the REST API.
+type apiError struct {
+	status  int
+	code    bperrors.ApiErrorCode
+	message string
+}
+
+func errorf(status int, code bperrors.ApiErrorCode, format string, args ...any) *apiError {
+	return &apiError{status: status, code: code, message: fmt.Sprintf(format, args...)}
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if websocket.IsWebSocketUpgrade(r) {
+		s.serveWS(w, r)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/")
+
+	s.mu.Lock()
+	latency := s.latency
+	s.mu.Unlock()
+	f, faulty := s.fault(r.Method, path)
+	if faulty {
+		latency += f.Delay
+	}
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if faulty && (f.Drop || f.Status != 0 || f.Code != "") {
+		if f.Drop {
+			if hj, ok := w.(http.Hijacker); ok {
+				if conn, _, err := hj.Hijack(); err == nil {
+					conn.Close()
+					return
+				}
+			}
+		}
+		status := f.Status
+		if status == 0 {
+			status = http.StatusInternalServerError
+		}
+		writeJSON(w, status, map[string]string{"code": f.Code, "message": f.Message})
+		return
+	}
+
+	result, apiErr := s.route(r, path)
+	if apiErr != nil {
+		writeJSON(w, apiErr.status, map[string]string{"code": string(apiErr.code), "message": apiErr.message})
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if v != nil {
+		json.NewEncoder(w).Encode(v)
+	}
+}
+
+// instructions maps authenticated endpoints to their signing instruction.
+var instructions = map[string]string{
+	"GET api/v1/account":                       "accountQuery",
+	"GET api/v1/capital":                       "balanceQuery",
+	"GET api/v1/capital/collateral":            "collateralQuery",
+	"GET api/v1/position":                      "positionQuery",
+	"GET wapi/v1/capital/deposits":             "depositQueryAll",
+	"GET wapi/v1/capital/withdrawals":          "withdrawalQueryAll",
+	"GET api/v1/order":                         "orderQuery",
+	"POST api/v1/order":                        "orderExecute",
+	"DELETE api/v1/order":                      "orderCancel",
+	"GET api/v1/orders":                        "orderQueryAll",
+	"POST api/v1/orders":                       "orderExecute",
+	"DELETE api/v1/orders":                     "orderCancelAll",
+	"GET wapi/v1/history/borrowLend":           "borrowHistoryQueryAll",
+	"GET wapi/v1/history/interest":             "interestHistoryQueryAll",
+	"GET wapi/v1/history/borrowLend/positions": "borrowPositionHistoryQueryAll",
+	"GET wapi/v1/history/fills":                "fillHistoryQueryAll",
+	"GET wapi/v1/history/funding":              "fundingHistoryQueryAll",
+	"GET wapi/v1/history/orders":               "orderHistoryQueryAll",
+	"GET wapi/v1/history/settlement":           "settlementHistoryQueryAll",
+	"GET wapi/v1/history/dust":                 "dustHistoryQueryAll",
+	"GET wapi/v1/history/rfq":                  "rfqHistoryQueryAll",
+	"GET wapi/v1/history/quote":                "quoteHistoryQueryAll",
+	"GET wapi/v1/history/rfq/fill":             "rfqFillHistoryQueryAll",
+	"GET wapi/v1/history/quote/fill":           "quoteFillHistoryQueryAll",
+	"GET wapi/v1/history/strategies":           "strategyHistoryQueryAll",
+	"GET wapi/v1/history/position":             "positionHistoryQueryAll",
+}
+
+func (s *Server) route(r *http.Request, path string) (any, *apiError) {
+	query := make(map[string]string)
+	for k := range r.URL.Query() {
+		query[k] = r.URL.Query().Get(k)
+	}
+
+	key := r.Method + " " + path
+	if instruction, ok := instructions[key]; ok {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, errorf(http.StatusBadRequest, bperrors.ErrCodeInvalidClientRequest, "invalid body: %v", err)
+		}
+
+		if key == "POST api/v1/orders" {
+			signed, err := batchParams(body)
+			if err != nil {
+				return nil, errorf(http.StatusBadRequest, bperrors.ErrCodeInvalidClientRequest, "invalid body: %v", err)
+			}
+			if apiErr := s.verify(r, func(timestamp, window int64) string {
+				return signedBatchMessage(signed, timestamp, window)
+			}); apiErr != nil {
+				return nil, apiErr
+			}
+			var orders []map[string]any
+			json.Unmarshal(body, &orders)
+			return s.executeBatch(orders), nil
+		}
+
+		params := make(map[string]any)
+		signed := query
+		if r.Method == http.MethodGet {
+			for k, v := range query {
+				params[k] = v
+			}
+		} else {
+			if signed, err = bodyParams(body); err != nil {
+				return nil, errorf(http.StatusBadRequest, bperrors.ErrCodeInvalidClientRequest, "invalid body: %v", err)
+			}
+			json.Unmarshal(body, &params)
+		}
+		if apiErr := s.verify(r, func(timestamp, window int64) string {
+			return signedMessage(instruction, signed, timestamp, window)
+		}); apiErr != nil {
+			return nil, apiErr
+		}
+		return s.private(key, query, params)
+	}
+
+	switch key {
+	case "GET api/v1/status":
+		return types.SystemStatus{Status: enums.SystemStatusOk}, nil
+	case "GET api/v1/ping":
+		return "pong", nil
+	case "GET api/v1/time":
+		return time.Now().UnixMilli(), nil
+	case "GET api/v1/markets":
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return append([]types.Market{}, s.markets...), nil
+	case "GET api/v1/market":
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		m, apiErr := s.marketLocked(query["symbol"])
+		if apiErr != nil {
+			return nil, apiErr
+		}
+		return m, nil
+	case "GET api/v1/depth":
+		return s.depth(query["symbol"])
+	case "GET api/v1/ticker":
+		return s.ticker(query["symbol"], enums.TickerInterval(query["interval"]))
+	case "GET api/v1/tickers":
+		return s.tickers(enums.TickerInterval(query["interval"])), nil
+	case "GET api/v1/trades":
+		return s.recentTrades(query)
+	case "GET api/v1/klines":
+		return s.klines(query)
+	case "GET api/v1/markPrices":
+		return s.markPrices(query), nil
+	case "GET api/v1/assets", "GET api/v1/collateral":
+		return []any{}, nil
+	}
+	return nil, errorf(http.StatusNotFound, bperrors.ErrCodeResourceNotFound, "%s %s not found", r.Method, path)
+}
+
+func (s *Server) private(key string, query map[string]string, params map[string]any) (any, *apiError) {
+	switch key {
+	case "GET api/v1/account":
+		return s.account(), nil
+	case "GET api/v1/capital":
+		return s.Balances(), nil
+	case "GET api/v1/capital/collateral":
+		return s.collateral(), nil
+	case "GET api/v1/position":
+		return s.positionsFor(query), nil
+	case "GET wapi/v1/capital/deposits":
+		return s.depositHistory(query), nil
+	case "GET wapi/v1/capital/withdrawals":
+		// The server does not process withdrawals.
+		return []types.Withdrawal{}, nil
+	case "GET api/v1/order":
+		return s.getOrder(query)
+	case "POST api/v1/order":
+		return s.execute(params)
+	case "DELETE api/v1/order":
+		return s.cancel(params)
+	case "GET api/v1/orders":
+		return s.OpenOrders(query["symbol"]), nil
+	case "DELETE api/v1/orders":
+		return s.cancelAll(params)
+	case "GET wapi/v1/history/orders":
+		return s.orderHistory(query), nil
+	case "GET wapi/v1/history/fills":
+		return s.fillHistory(query), nil
+	}
+	return []any{}, nil
+}
+
+// verify checks the signature of an authenticated request, given the
+// message it should sign for its timestamp and window.
+func (s *Server) verify(r *http.Request, message func(timestamp, window int64) string) *apiError {
+	timestamp, err := strconv.ParseInt(r.Header.Get("X-Timestamp"), 10, 64)
+	if err != nil {
+		return errorf(http.StatusBadRequest, bperrors.ErrCodeInvalidClientRequest, "missing or invalid X-Timestamp")
+	}
+	window, err := strconv.ParseInt(r.Header.Get("X-Window"), 10, 64)
+	if err != nil {
+		window = 5000
+	}
+	return s.verifySignature(r.Header.Get("X-API-Key"), r.Header.Get("X-Signature"), message(timestamp, window), timestamp, window)
+}
+
+func (s *Server) verifySignature(apiKey, signature, message string, timestamp, window int64) *apiError {
+	s.mu.Lock()
+	key, ok := s.keys[apiKey]
+	s.mu.Unlock()
+	if !ok {
+		return errorf(http.StatusUnauthorized, bperrors.ErrCodeUnauthorized, "unknown API key")
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil || !ed25519.Verify(key, []byte(message), sig) {
+		return errorf(http.StatusBadRequest, bperrors.ErrCodeInvalidSignature, "invalid signature")
+	}
+
+	now := time.Now().UnixMilli()
+	if timestamp > now+window || now > timestamp+window {
+		return errorf(http.StatusBadRequest, bperrors.ErrCodeInvalidClientRequest, "request has expired")
+	}
+	return nil
+}
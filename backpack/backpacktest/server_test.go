@@ -0,0 +1,201 @@
+package backpacktest_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/backpacktest"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/enums"
+	bperrors "github.com/solomeowl/backpack-exchange-sdk-go/backpack/errors"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/services"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/types"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/websocket"
+)
+
+func newClient(t *testing.T, server *backpacktest.Server) *backpack.Client {
+	t.Helper()
+	client, err := backpack.NewClient(
+		backpack.WithBaseURL(server.URL()),
+		backpack.WithCredentials(server.Credentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestOrdersService(t *testing.T) {
+	server := backpacktest.NewServer(
+		backpacktest.WithBalance("USDC", "1000"),
+		backpacktest.WithPrice("SOL_USDC", "100"),
+	)
+	defer server.Close()
+	client := newClient(t, server)
+	ctx := context.Background()
+
+	resting, err := client.Orders.LimitOrder(ctx, "SOL_USDC", enums.SideBid, "90", "2")
+	if err != nil {
+		t.Fatalf("LimitOrder: %v", err)
+	}
+	if resting.Status != enums.OrderStatusNew {
+		t.Errorf("resting order status %s", resting.Status)
+	}
+	clientID := uint32(7)
+	results, err := client.Orders.ExecuteBatchOrders(ctx, []types.ExecuteOrderParams{
+		{Symbol: "SOL_USDC", Side: enums.SideBid, OrderType: enums.OrderTypeLimit, Price: "80", Quantity: "1", ClientID: &clientID},
+		{Symbol: "SOL_USDC", Side: enums.SideBid, OrderType: enums.OrderTypeMarket, QuoteQuantity: "100"},
+		{Symbol: "DOGE_USDC", Side: enums.SideBid, OrderType: enums.OrderTypeMarket, Quantity: "1"},
+	})
+	if err != nil {
+		t.Fatalf("ExecuteBatchOrders: %v", err)
+	}
+	if results[0].Order == nil || results[1].Order == nil || results[1].Order.Status != enums.OrderStatusFilled || results[2].Error == "" {
+		t.Fatalf("batch results %+v", results)
+	}
+
+	open, err := client.Orders.GetOpenOrders(ctx, &types.GetOpenOrdersParams{Symbol: "SOL_USDC"})
+	if err != nil || len(open) != 2 {
+		t.Fatalf("GetOpenOrders = %d orders, %v; want 2", len(open), err)
+	}
+	got, err := client.Orders.GetOrder(ctx, types.GetOrderParams{Symbol: "SOL_USDC", ClientID: &clientID})
+	if err != nil || got.ID != results[0].Order.ID {
+		t.Fatalf("GetOrder by client ID = %+v, %v", got, err)
+	}
+	if _, err := client.Orders.CancelOrder(ctx, types.CancelOrderParams{Symbol: "SOL_USDC", ClientID: &clientID}); err != nil {
+		t.Fatalf("CancelOrder: %v", err)
+	}
+	if _, err := client.Orders.CancelOrder(ctx, types.CancelOrderParams{Symbol: "SOL_USDC", ClientID: &clientID}); !errors.Is(err, bperrors.ErrResourceNotFound) {
+		t.Errorf("second CancelOrder: err = %v, want RESOURCE_NOT_FOUND", err)
+	}
+
+	// The price falls to the resting bid, which fills at its limit.
+	server.SetPrice("SOL_USDC", "85")
+	if o, _ := server.Order(resting.ID); o.Status != enums.OrderStatusFilled {
+		t.Errorf("resting order status %s after the price crossed it", o.Status)
+	}
+	balances, err := client.Capital.GetBalances(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 1 SOL bought for 100 USDC, then 2 for 180.
+	if b := balances["SOL"]; !types.MustParseDecimal(b.Available).Equal(types.MustParseDecimal("3")) {
+		t.Errorf("SOL balance %+v, want 3", b)
+	}
+	if b := balances["USDC"]; !types.MustParseDecimal(b.Available).Equal(types.MustParseDecimal("720")) || b.Locked != "0" {
+		t.Errorf("USDC balance %+v, want 720 available", b)
+	}
+}
+
+func TestRejectsBadSignature(t *testing.T) {
+	server := backpacktest.NewServer()
+	defer server.Close()
+	publicKey, _ := server.Credentials()
+	other := backpacktest.NewServer()
+	defer other.Close()
+	_, otherSecret := other.Credentials()
+
+	client, err := backpack.NewClient(backpack.WithBaseURL(server.URL()), backpack.WithCredentials(publicKey, otherSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	if _, err := client.Capital.GetBalances(context.Background()); !errors.Is(err, bperrors.ErrInvalidSignature) {
+		t.Errorf("err = %v, want INVALID_SIGNATURE", err)
+	}
+}
+
+func TestMarketDataAndAccount(t *testing.T) {
+	server := backpacktest.NewServer(backpacktest.WithPrice("SOL_USDC", "100"))
+	defer server.Close()
+	server.Deposit("USDC", "500")
+	client := newClient(t, server)
+	ctx := context.Background()
+
+	if _, err := client.Orders.MarketOrder(ctx, "SOL_USDC", enums.SideBid, "1"); err != nil {
+		t.Fatal(err)
+	}
+	server.SetPrice("SOL_USDC", "110")
+	if _, err := client.Orders.MarketOrder(ctx, "SOL_USDC", enums.SideAsk, "0.5"); err != nil {
+		t.Fatal(err)
+	}
+
+	ticker, err := client.Markets.GetTicker(ctx, services.GetTickerParams{Symbol: "SOL_USDC"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ticker.FirstPrice != "100" || ticker.LastPrice != "110" || ticker.Volume != "1.5" || ticker.Trades != "2" {
+		t.Errorf("ticker %+v", ticker)
+	}
+	tickers, err := client.Markets.GetTickers(ctx, nil)
+	if err != nil || len(tickers) != 2 {
+		t.Errorf("GetTickers = %d tickers, %v", len(tickers), err)
+	}
+	trades, err := client.Trades.GetRecentTrades(ctx, services.GetRecentTradesParams{Symbol: "SOL_USDC"})
+	if err != nil || len(trades) != 2 || trades[1].Price != "110" {
+		t.Errorf("GetRecentTrades = %+v, %v", trades, err)
+	}
+	klines, err := client.Markets.GetKlines(ctx, services.GetKlinesParams{
+		Symbol: "SOL_USDC", Interval: enums.KlineInterval1d, StartTime: time.Now().Add(-time.Hour).Unix(),
+	})
+	if err != nil || len(klines) == 0 || klines[len(klines)-1].Close != "110" {
+		t.Errorf("GetKlines = %+v, %v", klines, err)
+	}
+	if prices, err := client.Markets.GetMarkPrices(ctx, &services.GetMarkPricesParams{MarketType: enums.MarketTypeSpot}); err != nil || len(prices) != 1 {
+		t.Errorf("GetMarkPrices = %+v, %v", prices, err)
+	}
+
+	collateral, err := client.Capital.GetCollateral(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 455 USDC and 0.5 SOL at 110.
+	if collateral.NetEquity != "510.0" || collateral.MarginFraction != "" {
+		t.Errorf("collateral %+v", collateral)
+	}
+	deposits, err := client.Capital.GetDeposits(ctx, nil)
+	if err != nil || len(deposits) != 1 || deposits[0].Quantity != "500" {
+		t.Errorf("GetDeposits = %+v, %v", deposits, err)
+	}
+	if _, err := client.Capital.GetWithdrawals(ctx, nil); err != nil {
+		t.Error(err)
+	}
+	if _, err := client.Account.GetAccount(ctx); err != nil {
+		t.Error(err)
+	}
+	server.SetPositions(types.Position{Symbol: "SOL_USDC_PERP", NetQuantity: "1"})
+	if positions, err := client.Positions.GetPositions(ctx, nil); err != nil || len(positions) != 1 {
+		t.Errorf("GetPositions = %+v, %v", positions, err)
+	}
+}
+
+func TestPrivateStreamSignature(t *testing.T) {
+	server := backpacktest.NewServer()
+	defer server.Close()
+	ws, err := websocket.NewClient(websocket.WithWSURL(server.WSURL()), websocket.WithCredentials(server.Credentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+
+	acks := make(chan websocket.SubscribeAck, 1)
+	ws.OnSubscribeAck(func(ack websocket.SubscribeAck) { acks <- ack })
+	if err := ws.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.SubscribePrivate([]string{"account.orderUpdate"}, func(json.RawMessage) {}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case ack := <-acks:
+		if ack.Err != nil {
+			t.Errorf("private subscription rejected: %v", ack.Err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no subscription acknowledgement")
+	}
+}
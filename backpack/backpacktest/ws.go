@@ -0,0 +1,175 @@
+package backpacktest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/types"
+)
+
+// wsConn is a WebSocket connection to the server.
+type wsConn struct {
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+	streams map[string]bool // Guarded by Server.wsMu
+}
+
+func (c *wsConn) write(v any) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+	return c.conn.WriteJSON(v)
+}
+
+// wsError is the error of a WebSocket request.
+type wsError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (s *Server) serveWS(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	c := &wsConn{conn: conn, streams: make(map[string]bool)}
+
+	s.wsMu.Lock()
+	s.conns[c] = true
+	s.wsMu.Unlock()
+
+	defer func() {
+		s.wsMu.Lock()
+		delete(s.conns, c)
+		s.wsMu.Unlock()
+		conn.Close()
+	}()
+
+	for {
+		var req struct {
+			Method    string   `json:"method"`
+			Params    []string `json:"params"`
+			Signature []string `json:"signature"`
+			ID        *uint64  `json:"id"`
+		}
+		if err := conn.ReadJSON(&req); err != nil {
+			return
+		}
+
+		resp := map[string]any{"result": nil}
+		if req.ID != nil {
+			resp["id"] = *req.ID
+		}
+		switch req.Method {
+		case "SUBSCRIBE":
+			if e := s.authorizeStreams(req.Params, req.Signature); e != nil {
+				resp["error"] = e
+				break
+			}
+			s.wsMu.Lock()
+			for _, stream := range req.Params {
+				c.streams[stream] = true
+			}
+			s.wsMu.Unlock()
+		case "UNSUBSCRIBE":
+			s.wsMu.Lock()
+			for _, stream := range req.Params {
+				delete(c.streams, stream)
+			}
+			s.wsMu.Unlock()
+		default:
+			resp["error"] = wsError{Code: 4000, Message: "unknown method " + strconv.Quote(req.Method)}
+		}
+		if err := c.write(resp); err != nil {
+			return
+		}
+	}
+}
+
+// authorizeStreams checks the signature required to subscribe to private streams.
+func (s *Server) authorizeStreams(streams, signature []string) *wsError {
+	private := false
+	for _, stream := range streams {
+		if strings.HasPrefix(stream, "account.") {
+			private = true
+		}
+	}
+	if !private {
+		return nil
+	}
+	if len(signature) != 4 {
+		return &wsError{Code: 4006, Message: "signature required for private streams"}
+	}
+	timestamp, err1 := strconv.ParseInt(signature[2], 10, 64)
+	window, err2 := strconv.ParseInt(signature[3], 10, 64)
+	if err1 != nil || err2 != nil {
+		return &wsError{Code: 4006, Message: "invalid signature"}
+	}
+	message := signedMessage("subscribe", nil, timestamp, window)
+	if apiErr := s.verifySignature(signature[0], signature[1], message, timestamp, window); apiErr != nil {
+		return &wsError{Code: 4006, Message: apiErr.message}
+	}
+	return nil
+}
+
+// Publish sends data to every connection subscribed to stream.
+func (s *Server) Publish(stream string, data any) {
+	msg := map[string]any{"stream": stream, "data": data}
+
+	s.wsMu.Lock()
+	var conns []*wsConn
+	for c := range s.conns {
+		if c.streams[stream] {
+			conns = append(conns, c)
+		}
+	}
+	s.wsMu.Unlock()
+
+	for _, c := range conns {
+		c.write(msg)
+	}
+}
+
+// publishOrderUpdates sends order updates on the account.orderUpdate
+// streams.
+func (s *Server) publishOrderUpdates(updates []types.WSOrderUpdate) {
+	for _, u := range updates {
+		data, _ := json.Marshal(u)
+		s.Publish("account.orderUpdate", json.RawMessage(data))
+		s.Publish("account.orderUpdate."+u.Symbol, json.RawMessage(data))
+	}
+}
+
+// Subscribers returns the number of connections subscribed to stream.
+func (s *Server) Subscribers(stream string) int {
+	s.wsMu.Lock()
+	defer s.wsMu.Unlock()
+	n := 0
+	for c := range s.conns {
+		if c.streams[stream] {
+			n++
+		}
+	}
+	return n
+}
+
+// DisconnectAll abruptly closes every WebSocket connection, e.g. to test
+// reconnection.
+func (s *Server) DisconnectAll() {
+	s.wsMu.Lock()
+	conns := make([]*wsConn, 0, len(s.conns))
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+	s.wsMu.Unlock()
+
+	for _, c := range conns {
+		c.conn.Close()
+	}
+}
@@ -2,16 +2,32 @@
 package backpack
 
 import (
+	"context"
+	"crypto/ed25519"
 	"net/http"
+	"time"
 
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/fixture"
 	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/internal/auth"
 	internalhttp "github.com/solomeowl/backpack-exchange-sdk-go/backpack/internal/http"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/market"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/paper"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/ratelimit"
 	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/services"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/signing"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/timesync"
 )
 
+// initialClockSyncTimeout bounds the clock measurement made by NewClient.
+const initialClockSyncTimeout = 5 * time.Second
+
 // Client is the main entry point for the Backpack Exchange SDK.
 type Client struct {
 	httpClient *internalhttp.Client
+	limiter    *ratelimit.Limiter
+	clockSync  *timesync.Syncer
+	registry   *market.Registry
+	fixtures   *fixture.Transport
 
 	// Public APIs
 	System            *services.SystemService
@@ -47,14 +63,42 @@ func NewClient(opts ...Option) (*Client, error) {
 		}
 	}
 
-	// Create signer if credentials are provided
+	// Record or replay REST calls if configured
+	var fixtures *fixture.Transport
+	if cfg.fixturePath != "" {
+		var transportOpts []fixture.Option
+		if httpClient.Transport != nil {
+			transportOpts = append(transportOpts, fixture.WithTransport(httpClient.Transport))
+		}
+		var err error
+		fixtures, err = fixture.NewTransport(cfg.fixturePath, cfg.fixtureMode, transportOpts...)
+		if err != nil {
+			return nil, err
+		}
+		fixtureClient := *httpClient
+		fixtureClient.Transport = fixtures
+		httpClient = &fixtureClient
+		cfg.middleware = append(cfg.middleware, recordInstruction)
+	}
+
+	// Create signer if a signer or credentials are provided
 	var signer *auth.Signer
-	if cfg.publicKey != "" && cfg.secretKey != "" {
+	if cfg.signer != nil {
+		signer = auth.New(cfg.signer)
+	} else if cfg.publicKey != "" && cfg.secretKey != "" {
 		var err error
 		signer, err = auth.NewSigner(cfg.publicKey, cfg.secretKey)
 		if err != nil {
 			return nil, err
 		}
+	} else if cfg.fixturePath != "" && cfg.fixtureMode == fixture.ModeReplay {
+		signer = auth.New(signing.NewKeySignerFromPrivateKey(ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))))
+	}
+
+	// Create rate limiter if configured
+	var limiter *ratelimit.Limiter
+	if cfg.rateLimit != nil {
+		limiter = ratelimit.New(*cfg.rateLimit)
 	}
 
 	// Create internal HTTP client
@@ -64,29 +108,79 @@ func NewClient(opts ...Option) (*Client, error) {
 		Signer:     signer,
 		Window:     cfg.window,
 		Debug:      cfg.debug,
+		Retry: internalhttp.RetryPolicy{
+			MaxAttempts:    cfg.retry.MaxAttempts,
+			InitialBackoff: cfg.retry.InitialBackoff,
+			MaxBackoff:     cfg.retry.MaxBackoff,
+			Multiplier:     cfg.retry.Multiplier,
+			Jitter:         cfg.retry.Jitter,
+		},
+		Limiter: limiter,
 	})
 
 	c := &Client{
 		httpClient: internalClient,
+		limiter:    limiter,
+		fixtures:   fixtures,
+	}
+
+	// Route service calls through middleware if configured
+	var rest services.HTTPClient = internalClient
+	if cfg.paper != nil {
+		rest = paper.NewClient(cfg.paper, rest)
+	}
+	if len(cfg.middleware) > 0 {
+		rest = newMiddlewareClient(rest, cfg.middleware)
 	}
 
 	// Initialize public services
-	c.System = services.NewSystemService(internalClient)
-	c.Assets = services.NewAssetsService(internalClient)
-	c.Markets = services.NewMarketsService(internalClient)
-	c.Trades = services.NewTradesService(internalClient)
-	c.BorrowLendMarkets = services.NewBorrowLendMarketsService(internalClient)
-	c.Prediction = services.NewPredictionService(internalClient)
+	c.System = services.NewSystemService(rest)
+	c.Assets = services.NewAssetsService(rest)
+	c.Markets = services.NewMarketsService(rest)
+	c.Trades = services.NewTradesService(rest)
+	c.BorrowLendMarkets = services.NewBorrowLendMarketsService(rest)
+	c.Prediction = services.NewPredictionService(rest)
 
 	// Initialize authenticated services
-	c.Account = services.NewAccountService(internalClient)
-	c.Capital = services.NewCapitalService(internalClient)
-	c.Orders = services.NewOrdersService(internalClient)
-	c.Positions = services.NewPositionsService(internalClient)
-	c.BorrowLend = services.NewBorrowLendService(internalClient)
-	c.RFQ = services.NewRFQService(internalClient)
-	c.Strategy = services.NewStrategyService(internalClient)
-	c.History = services.NewHistoryService(internalClient)
+	c.Account = services.NewAccountService(rest)
+	c.Capital = services.NewCapitalService(rest)
+	c.Orders = services.NewOrdersService(rest)
+	c.Positions = services.NewPositionsService(rest)
+	c.BorrowLend = services.NewBorrowLendService(rest)
+	c.RFQ = services.NewRFQService(rest)
+	c.Strategy = services.NewStrategyService(rest)
+	c.History = services.NewHistoryService(rest)
+
+	// Share market metadata between SDK components
+	registryOpts := []market.RegistryOption{market.WithAssets(c.Assets)}
+	if cfg.marketRefresh > 0 {
+		registryOpts = append(registryOpts, market.WithTTL(cfg.marketRefresh))
+	}
+	c.registry = market.NewRegistry(c.Markets, registryOpts...)
+	if cfg.paper != nil {
+		cfg.paper.SetMarkets(c.registry)
+	}
+	if cfg.marketRefresh > 0 {
+		c.registry.Start(context.Background())
+	}
+
+	// Validate orders against market filters if configured
+	if cfg.validate {
+		c.Orders.SetValidator(market.NewValidator(c.registry, market.WithRounding(cfg.rounding)))
+	}
+
+	// Start clock synchronization if configured
+	if cfg.clockSync > 0 {
+		syncOpts := append([]timesync.Option{timesync.WithInterval(cfg.clockSync)}, cfg.clockSyncOpts...)
+		c.clockSync = timesync.NewSyncer(c.System, syncOpts...)
+		internalClient.SetClock(c.clockSync)
+		// Measure once before returning so the first signed requests use a
+		// corrected clock. A failure is retried in the background.
+		ctx, cancel := context.WithTimeout(context.Background(), initialClockSyncTimeout)
+		_ = c.clockSync.Sync(ctx)
+		cancel()
+		c.clockSync.Start(context.Background())
+	}
 
 	return c, nil
 }
@@ -100,3 +194,40 @@ func (c *Client) SetCredentials(publicKey, secretKey string) error {
 	c.httpClient.SetSigner(signer)
 	return nil
 }
+
+// SetSigner sets or replaces the signer used for authenticated requests.
+func (c *Client) SetSigner(signer signing.Signer) {
+	c.httpClient.SetSigner(auth.New(signer))
+}
+
+// RateLimiter returns the client-side rate limiter, or nil if rate limiting
+// is not enabled. It can be used to inspect current wait times.
+func (c *Client) RateLimiter() *ratelimit.Limiter {
+	return c.limiter
+}
+
+// ClockSync returns the server clock synchronizer, or nil if clock
+// synchronization is not enabled. Pass it to websocket.WithClock to sign
+// WebSocket subscriptions with the same corrected clock.
+func (c *Client) ClockSync() *timesync.Syncer {
+	return c.clockSync
+}
+
+// MarketRegistry returns the cached market metadata shared by the client's
+// components, such as order validation.
+func (c *Client) MarketRegistry() *market.Registry {
+	return c.registry
+}
+
+// Close stops background tasks started by the client and, when recording
+// fixtures, writes the fixture file.
+func (c *Client) Close() error {
+	if c.clockSync != nil {
+		c.clockSync.Stop()
+	}
+	c.registry.Stop()
+	if c.fixtures != nil {
+		return c.fixtures.Close()
+	}
+	return nil
+}
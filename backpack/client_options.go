@@ -3,6 +3,12 @@ package backpack
 import (
 	"net/http"
 	"time"
+
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/fixture"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/paper"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/ratelimit"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/signing"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/timesync"
 )
 
 // Option is a functional option for configuring the Client.
@@ -11,11 +17,24 @@ type Option func(*options)
 type options struct {
 	publicKey  string
 	secretKey  string
+	signer     signing.Signer
 	baseURL    string
 	timeout    time.Duration
 	window     int64
 	debug      bool
 	httpClient *http.Client
+	retry      RetryPolicy
+	rateLimit  *ratelimit.Config
+	middleware []Middleware
+	clockSync  time.Duration
+	validate   bool
+	rounding   bool
+
+	clockSyncOpts []timesync.Option
+	marketRefresh time.Duration
+	fixturePath   string
+	fixtureMode   fixture.Mode
+	paper         *paper.Engine
 }
 
 func defaultOptions() *options {
@@ -34,6 +53,15 @@ func WithCredentials(publicKey, secretKey string) Option {
 	}
 }
 
+// WithSigner sets a custom signer for authenticated requests, e.g. one that
+// keeps the private key in a separate process. It takes precedence over
+// WithCredentials.
+func WithSigner(signer signing.Signer) Option {
+	return func(o *options) {
+		o.signer = signer
+	}
+}
+
 // WithBaseURL sets a custom base URL for the API.
 func WithBaseURL(url string) Option {
 	return func(o *options) {
@@ -68,3 +96,118 @@ func WithHTTPClient(client *http.Client) Option {
 		o.httpClient = client
 	}
 }
+
+// RetryPolicy configures automatic retries of failed REST calls.
+//
+// Requests are retried on TOO_MANY_REQUESTS, SERVER_ERROR, TIMEOUT and
+// MAINTENANCE responses, other 429/5xx statuses and network errors. Only
+// requests that are safe to replay are retried: GET, HEAD and DELETE calls.
+// Order placement is never retried, since the exchange does not deduplicate
+// orders by clientId; use clientid.Submitter to resubmit orders safely.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// Values of 1 or less disable retries.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between attempts. A Retry-After header sent by
+	// the server takes precedence when it asks for a longer wait.
+	MaxBackoff time.Duration
+	// Multiplier grows the delay after each retry.
+	Multiplier float64
+	// Jitter randomly shortens each delay by up to this fraction (0 to 1).
+	Jitter float64
+}
+
+// DefaultRetryPolicy returns a retry policy suitable for most applications.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: 250 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.5,
+	}
+}
+
+// WithRetryPolicy enables automatic retries with backoff for failed requests.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(o *options) {
+		o.retry = policy
+	}
+}
+
+// WithRateLimit throttles requests on the client side using token buckets
+// per endpoint group, shared by all services of the client.
+// See ratelimit.DefaultConfig for a sensible starting point.
+func WithRateLimit(cfg ratelimit.Config) Option {
+	return func(o *options) {
+		o.rateLimit = &cfg
+	}
+}
+
+// WithMiddleware adds middleware around every REST call made by the client.
+// Middleware run in the order given, the first one being the outermost.
+// The option may be given several times; middleware accumulate.
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(o *options) {
+		o.middleware = append(o.middleware, middleware...)
+	}
+}
+
+// WithClockSync periodically measures the offset between the local clock and
+// the server clock, and uses it to timestamp signed requests. The first
+// measurement is made by NewClient, which waits for it at most 5 seconds; a
+// failed measurement is retried in the background. The measured drift is
+// available through Client.ClockSync; pass timesync.WithOnSync to be
+// notified of every measurement, e.g. to alert on drift.
+func WithClockSync(interval time.Duration, opts ...timesync.Option) Option {
+	return func(o *options) {
+		o.clockSync = interval
+		o.clockSyncOpts = append(o.clockSyncOpts, opts...)
+	}
+}
+
+// WithOrderValidation checks orders against the market's tick size, step
+// size, limits and order book state before they are submitted, returning a
+// *market.ValidationError instead of sending orders the exchange would
+// reject. If rounding is true, prices and quantities are first rounded to
+// the tick and step sizes.
+func WithOrderValidation(rounding bool) Option {
+	return func(o *options) {
+		o.validate = true
+		o.rounding = rounding
+	}
+}
+
+// WithMarketRefresh refreshes the client's market registry in the background
+// at the given interval instead of reloading it lazily on lookup.
+func WithMarketRefresh(interval time.Duration) Option {
+	return func(o *options) {
+		o.marketRefresh = interval
+	}
+}
+
+// WithFixtures records REST calls to, or replays them from, the fixture file
+// at path. In fixture.ModeRecord every request and response is recorded,
+// with authentication headers redacted, and written to the file by
+// Client.Close; in fixture.ModeReplay responses are served from the file
+// without network access, matching on method, path, query parameters and
+// body. Replay does not need credentials: authenticated calls are signed
+// with a throwaway key if none are set.
+func WithFixtures(path string, mode fixture.Mode) Option {
+	return func(o *options) {
+		o.fixturePath = path
+		o.fixtureMode = mode
+	}
+}
+
+// WithPaperTrading serves orders, balances, positions and order and fill
+// history from a simulated account instead of the exchange. Market data and
+// other queries still go to the API. Feed the engine with market data, e.g.
+// with paper.Engine.Track, for orders to fill.
+func WithPaperTrading(engine *paper.Engine) Option {
+	return func(o *options) {
+		o.paper = engine
+	}
+}
@@ -0,0 +1,125 @@
+// Package clientid allocates order client IDs and submits orders
+// idempotently with them.
+package clientid
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/internal/atomicfile"
+)
+
+// DefaultBlockSize is the default number of IDs reserved per write of the
+// allocator's state file.
+const DefaultBlockSize = 1000
+
+// Allocator hands out unique order client IDs. IDs increase by one and wrap
+// around at the uint32 limit, skipping zero. It is safe for concurrent use
+// but must not be shared between processes.
+type Allocator struct {
+	mu        sync.Mutex
+	path      string
+	blockSize uint32
+	next      uint32
+	reserved  uint32 // IDs up to, excluding, reserved may be handed out
+}
+
+// state is the content of an allocator's state file.
+type state struct {
+	Reserved uint32 `json:"reserved"`
+}
+
+// AllocatorOption is a functional option for configuring an Allocator.
+type AllocatorOption func(*Allocator)
+
+// WithBlockSize sets how many IDs are reserved per write of the state file.
+// Larger blocks mean fewer writes, and more IDs skipped after a restart.
+func WithBlockSize(n uint32) AllocatorOption {
+	return func(a *Allocator) {
+		if n > 0 {
+			a.blockSize = n
+		}
+	}
+}
+
+// New creates an Allocator without persistence. It starts from the current
+// time in milliseconds, so a restarted process does not reuse recent IDs as
+// long as it placed fewer orders than milliseconds elapsed, and IDs are only
+// reused after about 49 days.
+func New() *Allocator {
+	start := uint32(time.Now().UnixMilli())
+	if start == 0 {
+		start = 1
+	}
+	return &Allocator{next: start}
+}
+
+// Open creates an Allocator persisting its state in the file at path, so IDs
+// are never reused across restarts. IDs are reserved in blocks: the file
+// records the end of the current block before any ID of it is handed out,
+// and a restarted allocator continues after it.
+func Open(path string, opts ...AllocatorOption) (*Allocator, error) {
+	a := &Allocator{path: path, blockSize: DefaultBlockSize, next: 1}
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf("clientid: failed to read %s: %w", path, err)
+	default:
+		var s state
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, fmt.Errorf("clientid: failed to decode %s: %w", path, err)
+		}
+		a.next = s.Reserved
+		if a.next == 0 {
+			a.next = 1
+		}
+	}
+	a.reserved = a.next
+	return a, nil
+}
+
+// Next returns a new client ID.
+func (a *Allocator) Next() (uint32, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.path != "" && a.next == a.reserved {
+		if err := a.reserveLocked(); err != nil {
+			return 0, err
+		}
+	}
+	id := a.next
+	a.next++
+	if a.next == 0 {
+		a.next, a.reserved = 1, 1
+	}
+	return id, nil
+}
+
+// reserveLocked records the end of the next block of IDs.
+func (a *Allocator) reserveLocked() error {
+	end := a.next + a.blockSize
+	if end < a.next {
+		// The block wraps around; reserve up to the limit and restart
+		// from 1 once it is used.
+		end = 0
+	}
+	data, err := json.Marshal(state{Reserved: end})
+	if err != nil {
+		return fmt.Errorf("clientid: failed to encode state: %w", err)
+	}
+	if err := atomicfile.WriteFile(a.path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("clientid: failed to write %s: %w", a.path, err)
+	}
+	a.reserved = end
+	return nil
+}
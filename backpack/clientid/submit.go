@@ -0,0 +1,259 @@
+package clientid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/enums"
+	bperrors "github.com/solomeowl/backpack-exchange-sdk-go/backpack/errors"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/types"
+)
+
+const (
+	DefaultMaxAttempts    = 3
+	DefaultAttemptTimeout = 10 * time.Second
+	DefaultSettleDelay    = time.Second
+)
+
+const (
+	// historyPage is the number of order history rows requested at a time.
+	historyPage = 1000
+	// clockSkew is how much older than the submission an order may appear
+	// in the order history, allowing for the difference between the local
+	// and the exchange clocks.
+	clockSkew = time.Minute
+)
+
+// ErrUnknownOutcome is returned when an order could not be confirmed as
+// placed or not placed within the allowed attempts.
+var ErrUnknownOutcome = errors.New("clientid: order outcome unknown")
+
+// OrderClient places and looks up orders. *services.OrdersService
+// implements OrderClient.
+type OrderClient interface {
+	ExecuteOrder(ctx context.Context, params types.ExecuteOrderParams) (*types.Order, error)
+	GetOrder(ctx context.Context, params types.GetOrderParams) (*types.Order, error)
+}
+
+// HistorySource lists past orders. *services.HistoryService implements
+// HistorySource.
+type HistorySource interface {
+	GetOrderHistory(ctx context.Context, params *types.OrderHistoryParams) ([]types.OrderHistoryItem, error)
+}
+
+// Submitter places orders at most once. Every order gets a client ID; when
+// a submission fails ambiguously, such as with a timeout, a network error
+// or a server error, the order is looked up by client ID among open orders
+// and then in the order history before it is submitted again.
+//
+// The lookup happens after a settle delay to give a slow request time to
+// reach the matching engine. A request delayed beyond it can still result
+// in a duplicate, so the delay should exceed the request timeout of the
+// exchange.
+type Submitter struct {
+	orders         OrderClient
+	history        HistorySource
+	ids            *Allocator
+	maxAttempts    int
+	attemptTimeout time.Duration
+	settleDelay    time.Duration
+}
+
+// Option is a functional option for configuring a Submitter.
+type Option func(*Submitter)
+
+// WithHistory sets the source used to find orders that are no longer open,
+// such as market orders that filled immediately. Without it, only open
+// orders are found, and an order that closed before the lookup is
+// submitted again.
+func WithHistory(history HistorySource) Option {
+	return func(s *Submitter) {
+		s.history = history
+	}
+}
+
+// WithMaxAttempts sets how many times an order is submitted at most.
+func WithMaxAttempts(n int) Option {
+	return func(s *Submitter) {
+		if n > 0 {
+			s.maxAttempts = n
+		}
+	}
+}
+
+// WithAttemptTimeout sets the timeout of each submission.
+func WithAttemptTimeout(timeout time.Duration) Option {
+	return func(s *Submitter) {
+		s.attemptTimeout = timeout
+	}
+}
+
+// WithSettleDelay sets how long to wait after an ambiguous failure before
+// looking the order up, and after a rejection before submitting again.
+func WithSettleDelay(delay time.Duration) Option {
+	return func(s *Submitter) {
+		s.settleDelay = delay
+	}
+}
+
+// NewSubmitter creates a new Submitter placing orders with orders and
+// client IDs from ids.
+func NewSubmitter(orders OrderClient, ids *Allocator, opts ...Option) *Submitter {
+	s := &Submitter{
+		orders:         orders,
+		ids:            ids,
+		maxAttempts:    DefaultMaxAttempts,
+		attemptTimeout: DefaultAttemptTimeout,
+		settleDelay:    DefaultSettleDelay,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Submit places an order. A client ID is allocated unless params has one.
+// Submissions rejected without reaching the matching engine, such as for
+// rate limiting, are retried; other API errors are returned as is. If the
+// order could not be placed nor found after the last attempt, the error
+// wraps ErrUnknownOutcome and the last failure. So does the error of ctx
+// if it is done after a submission may have reached the matching engine.
+func (s *Submitter) Submit(ctx context.Context, params types.ExecuteOrderParams) (*types.Order, error) {
+	submitted := time.Now()
+	if params.ClientID == nil {
+		id, err := s.ids.Next()
+		if err != nil {
+			return nil, err
+		}
+		params.ClientID = &id
+	}
+
+	var lastErr error
+	ambiguous := false
+	for attempt := 0; attempt < s.maxAttempts; attempt++ {
+		if attempt > 0 {
+			if err := sleep(ctx, s.settleDelay); err != nil {
+				return nil, outcome(err, ambiguous)
+			}
+		}
+		if ambiguous {
+			o, err := s.Lookup(ctx, params.Symbol, *params.ClientID, submitted)
+			if err != nil {
+				// Without knowing whether the order exists, it must not
+				// be submitted again.
+				return nil, fmt.Errorf("%w: %w", ErrUnknownOutcome, err)
+			}
+			if o != nil {
+				return o, nil
+			}
+		}
+
+		o, err := s.execute(ctx, params)
+		if err == nil {
+			return o, nil
+		}
+		if ctx.Err() != nil {
+			// The request may have been sent before ctx was done.
+			return nil, outcome(ctx.Err(), true)
+		}
+		lastErr = err
+		switch {
+		case bperrors.IsAmbiguous(err):
+			ambiguous = true
+		case bperrors.IsRetryable(err):
+			// Rejected before reaching the matching engine.
+			ambiguous = false
+		default:
+			return nil, err
+		}
+	}
+
+	if !ambiguous {
+		return nil, lastErr
+	}
+	if err := sleep(ctx, s.settleDelay); err != nil {
+		return nil, outcome(err, true)
+	}
+	o, err := s.Lookup(ctx, params.Symbol, *params.ClientID, submitted)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnknownOutcome, err)
+	}
+	if o != nil {
+		return o, nil
+	}
+	return nil, fmt.Errorf("%w: %w", ErrUnknownOutcome, lastErr)
+}
+
+func (s *Submitter) execute(ctx context.Context, params types.ExecuteOrderParams) (*types.Order, error) {
+	if s.attemptTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.attemptTimeout)
+		defer cancel()
+	}
+	return s.orders.ExecuteOrder(ctx, params)
+}
+
+// outcome returns err, wrapped with ErrUnknownOutcome if an earlier
+// submission may have placed the order.
+func outcome(err error, ambiguous bool) error {
+	if !ambiguous {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrUnknownOutcome, err)
+}
+
+// Lookup finds an order by client ID among open orders, then, with
+// WithHistory, in the order history, newest first, back to orders created
+// before submitted. A zero submitted searches the whole history. It returns
+// nil if the order does not exist.
+func (s *Submitter) Lookup(ctx context.Context, symbol string, clientID uint32, submitted time.Time) (*types.Order, error) {
+	o, err := s.orders.GetOrder(ctx, types.GetOrderParams{Symbol: symbol, ClientID: &clientID})
+	if err == nil {
+		return o, nil
+	}
+	if apiErr, ok := bperrors.IsAPIError(err); !ok || (apiErr.StatusCode != http.StatusNotFound && !apiErr.HasCode(bperrors.ErrCodeResourceNotFound)) {
+		return nil, fmt.Errorf("clientid: failed to look up order: %w", err)
+	}
+	if s.history == nil {
+		return nil, nil
+	}
+
+	since := submitted.Add(-clockSkew)
+	params := &types.OrderHistoryParams{Symbol: symbol, Limit: historyPage, SortDirection: enums.SortDirectionDesc}
+	for {
+		history, err := s.history.GetOrderHistory(ctx, params)
+		if err != nil {
+			return nil, fmt.Errorf("clientid: failed to look up order history: %w", err)
+		}
+		older := false
+		for i := range history {
+			if history[i].ClientID == clientID {
+				return &history[i], nil
+			}
+			if created, err := history[i].CreatedAt.Time(); err == nil && !submitted.IsZero() && created.Before(since) {
+				older = true
+			}
+		}
+		if older || len(history) < historyPage {
+			return nil, nil
+		}
+		params.Offset += historyPage
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
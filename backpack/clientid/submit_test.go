@@ -0,0 +1,105 @@
+package clientid
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	bperrors "github.com/solomeowl/backpack-exchange-sdk-go/backpack/errors"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/types"
+)
+
+// lostOrders times out every submission, although the order is placed, and
+// finds orders only in the history.
+type lostOrders struct {
+	history  []types.Order
+	executed int
+	pages    int
+}
+
+func (l *lostOrders) ExecuteOrder(ctx context.Context, params types.ExecuteOrderParams) (*types.Order, error) {
+	l.executed++
+	l.history = append([]types.Order{{
+		ID:        "placed",
+		ClientID:  *params.ClientID,
+		CreatedAt: types.TimeString(strconv.FormatInt(time.Now().UnixMilli(), 10)),
+	}}, l.history...)
+	return nil, context.DeadlineExceeded
+}
+
+func (l *lostOrders) GetOrder(ctx context.Context, params types.GetOrderParams) (*types.Order, error) {
+	return nil, &bperrors.APIError{StatusCode: http.StatusNotFound, Code: string(bperrors.ErrCodeResourceNotFound)}
+}
+
+func (l *lostOrders) GetOrderHistory(ctx context.Context, params *types.OrderHistoryParams) ([]types.OrderHistoryItem, error) {
+	l.pages++
+	if params.Offset >= len(l.history) {
+		return nil, nil
+	}
+	return l.history[params.Offset:min(params.Offset+params.Limit, len(l.history))], nil
+}
+
+// recent returns n orders created just now, newest first.
+func recent(n int) []types.Order {
+	now := types.TimeString(strconv.FormatInt(time.Now().UnixMilli(), 10))
+	orders := make([]types.Order, n)
+	for i := range orders {
+		orders[i] = types.Order{ID: strconv.Itoa(i), ClientID: uint32(i + 1), CreatedAt: now}
+	}
+	return orders
+}
+
+func TestSubmitFindsOrderBeyondFirstHistoryPage(t *testing.T) {
+	orders := &lostOrders{}
+	s := NewSubmitter(orders, New(), WithHistory(orders), WithSettleDelay(0))
+	id := uint32(1 << 31)
+	orders.ExecuteOrder(context.Background(), types.ExecuteOrderParams{ClientID: &id})
+	// Orders placed since push the lost order past the first page.
+	orders.history = append(recent(historyPage+10), orders.history...)
+
+	o, err := s.Lookup(context.Background(), "SOL_USDC", id, time.Now())
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if o == nil || o.ID != "placed" {
+		t.Fatalf("Lookup = %v, want the placed order", o)
+	}
+	if orders.pages != 2 {
+		t.Errorf("fetched %d history pages, want 2", orders.pages)
+	}
+}
+
+func TestLookupStopsAtOlderOrders(t *testing.T) {
+	old := types.TimeString(strconv.FormatInt(time.Now().Add(-time.Hour).UnixMilli(), 10))
+	orders := &lostOrders{}
+	for i := 0; i < 3*historyPage; i++ {
+		orders.history = append(orders.history, types.Order{ID: strconv.Itoa(i), CreatedAt: old})
+	}
+	s := NewSubmitter(orders, New(), WithHistory(orders))
+
+	o, err := s.Lookup(context.Background(), "SOL_USDC", 42, time.Now())
+	if err != nil || o != nil {
+		t.Fatalf("Lookup = %v, %v, want nil, nil", o, err)
+	}
+	if orders.pages != 1 {
+		t.Errorf("fetched %d history pages, want 1", orders.pages)
+	}
+}
+
+func TestSubmitReportsUnknownOutcomeWhenCancelled(t *testing.T) {
+	orders := &lostOrders{}
+	s := NewSubmitter(orders, New(), WithHistory(orders), WithSettleDelay(time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := s.Submit(ctx, types.ExecuteOrderParams{Symbol: "SOL_USDC"})
+	if !errors.Is(err, ErrUnknownOutcome) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Submit error = %v, want ErrUnknownOutcome wrapping context.DeadlineExceeded", err)
+	}
+	if orders.executed != 1 {
+		t.Errorf("submitted %d times, want 1", orders.executed)
+	}
+}
@@ -33,6 +33,34 @@ const (
 	OrderStatusTriggerFailed   OrderStatus = "TriggerFailed"
 )
 
+// Terminal reports whether an order with the status has reached a final
+// state.
+func (s OrderStatus) Terminal() bool {
+	switch s {
+	case OrderStatusFilled, OrderStatusCancelled, OrderStatusExpired, OrderStatusTriggerFailed:
+		return true
+	}
+	return false
+}
+
+// Rank orders statuses along the lifecycle of an order. An order never
+// moves to a lower rank: TriggerPending → New → PartiallyFilled → a
+// terminal status. Unknown statuses, including the empty one, rank -1.
+func (s OrderStatus) Rank() int {
+	switch s {
+	case OrderStatusTriggerPending:
+		return 0
+	case OrderStatusNew:
+		return 1
+	case OrderStatusPartiallyFilled:
+		return 2
+	}
+	if s.Terminal() {
+		return 3
+	}
+	return -1
+}
+
 // SelfTradePrevention represents self-trade prevention mode.
 type SelfTradePrevention string
 
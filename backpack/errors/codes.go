@@ -42,3 +42,51 @@ const (
 	ErrCodeTradingPaused             ApiErrorCode = "TRADING_PAUSED"
 	ErrCodeUnauthorized              ApiErrorCode = "UNAUTHORIZED"
 )
+
+// Sentinel errors for each API error code, for use with errors.Is:
+//
+//	if errors.Is(err, errors.ErrInsufficientFunds) { ... }
+var (
+	ErrAccountDeactivated       error = codeError(ErrCodeAccountDeactivated)
+	ErrAccountLiquidating       error = codeError(ErrCodeAccountLiquidating)
+	ErrBorrowLimit              error = codeError(ErrCodeBorrowLimit)
+	ErrBorrowRequiresLendRedeem error = codeError(ErrCodeBorrowRequiresLendRedeem)
+	ErrForbidden                error = codeError(ErrCodeForbidden)
+	ErrInsufficientFunds        error = codeError(ErrCodeInsufficientFunds)
+	ErrInsufficientMargin       error = codeError(ErrCodeInsufficientMargin)
+	ErrInsufficientSupply       error = codeError(ErrCodeInsufficientSupply)
+	ErrInvalidAsset             error = codeError(ErrCodeInvalidAsset)
+	ErrInvalidClientRequest     error = codeError(ErrCodeInvalidClientRequest)
+	ErrInvalidMarket            error = codeError(ErrCodeInvalidMarket)
+	ErrInvalidOrder             error = codeError(ErrCodeInvalidOrder)
+	ErrInvalidPositionID        error = codeError(ErrCodeInvalidPositionID)
+	ErrInvalidQuantity          error = codeError(ErrCodeInvalidQuantity)
+	ErrInvalidRange             error = codeError(ErrCodeInvalidRange)
+	ErrInvalidSignature         error = codeError(ErrCodeInvalidSignature)
+	ErrInvalidSource            error = codeError(ErrCodeInvalidSource)
+	ErrInvalidSymbol            error = codeError(ErrCodeInvalidSymbol)
+	ErrInvalidTwoFactorCode     error = codeError(ErrCodeInvalidTwoFactorCode)
+	ErrLendLimit                error = codeError(ErrCodeLendLimit)
+	ErrLendRequiresBorrowRepay  error = codeError(ErrCodeLendRequiresBorrowRepay)
+	ErrMaintenance              error = codeError(ErrCodeMaintenance)
+	ErrMaxLeverageReached       error = codeError(ErrCodeMaxLeverageReached)
+	ErrNotImplemented           error = codeError(ErrCodeNotImplemented)
+	ErrOrderLimit               error = codeError(ErrCodeOrderLimit)
+	ErrPositionLimit            error = codeError(ErrCodePositionLimit)
+	ErrPreconditionFailed       error = codeError(ErrCodePreconditionFailed)
+	ErrResourceNotFound         error = codeError(ErrCodeResourceNotFound)
+	ErrServerError              error = codeError(ErrCodeServerError)
+	ErrTimeout                  error = codeError(ErrCodeTimeout)
+	ErrTooManyRequests          error = codeError(ErrCodeTooManyRequests)
+	ErrTradingPaused            error = codeError(ErrCodeTradingPaused)
+	ErrUnauthorized             error = codeError(ErrCodeUnauthorized)
+)
+
+// codeError is the type of the sentinel errors. An *APIError matches the
+// sentinel of its code in errors.Is.
+type codeError ApiErrorCode
+
+// Error implements the error interface.
+func (c codeError) Error() string {
+	return "backpack: " + string(c)
+}
@@ -2,9 +2,12 @@
 package errors
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"time"
 )
 
 // Common errors
@@ -19,6 +22,9 @@ type APIError struct {
 	Code       string `json:"code,omitempty"`
 	Message    string `json:"message,omitempty"`
 	RawBody    string `json:"-"`
+
+	// RetryAfter is the delay requested by the server's Retry-After header, if any.
+	RetryAfter time.Duration `json:"-"`
 }
 
 func (e *APIError) Error() string {
@@ -36,6 +42,13 @@ func (e *APIError) HasCode(code ErrorCode) bool {
 	return e.Code == string(code)
 }
 
+// Is reports whether the error carries the given error code, so that
+// errors.Is(err, ErrInsufficientFunds) matches API errors by code.
+func (e *APIError) Is(target error) bool {
+	code, ok := target.(codeError)
+	return ok && e.Code == string(code)
+}
+
 // RequestError represents an error that occurred while making a request.
 type RequestError struct {
 	Method  string
@@ -95,3 +108,81 @@ func IsRequestError(err error) (*RequestError, bool) {
 	}
 	return nil, false
 }
+
+// Is reports whether any error in err's tree matches target. It mirrors the
+// standard library's errors.Is so callers importing this package as "errors"
+// can match sentinel errors directly.
+func Is(err, target error) bool {
+	return errors.Is(err, target)
+}
+
+// As finds the first error in err's tree that matches target. It mirrors
+// the standard library's errors.As.
+func As(err error, target any) bool {
+	return errors.As(err, target)
+}
+
+// IsRetryable reports whether a request that failed with err may succeed if
+// repeated: rate limiting, server errors, timeouts, maintenance and network
+// errors. Errors caused by context cancellation are not retryable.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if apiErr, ok := IsAPIError(err); ok {
+		switch ApiErrorCode(apiErr.Code) {
+		case ErrCodeTooManyRequests, ErrCodeServerError, ErrCodeTimeout, ErrCodeMaintenance:
+			return true
+		}
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+			http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	_, ok := IsRequestError(err)
+	return ok
+}
+
+// IsAmbiguous reports whether a request that failed with err may still
+// have been processed by the exchange: server errors other than
+// unavailability, timeouts, network errors and requests abandoned when
+// their context was done. Repeating such a request, such as placing an
+// order, may apply it twice.
+func IsAmbiguous(err error) bool {
+	if err == nil {
+		return false
+	}
+	if apiErr, ok := IsAPIError(err); ok {
+		return apiErr.StatusCode >= http.StatusInternalServerError && apiErr.StatusCode != http.StatusServiceUnavailable ||
+			apiErr.HasCode(ErrCodeServerError) || apiErr.HasCode(ErrCodeTimeout)
+	}
+	_, ok := IsRequestError(err)
+	return ok || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled)
+}
+
+// IsRateLimited reports whether err was caused by exceeding a rate limit.
+func IsRateLimited(err error) bool {
+	if errors.Is(err, ErrTooManyRequests) {
+		return true
+	}
+	apiErr, ok := IsAPIError(err)
+	return ok && apiErr.StatusCode == http.StatusTooManyRequests
+}
+
+// IsAuthFailure reports whether err was caused by missing, invalid or
+// insufficient credentials, or by a rejected signature.
+func IsAuthFailure(err error) bool {
+	if errors.Is(err, ErrNoCredentials) || errors.Is(err, ErrInvalidKey) ||
+		errors.Is(err, ErrInvalidSignature) || errors.Is(err, ErrUnauthorized) || errors.Is(err, ErrForbidden) {
+		return true
+	}
+	apiErr, ok := IsAPIError(err)
+	return ok && apiErr.StatusCode == http.StatusUnauthorized
+}
+
+// IsMaintenance reports whether err was caused by exchange maintenance.
+func IsMaintenance(err error) bool {
+	return errors.Is(err, ErrMaintenance)
+}
@@ -0,0 +1,43 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestAPIErrorIs(t *testing.T) {
+	err := fmt.Errorf("place order: %w", ParseAPIError(400, []byte(`{"code":"INSUFFICIENT_FUNDS","message":"insufficient funds"}`)))
+
+	if !errors.Is(err, ErrInsufficientFunds) {
+		t.Error("errors.Is(err, ErrInsufficientFunds) = false, want true")
+	}
+	if errors.Is(err, ErrInvalidOrder) {
+		t.Error("errors.Is(err, ErrInvalidOrder) = true, want false")
+	}
+	if got := fmt.Sprint(ErrCodeInsufficientFunds); got != "INSUFFICIENT_FUNDS" {
+		t.Errorf("ErrCodeInsufficientFunds prints as %q, want %q", got, "INSUFFICIENT_FUNDS")
+	}
+}
+
+func TestIsAmbiguous(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{ParseAPIError(500, []byte(`{"code":"INTERNAL_ERROR","message":"oops"}`)), true},
+		{ParseAPIError(504, nil), true},
+		{ParseAPIError(503, nil), false},
+		{ParseAPIError(400, []byte(`{"code":"INVALID_ORDER","message":"bad"}`)), false},
+		{&RequestError{Err: errors.New("connection reset")}, true},
+		{fmt.Errorf("attempt: %w", context.DeadlineExceeded), true},
+		{errors.New("invalid params"), false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := IsAmbiguous(tt.err); got != tt.want {
+			t.Errorf("IsAmbiguous(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,374 @@
+// Package fixture records REST calls to Backpack Exchange and replays them
+// without network access, for regression tests against real payloads.
+package fixture
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"sync"
+
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/internal/atomicfile"
+)
+
+// ErrNoFixture is returned in replay mode when no recorded interaction
+// matches a request.
+var ErrNoFixture = errors.New("fixture: no recorded interaction matches request")
+
+// Redacted replaces the value of authentication headers in recordings.
+const Redacted = "REDACTED"
+
+// redactedHeaders are the request headers whose values are not recorded.
+var redactedHeaders = []string{"X-API-Key", "X-Signature"}
+
+// Mode selects whether a Transport records or replays.
+type Mode int
+
+const (
+	// ModeReplay serves responses from the fixture file without network access.
+	ModeReplay Mode = iota
+	// ModeRecord sends requests to the server and records every interaction.
+	// The fixture file is replaced when the Transport is closed.
+	ModeRecord
+)
+
+// String returns the name of the mode.
+func (m Mode) String() string {
+	switch m {
+	case ModeReplay:
+		return "replay"
+	case ModeRecord:
+		return "record"
+	default:
+		return "unknown"
+	}
+}
+
+// Request is a recorded REST request.
+type Request struct {
+	Method      string            `json:"method"`
+	Path        string            `json:"path"`
+	Instruction string            `json:"instruction,omitempty"`
+	Params      map[string]string `json:"params,omitempty"`
+	Body        json.RawMessage   `json:"body,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+}
+
+// Response is a recorded REST response. JSON bodies are kept in Body and
+// other bodies, such as plain text errors, in Text.
+type Response struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+	Text    string            `json:"text,omitempty"`
+}
+
+// Interaction is a recorded request and its response.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// matches reports whether a recorded request matches req on method, path,
+// query parameters and body.
+func (r Request) matches(req Request) bool {
+	if r.Method != req.Method || r.Path != req.Path {
+		return false
+	}
+	if len(r.Params) != 0 || len(req.Params) != 0 {
+		if !reflect.DeepEqual(r.Params, req.Params) {
+			return false
+		}
+	}
+	return bytes.Equal(r.Body, req.Body)
+}
+
+// Load reads the interactions of a fixture file.
+func Load(path string) ([]Interaction, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("fixture: failed to read %s: %w", path, err)
+	}
+	var interactions []Interaction
+	if err := json.Unmarshal(data, &interactions); err != nil {
+		return nil, fmt.Errorf("fixture: invalid fixture file %s: %w", path, err)
+	}
+	// Save indents the bodies along with the file; match them compacted.
+	for i := range interactions {
+		interactions[i].Request.Body = compact(interactions[i].Request.Body)
+		interactions[i].Response.Body = compact(interactions[i].Response.Body)
+	}
+	return interactions, nil
+}
+
+// Save writes interactions to a fixture file, creating its directory if needed.
+func Save(path string, interactions []Interaction) error {
+	data, err := json.MarshalIndent(interactions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("fixture: failed to encode interactions: %w", err)
+	}
+	if err := atomicfile.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("fixture: failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+type instructionKey struct{}
+
+// WithInstruction returns a context carrying the signing instruction of the
+// request made with it, so it can be recorded. backpack.Client sets it when
+// fixtures are enabled.
+func WithInstruction(ctx context.Context, instruction string) context.Context {
+	return context.WithValue(ctx, instructionKey{}, instruction)
+}
+
+func instructionFrom(ctx context.Context) string {
+	instruction, _ := ctx.Value(instructionKey{}).(string)
+	return instruction
+}
+
+// Transport is an http.RoundTripper that records or replays REST calls. It
+// is safe for concurrent use.
+type Transport struct {
+	path string
+	mode Mode
+	next http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// Option is a functional option for configuring a Transport.
+type Option func(*Transport)
+
+// WithTransport sets the transport used to reach the server in record
+// mode. It defaults to http.DefaultTransport.
+func WithTransport(next http.RoundTripper) Option {
+	return func(t *Transport) {
+		t.next = next
+	}
+}
+
+// NewTransport creates a Transport for the fixture file at path. In replay
+// mode the file is loaded immediately; in record mode it is written by
+// Close.
+func NewTransport(path string, mode Mode, opts ...Option) (*Transport, error) {
+	t := &Transport{
+		path: path,
+		mode: mode,
+		next: http.DefaultTransport,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	if mode == ModeReplay {
+		interactions, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		t.interactions = interactions
+		t.used = make([]bool, len(interactions))
+	}
+	return t, nil
+}
+
+// Mode returns the mode of the transport.
+func (t *Transport) Mode() Mode {
+	return t.mode
+}
+
+// Interactions returns the interactions recorded or loaded so far.
+func (t *Transport) Interactions() []Interaction {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Interaction(nil), t.interactions...)
+}
+
+// Close writes the recorded interactions to the fixture file in record
+// mode. It does nothing in replay mode.
+func (t *Transport) Close() error {
+	if t.mode != ModeRecord {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return Save(t.path, t.interactions)
+}
+
+// RoundTrip implements http.RoundTripper. In replay mode a request without
+// a recorded interaction fails with an error wrapping ErrNoFixture.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Reading the body must not consume the caller's request.
+	req = req.Clone(req.Context())
+	recorded, err := newRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if t.mode == ModeReplay {
+		return t.replay(req, recorded)
+	}
+	return t.record(req, recorded)
+}
+
+// replay serves the first unused interaction matching the request, so
+// repeated calls get their responses in recorded order. Once all matching
+// interactions are used, the last one is served again.
+func (t *Transport) replay(req *http.Request, recorded Request) (*http.Response, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	match := -1
+	for i, in := range t.interactions {
+		if !in.Request.matches(recorded) {
+			continue
+		}
+		match = i
+		if !t.used[i] {
+			break
+		}
+	}
+	if match < 0 {
+		return nil, fmt.Errorf("%w: %s %s", ErrNoFixture, recorded.Method, req.URL.RequestURI())
+	}
+	t.used[match] = true
+	return t.interactions[match].Response.http(req), nil
+}
+
+func (t *Transport) record(req *http.Request, recorded Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := readBody(&resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	in := Interaction{
+		Request: recorded,
+		Response: Response{
+			Status:  resp.StatusCode,
+			Headers: flatten(resp.Header),
+		},
+	}
+	if json.Valid(body) {
+		in.Response.Body = compact(body)
+	} else {
+		in.Response.Text = string(body)
+	}
+
+	t.mu.Lock()
+	t.interactions = append(t.interactions, in)
+	t.mu.Unlock()
+	return resp, nil
+}
+
+// newRequest describes an outgoing request for recording and matching.
+func newRequest(req *http.Request) (Request, error) {
+	r := Request{
+		Method:      req.Method,
+		Path:        req.URL.Path,
+		Instruction: instructionFrom(req.Context()),
+	}
+	if query := req.URL.Query(); len(query) > 0 {
+		r.Params = make(map[string]string, len(query))
+		for k := range query {
+			r.Params[k] = query.Get(k)
+		}
+	}
+	if req.Body != nil {
+		body, err := readBody(&req.Body)
+		if err != nil {
+			return Request{}, err
+		}
+		if !json.Valid(body) {
+			return Request{}, fmt.Errorf("fixture: request body is not JSON")
+		}
+		r.Body = compact(body)
+	}
+
+	r.Headers = make(map[string]string)
+	for _, name := range []string{"X-API-Key", "X-Signature", "X-Timestamp", "X-Window"} {
+		if v := req.Header.Get(name); v != "" {
+			r.Headers[name] = v
+		}
+	}
+	for _, name := range redactedHeaders {
+		if _, ok := r.Headers[name]; ok {
+			r.Headers[name] = Redacted
+		}
+	}
+	if len(r.Headers) == 0 {
+		r.Headers = nil
+	}
+	return r, nil
+}
+
+// http builds the response served in replay mode.
+func (r Response) http(req *http.Request) *http.Response {
+	header := make(http.Header, len(r.Headers))
+	for k, v := range r.Headers {
+		header.Set(k, v)
+	}
+	body := []byte(r.Body)
+	if len(body) == 0 {
+		body = []byte(r.Text)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.Status, http.StatusText(r.Status)),
+		StatusCode:    r.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// readBody reads a request or response body and replaces it with a copy.
+func readBody(body *io.ReadCloser) ([]byte, error) {
+	data, err := io.ReadAll(*body)
+	(*body).Close()
+	if err != nil {
+		return nil, fmt.Errorf("fixture: failed to read body: %w", err)
+	}
+	*body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
+
+// compact returns valid JSON without insignificant whitespace, so that
+// bodies compare equal regardless of formatting.
+func compact(data []byte) json.RawMessage {
+	if len(data) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return data
+	}
+	return buf.Bytes()
+}
+
+// flatten keeps the response headers the SDK reads.
+func flatten(header http.Header) map[string]string {
+	var m map[string]string
+	for _, name := range []string{"Content-Type", "Retry-After"} {
+		if v := header.Get(name); v != "" {
+			if m == nil {
+				m = make(map[string]string)
+			}
+			m[name] = v
+		}
+	}
+	return m
+}
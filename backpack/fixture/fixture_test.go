@@ -0,0 +1,73 @@
+package fixture
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"echo": `+string(body)+`}`)
+	}))
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "fixtures.json")
+
+	recorder, err := NewTransport(path, ModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequestWithContext(WithInstruction(context.Background(), "orderExecute"),
+		http.MethodPost, server.URL+"/api/v1/order", strings.NewReader(`{"symbol": "SOL_USDC"}`))
+	req.Header.Set("X-API-Key", "key")
+	body := req.Body
+	resp, err := recorder.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if req.Body != body {
+		t.Error("RoundTrip replaced the body of the caller's request")
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("fixture file written before Close")
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	interactions, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(interactions) != 1 || interactions[0].Request.Instruction != "orderExecute" ||
+		interactions[0].Request.Headers["X-API-Key"] != Redacted {
+		t.Fatalf("recorded %+v", interactions)
+	}
+
+	replayer, err := NewTransport(path, ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ = http.NewRequest(http.MethodPost, "http://offline/api/v1/order", strings.NewReader(`{"symbol":"SOL_USDC"}`))
+	resp, err = replayer.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := io.ReadAll(resp.Body)
+	if string(got) != `{"echo":{"symbol":"SOL_USDC"}}` {
+		t.Errorf("replayed body %s", got)
+	}
+
+	req, _ = http.NewRequest(http.MethodPost, "http://offline/api/v1/order", strings.NewReader(`{"symbol":"BTC_USDC"}`))
+	if _, err := replayer.RoundTrip(req); !errors.Is(err, ErrNoFixture) {
+		t.Errorf("unmatched request: err = %v, want ErrNoFixture", err)
+	}
+}
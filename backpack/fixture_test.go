@@ -0,0 +1,39 @@
+package backpack_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack"
+	bperrors "github.com/solomeowl/backpack-exchange-sdk-go/backpack/errors"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/fixture"
+)
+
+func TestReplayMissingFixture(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixtures.json")
+	if err := os.WriteFile(path, []byte("[]"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	client, err := backpack.NewClient(
+		backpack.WithFixtures(path, fixture.ModeReplay),
+		backpack.WithRetryPolicy(backpack.RetryPolicy{MaxAttempts: 3}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	_, err = client.System.GetStatus(context.Background())
+	if !errors.Is(err, fixture.ErrNoFixture) {
+		t.Fatalf("err = %v, want ErrNoFixture", err)
+	}
+	if bperrors.IsRetryable(err) {
+		t.Error("missing fixture reported as retryable")
+	}
+	if _, ok := bperrors.IsRequestError(err); ok {
+		t.Error("missing fixture reported as a request error")
+	}
+}
@@ -1,9 +1,6 @@
 package auth
 
-import (
-	"strconv"
-	"time"
-)
+import "strconv"
 
 // Headers represents authentication headers for API requests.
 type Headers struct {
@@ -25,37 +22,40 @@ func (h Headers) ToMap() map[string]string {
 }
 
 // GenerateHeaders generates authentication headers for a standard API request.
-func (s *Signer) GenerateHeaders(instruction string, params map[string]any, window int64) Headers {
-	timestamp := time.Now().UnixMilli()
+// The timestamp is in Unix milliseconds.
+func (s *Signer) GenerateHeaders(instruction string, params map[string]any, timestamp, window int64) (Headers, error) {
 	signStr := BuildSigningString(instruction, params, timestamp, window)
-	signature := s.Sign(signStr)
+	signature, err := s.Sign(signStr)
+	if err != nil {
+		return Headers{}, err
+	}
 
 	return Headers{
-		APIKey:    s.publicKey,
+		APIKey:    s.PublicKey(),
 		Signature: signature,
 		Timestamp: strconv.FormatInt(timestamp, 10),
 		Window:    strconv.FormatInt(window, 10),
-	}
+	}, nil
 }
 
 // GenerateBatchHeaders generates authentication headers for batch order execution.
-func (s *Signer) GenerateBatchHeaders(orders []map[string]any, window int64) Headers {
-	timestamp := time.Now().UnixMilli()
+func (s *Signer) GenerateBatchHeaders(orders []map[string]any, timestamp, window int64) (Headers, error) {
 	signStr := BuildBatchSigningString(orders, timestamp, window)
-	signature := s.Sign(signStr)
+	signature, err := s.Sign(signStr)
+	if err != nil {
+		return Headers{}, err
+	}
 
 	return Headers{
-		APIKey:    s.publicKey,
+		APIKey:    s.PublicKey(),
 		Signature: signature,
 		Timestamp: strconv.FormatInt(timestamp, 10),
 		Window:    strconv.FormatInt(window, 10),
-	}
+	}, nil
 }
 
 // GenerateWSSignature generates a signature for WebSocket authentication.
-func (s *Signer) GenerateWSSignature(window int64) (signature string, timestamp int64) {
-	timestamp = time.Now().UnixMilli()
+func (s *Signer) GenerateWSSignature(timestamp, window int64) (string, error) {
 	signStr := BuildSigningString("subscribe", nil, timestamp, window)
-	signature = s.Sign(signStr)
-	return signature, timestamp
+	return s.Sign(signStr)
 }
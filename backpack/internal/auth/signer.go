@@ -1,54 +1,47 @@
-// Package auth provides ED25519 signing functionality for Backpack Exchange API.
+// Package auth provides request signing functionality for Backpack Exchange API.
 package auth
 
 import (
-	"crypto/ed25519"
 	"encoding/base64"
 	"fmt"
 	"sort"
 	"strconv"
 	"strings"
+
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/signing"
 )
 
-// Signer handles ED25519 signing for API requests.
+// Signer generates authentication headers using a signing.Signer.
 type Signer struct {
-	publicKey  string
-	privateKey ed25519.PrivateKey
+	signer signing.Signer
+}
+
+// New creates a new Signer backed by the given signing.Signer.
+func New(signer signing.Signer) *Signer {
+	return &Signer{signer: signer}
 }
 
-// NewSigner creates a new Signer from base64-encoded keys.
+// NewSigner creates a new Signer from base64-encoded keys held in memory.
 func NewSigner(publicKey, secretKey string) (*Signer, error) {
-	privateKeyBytes, err := base64.StdEncoding.DecodeString(secretKey)
+	signer, err := signing.NewKeySigner(publicKey, secretKey)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode secret key: %w", err)
-	}
-
-	// ED25519 seed is 32 bytes, full private key is 64 bytes
-	var privateKey ed25519.PrivateKey
-	if len(privateKeyBytes) == ed25519.SeedSize {
-		privateKey = ed25519.NewKeyFromSeed(privateKeyBytes)
-	} else if len(privateKeyBytes) == ed25519.PrivateKeySize {
-		privateKey = privateKeyBytes
-	} else {
-		return nil, fmt.Errorf("invalid secret key length: expected %d or %d bytes, got %d",
-			ed25519.SeedSize, ed25519.PrivateKeySize, len(privateKeyBytes))
+		return nil, err
 	}
-
-	return &Signer{
-		publicKey:  publicKey,
-		privateKey: privateKey,
-	}, nil
+	return New(signer), nil
 }
 
 // PublicKey returns the public key (API key).
 func (s *Signer) PublicKey() string {
-	return s.publicKey
+	return s.signer.PublicKey()
 }
 
 // Sign signs a message and returns a base64-encoded signature.
-func (s *Signer) Sign(message string) string {
-	signature := ed25519.Sign(s.privateKey, []byte(message))
-	return base64.StdEncoding.EncodeToString(signature)
+func (s *Signer) Sign(message string) (string, error) {
+	signature, err := s.signer.Sign([]byte(message))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign request: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(signature), nil
 }
 
 // BuildSigningString builds the signing string for an API request.
@@ -12,7 +12,10 @@ import (
 	"time"
 
 	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/errors"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/fixture"
 	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/internal/auth"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/ratelimit"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/timesync"
 )
 
 const (
@@ -28,6 +31,9 @@ type Client struct {
 	signer     *auth.Signer
 	window     int64
 	debug      bool
+	retry      RetryPolicy
+	limiter    *ratelimit.Limiter
+	clock      timesync.Clock
 }
 
 // Config holds configuration for the HTTP client.
@@ -37,8 +43,15 @@ type Config struct {
 	Signer     *auth.Signer
 	Window     int64
 	Debug      bool
+	Retry      RetryPolicy
+	Limiter    *ratelimit.Limiter
+	Clock      timesync.Clock
 }
 
+// requestFunc builds a new request for a single attempt, so that
+// authenticated requests are signed with a fresh timestamp on every retry.
+type requestFunc func() (*http.Request, error)
+
 // NewClient creates a new HTTP client with the given configuration.
 func NewClient(cfg Config) *Client {
 	baseURL := cfg.BaseURL
@@ -64,6 +77,9 @@ func NewClient(cfg Config) *Client {
 		signer:     cfg.Signer,
 		window:     window,
 		debug:      cfg.Debug,
+		retry:      cfg.Retry,
+		limiter:    cfg.Limiter,
+		clock:      cfg.Clock,
 	}
 }
 
@@ -72,6 +88,19 @@ func (c *Client) SetSigner(signer *auth.Signer) {
 	c.signer = signer
 }
 
+// SetClock sets the clock used to timestamp signed requests.
+func (c *Client) SetClock(clock timesync.Clock) {
+	c.clock = clock
+}
+
+// timestamp returns the current time in Unix milliseconds for request signing.
+func (c *Client) timestamp() int64 {
+	if c.clock != nil {
+		return c.clock.Now().UnixMilli()
+	}
+	return time.Now().UnixMilli()
+}
+
 // Get performs a GET request.
 func (c *Client) Get(ctx context.Context, path string, params map[string]string, result any) error {
 	return c.doRequest(ctx, http.MethodGet, path, params, nil, "", result)
@@ -125,41 +154,55 @@ func (c *Client) PostBatchOrders(ctx context.Context, path string, orders []map[
 		return fmt.Errorf("failed to marshal request body: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, reqURL, bytes.NewReader(bodyBytes))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
+	build := func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, reqURL, bytes.NewReader(bodyBytes))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
 
-	headers := c.signer.GenerateBatchHeaders(orders, c.window)
-	for k, v := range headers.ToMap() {
-		req.Header.Set(k, v)
+		headers, err := c.signer.GenerateBatchHeaders(orders, c.timestamp(), c.window)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range headers.ToMap() {
+			req.Header.Set(k, v)
+		}
+		return req, nil
 	}
 
-	return c.executeRequest(req, result)
+	return c.executeRequest(ctx, build, ratelimit.GroupOrder, isIdempotent(http.MethodPost), result)
 }
 
 func (c *Client) doRequest(ctx context.Context, method, path string, params map[string]string, body any, _ string, result any) error {
 	reqURL := c.buildURL(path, params)
 
-	var bodyReader io.Reader
+	var bodyBytes []byte
 	if body != nil {
-		bodyBytes, err := json.Marshal(body)
+		var err error
+		bodyBytes, err = json.Marshal(body)
 		if err != nil {
 			return fmt.Errorf("failed to marshal request body: %w", err)
 		}
-		bodyReader = bytes.NewReader(bodyBytes)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, reqURL, bodyReader)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
+	build := func() (*http.Request, error) {
+		var bodyReader io.Reader
+		if body != nil {
+			bodyReader = bytes.NewReader(bodyBytes)
+		}
 
-	if body != nil {
-		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+		req, err := http.NewRequestWithContext(ctx, method, reqURL, bodyReader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json; charset=utf-8")
+		}
+		return req, nil
 	}
 
-	return c.executeRequest(req, result)
+	return c.executeRequest(ctx, build, ratelimit.GroupPublic, isIdempotent(method), result)
 }
 
 func (c *Client) doAuthenticatedRequest(ctx context.Context, method, path string, params map[string]string, body any, instruction string, result any) error {
@@ -169,15 +212,15 @@ func (c *Client) doAuthenticatedRequest(ctx context.Context, method, path string
 
 	reqURL := c.buildURL(path, params)
 
-	var bodyReader io.Reader
+	var bodyBytes []byte
 	var signParams map[string]any
 
 	if body != nil {
-		bodyBytes, err := json.Marshal(body)
+		var err error
+		bodyBytes, err = json.Marshal(body)
 		if err != nil {
 			return fmt.Errorf("failed to marshal request body: %w", err)
 		}
-		bodyReader = bytes.NewReader(bodyBytes)
 
 		// Convert body to map for signing
 		if err := json.Unmarshal(bodyBytes, &signParams); err != nil {
@@ -190,17 +233,38 @@ func (c *Client) doAuthenticatedRequest(ctx context.Context, method, path string
 		}
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, reqURL, bodyReader)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
+	build := func() (*http.Request, error) {
+		var bodyReader io.Reader
+		if body != nil {
+			bodyReader = bytes.NewReader(bodyBytes)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, reqURL, bodyReader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
 
-	headers := c.signer.GenerateHeaders(instruction, signParams, c.window)
-	for k, v := range headers.ToMap() {
-		req.Header.Set(k, v)
+		headers, err := c.signer.GenerateHeaders(instruction, signParams, c.timestamp(), c.window)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range headers.ToMap() {
+			req.Header.Set(k, v)
+		}
+		return req, nil
 	}
 
-	return c.executeRequest(req, result)
+	return c.executeRequest(ctx, build, rateLimitGroup(instruction), isIdempotent(method), result)
+}
+
+// rateLimitGroup returns the rate limit group of an authenticated instruction.
+func rateLimitGroup(instruction string) ratelimit.Group {
+	switch instruction {
+	case "orderExecute", "orderCancel", "orderCancelAll":
+		return ratelimit.GroupOrder
+	default:
+		return ratelimit.GroupQuery
+	}
 }
 
 func (c *Client) buildURL(path string, params map[string]string) string {
@@ -217,24 +281,77 @@ func (c *Client) buildURL(path string, params map[string]string) string {
 	return reqURL
 }
 
-func (c *Client) executeRequest(req *http.Request, result any) error {
+// executeRequest performs the request, retrying failed attempts according to
+// the retry policy when the request is safe to replay.
+func (c *Client) executeRequest(ctx context.Context, build requestFunc, group ratelimit.Group, idempotent bool, result any) error {
+	attempts := 1
+	if idempotent && c.retry.enabled() {
+		attempts = c.retry.MaxAttempts
+	}
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			delay := c.retry.backoff(attempt - 1)
+			if apiErr, ok := errors.IsAPIError(err); ok && apiErr.RetryAfter > delay {
+				delay = apiErr.RetryAfter
+			}
+			if !sleep(ctx, delay) {
+				return err
+			}
+		}
+
+		if c.limiter != nil {
+			if err := c.limiter.Wait(ctx, group); err != nil {
+				return err
+			}
+		}
+
+		err = c.attempt(build, result)
+		if err == nil || ctx.Err() != nil || !errors.IsRetryable(err) {
+			return err
+		}
+	}
+
+	return err
+}
+
+func (c *Client) attempt(build requestFunc, result any) error {
+	req, err := build()
+	if err != nil {
+		return err
+	}
+
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
+		// A request missing from the replayed fixtures is a problem with
+		// the test, not the network: report it as is, and do not retry it.
+		if urlErr, ok := err.(*url.Error); ok && errors.Is(urlErr.Err, fixture.ErrNoFixture) {
+			return urlErr.Err
+		}
 		return &errors.RequestError{
 			Method:  req.Method,
 			URL:     req.URL.String(),
 			Message: err.Error(),
+			Err:     err,
 		}
 	}
 	defer resp.Body.Close()
 
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
+		return &errors.RequestError{
+			Method:  req.Method,
+			URL:     req.URL.String(),
+			Message: fmt.Sprintf("failed to read response body: %v", err),
+			Err:     err,
+		}
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return errors.ParseAPIError(resp.StatusCode, bodyBytes)
+		apiErr := errors.ParseAPIError(resp.StatusCode, bodyBytes)
+		apiErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		return apiErr
 	}
 
 	if result != nil && len(bodyBytes) > 0 {
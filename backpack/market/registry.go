@@ -0,0 +1,435 @@
+// Package market provides cached market metadata and pre-trade order validation for Backpack Exchange.
+package market
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/enums"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/services"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/types"
+)
+
+// DefaultTTL is how long loaded market metadata is considered fresh.
+const DefaultTTL = 5 * time.Minute
+
+// Lookup errors.
+var (
+	ErrUnknownMarket = errors.New("market: unknown market")
+	ErrUnknownAsset  = errors.New("market: unknown asset")
+)
+
+// MarketLister lists markets. *services.MarketsService implements MarketLister.
+type MarketLister interface {
+	GetMarkets(ctx context.Context, params *services.GetMarketsParams) ([]types.Market, error)
+}
+
+// AssetLister lists assets and their collateral parameters.
+// *services.AssetsService implements AssetLister.
+type AssetLister interface {
+	GetAssets(ctx context.Context) ([]types.Asset, error)
+	GetCollateral(ctx context.Context) ([]types.CollateralInfo, error)
+}
+
+// EventType identifies a change detected by a Registry refresh.
+type EventType int
+
+const (
+	// EventListed is sent when a market appears.
+	EventListed EventType = iota
+	// EventDelisted is sent when a market disappears.
+	EventDelisted
+	// EventStateChanged is sent when a market's order book state changes.
+	EventStateChanged
+)
+
+// String returns the name of the event type.
+func (t EventType) String() string {
+	switch t {
+	case EventListed:
+		return "listed"
+	case EventDelisted:
+		return "delisted"
+	case EventStateChanged:
+		return "stateChanged"
+	default:
+		return "unknown"
+	}
+}
+
+// Event describes a market change.
+type Event struct {
+	Type      EventType
+	Market    types.Market         // Current metadata, or the last known metadata if delisted
+	PrevState enums.OrderBookState // Previous order book state, set for EventStateChanged
+}
+
+// Registry caches market, asset and collateral metadata loaded from the API
+// and indexes markets by symbol, base, quote and market type. Lookups load
+// the metadata on first use and reload it once it is older than the TTL;
+// Start additionally refreshes it in the background. A single Registry can
+// be shared by any number of components. It is safe for concurrent use.
+type Registry struct {
+	markets  MarketLister
+	assets   AssetLister
+	ttl      time.Duration
+	interval time.Duration
+
+	mu        sync.RWMutex
+	snap      *snapshot
+	listeners []func(Event)
+	cancel    context.CancelFunc
+	done      chan struct{}
+
+	refreshMu sync.Mutex
+}
+
+type snapshot struct {
+	loadedAt   time.Time
+	bySymbol   map[string]types.Market
+	symbols    []string
+	byBase     map[enums.CustodyAsset][]string
+	byQuote    map[enums.CustodyAsset][]string
+	byType     map[enums.MarketType][]string
+	assets     map[enums.CustodyAsset]types.Asset
+	collateral map[enums.CustodyAsset]types.CollateralInfo
+}
+
+// RegistryOption is a functional option for configuring a Registry.
+type RegistryOption func(*Registry)
+
+// WithTTL sets how long loaded metadata is used before a lookup reloads it.
+// A TTL of zero or less keeps metadata until the next explicit refresh.
+func WithTTL(ttl time.Duration) RegistryOption {
+	return func(r *Registry) {
+		r.ttl = ttl
+	}
+}
+
+// WithAssets also loads assets and collateral parameters from the given source.
+func WithAssets(assets AssetLister) RegistryOption {
+	return func(r *Registry) {
+		r.assets = assets
+	}
+}
+
+// WithRefreshInterval sets how often Start refreshes the metadata.
+// It defaults to the TTL.
+func WithRefreshInterval(interval time.Duration) RegistryOption {
+	return func(r *Registry) {
+		r.interval = interval
+	}
+}
+
+// NewRegistry creates a new Registry. Metadata is loaded on first use.
+func NewRegistry(markets MarketLister, opts ...RegistryOption) *Registry {
+	r := &Registry{
+		markets: markets,
+		ttl:     DefaultTTL,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.interval <= 0 {
+		r.interval = r.ttl
+	}
+	if r.interval <= 0 {
+		r.interval = DefaultTTL
+	}
+	return r
+}
+
+// OnChange registers a listener invoked after a refresh for every market
+// that was listed, delisted or changed its order book state. Listeners are
+// not invoked for the initial load.
+func (r *Registry) OnChange(fn func(Event)) {
+	r.mu.Lock()
+	r.listeners = append(r.listeners, fn)
+	r.mu.Unlock()
+}
+
+// Refresh reloads the metadata from the API and notifies listeners of changes.
+func (r *Registry) Refresh(ctx context.Context) error {
+	r.refreshMu.Lock()
+	events, err := r.refreshLocked(ctx)
+	r.refreshMu.Unlock()
+	r.notify(events)
+	return err
+}
+
+// refreshLocked reloads the metadata and returns the changes since the
+// previous load. Listeners are notified by the caller once refreshMu is
+// released, so they may use the registry.
+func (r *Registry) refreshLocked(ctx context.Context) ([]Event, error) {
+	markets, err := r.markets.GetMarkets(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("market: failed to load markets: %w", err)
+	}
+	var assets []types.Asset
+	var collateral []types.CollateralInfo
+	if r.assets != nil {
+		if assets, err = r.assets.GetAssets(ctx); err != nil {
+			return nil, fmt.Errorf("market: failed to load assets: %w", err)
+		}
+		if collateral, err = r.assets.GetCollateral(ctx); err != nil {
+			return nil, fmt.Errorf("market: failed to load collateral: %w", err)
+		}
+	}
+
+	next := newSnapshot(markets, assets, collateral)
+
+	r.mu.Lock()
+	prev := r.snap
+	r.snap = next
+	r.mu.Unlock()
+
+	if prev == nil {
+		return nil, nil
+	}
+	return diff(prev, next), nil
+}
+
+// notify invokes the listeners for each event.
+func (r *Registry) notify(events []Event) {
+	if len(events) == 0 {
+		return
+	}
+	r.mu.RLock()
+	listeners := r.listeners
+	r.mu.RUnlock()
+	for _, ev := range events {
+		for _, fn := range listeners {
+			fn(ev)
+		}
+	}
+}
+
+// Start refreshes the metadata periodically in the background until Stop
+// is called or ctx is done. Refresh errors are ignored; the previous
+// metadata stays in use.
+func (r *Registry) Start(ctx context.Context) {
+	r.mu.Lock()
+	if r.cancel != nil {
+		r.mu.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	r.cancel = cancel
+	r.done = make(chan struct{})
+	done := r.done
+	r.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		for {
+			_ = r.Refresh(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops background refreshes started by Start.
+func (r *Registry) Stop() {
+	r.mu.Lock()
+	cancel, done := r.cancel, r.done
+	r.cancel, r.done = nil, nil
+	r.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// LoadedAt returns when the metadata was last loaded, or the zero time if
+// it has not been loaded yet.
+func (r *Registry) LoadedAt() time.Time {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.snap == nil {
+		return time.Time{}
+	}
+	return r.snap.loadedAt
+}
+
+// Market returns the metadata of the market with the given symbol.
+func (r *Registry) Market(ctx context.Context, symbol string) (*types.Market, error) {
+	snap, err := r.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := snap.bySymbol[symbol]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownMarket, symbol)
+	}
+	return &m, nil
+}
+
+// Markets returns all markets, sorted by symbol.
+func (r *Registry) Markets(ctx context.Context) ([]types.Market, error) {
+	snap, err := r.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return snap.collect(snap.symbols), nil
+}
+
+// MarketsByBase returns the markets with the given base asset, sorted by symbol.
+func (r *Registry) MarketsByBase(ctx context.Context, asset enums.CustodyAsset) ([]types.Market, error) {
+	snap, err := r.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return snap.collect(snap.byBase[asset]), nil
+}
+
+// MarketsByQuote returns the markets with the given quote asset, sorted by symbol.
+func (r *Registry) MarketsByQuote(ctx context.Context, asset enums.CustodyAsset) ([]types.Market, error) {
+	snap, err := r.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return snap.collect(snap.byQuote[asset]), nil
+}
+
+// MarketsByType returns the markets of the given type, sorted by symbol.
+func (r *Registry) MarketsByType(ctx context.Context, marketType enums.MarketType) ([]types.Market, error) {
+	snap, err := r.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return snap.collect(snap.byType[marketType]), nil
+}
+
+// Asset returns the metadata of the given asset. It requires WithAssets.
+func (r *Registry) Asset(ctx context.Context, symbol enums.CustodyAsset) (*types.Asset, error) {
+	snap, err := r.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	a, ok := snap.assets[symbol]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownAsset, symbol)
+	}
+	return &a, nil
+}
+
+// Collateral returns the collateral parameters of the given asset. It
+// requires WithAssets.
+func (r *Registry) Collateral(ctx context.Context, symbol enums.CustodyAsset) (*types.CollateralInfo, error) {
+	snap, err := r.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c, ok := snap.collateral[symbol]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownAsset, symbol)
+	}
+	return &c, nil
+}
+
+// snapshot returns the current metadata, loading it if it is missing or stale.
+func (r *Registry) snapshot(ctx context.Context) (*snapshot, error) {
+	if snap := r.current(); r.fresh(snap) {
+		return snap, nil
+	}
+
+	r.refreshMu.Lock()
+	// Another caller may have refreshed while we waited.
+	snap := r.current()
+	if r.fresh(snap) {
+		r.refreshMu.Unlock()
+		return snap, nil
+	}
+	events, err := r.refreshLocked(ctx)
+	next := r.current()
+	r.refreshMu.Unlock()
+	r.notify(events)
+
+	if err != nil {
+		if snap != nil {
+			// Keep serving stale metadata rather than failing lookups.
+			return snap, nil
+		}
+		return nil, err
+	}
+	return next, nil
+}
+
+func (r *Registry) current() *snapshot {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.snap
+}
+
+func (r *Registry) fresh(snap *snapshot) bool {
+	return snap != nil && (r.ttl <= 0 || time.Since(snap.loadedAt) < r.ttl)
+}
+
+func newSnapshot(markets []types.Market, assets []types.Asset, collateral []types.CollateralInfo) *snapshot {
+	s := &snapshot{
+		loadedAt:   time.Now(),
+		bySymbol:   make(map[string]types.Market, len(markets)),
+		byBase:     make(map[enums.CustodyAsset][]string),
+		byQuote:    make(map[enums.CustodyAsset][]string),
+		byType:     make(map[enums.MarketType][]string),
+		assets:     make(map[enums.CustodyAsset]types.Asset, len(assets)),
+		collateral: make(map[enums.CustodyAsset]types.CollateralInfo, len(collateral)),
+	}
+
+	sort.Slice(markets, func(i, j int) bool { return markets[i].Symbol < markets[j].Symbol })
+	for _, m := range markets {
+		s.bySymbol[m.Symbol] = m
+		s.symbols = append(s.symbols, m.Symbol)
+		s.byBase[m.BaseSymbol] = append(s.byBase[m.BaseSymbol], m.Symbol)
+		s.byQuote[m.QuoteSymbol] = append(s.byQuote[m.QuoteSymbol], m.Symbol)
+		s.byType[m.MarketType] = append(s.byType[m.MarketType], m.Symbol)
+	}
+	for _, a := range assets {
+		s.assets[a.Symbol] = a
+	}
+	for _, c := range collateral {
+		s.collateral[c.Symbol] = c
+	}
+	return s
+}
+
+func (s *snapshot) collect(symbols []string) []types.Market {
+	markets := make([]types.Market, len(symbols))
+	for i, symbol := range symbols {
+		markets[i] = s.bySymbol[symbol]
+	}
+	return markets
+}
+
+// diff returns the market events between two snapshots, ordered by symbol.
+func diff(prev, next *snapshot) []Event {
+	var events []Event
+	for _, symbol := range next.symbols {
+		m := next.bySymbol[symbol]
+		old, ok := prev.bySymbol[symbol]
+		switch {
+		case !ok:
+			events = append(events, Event{Type: EventListed, Market: m})
+		case old.OrderBookState != m.OrderBookState:
+			events = append(events, Event{Type: EventStateChanged, Market: m, PrevState: old.OrderBookState})
+		}
+	}
+	for _, symbol := range prev.symbols {
+		if _, ok := next.bySymbol[symbol]; !ok {
+			events = append(events, Event{Type: EventDelisted, Market: prev.bySymbol[symbol]})
+		}
+	}
+	return events
+}
@@ -0,0 +1,64 @@
+package market
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/services"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/types"
+)
+
+type fakeMarkets struct {
+	mu      sync.Mutex
+	markets []types.Market
+}
+
+func (f *fakeMarkets) GetMarkets(ctx context.Context, params *services.GetMarketsParams) ([]types.Market, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]types.Market(nil), f.markets...), nil
+}
+
+func (f *fakeMarkets) set(markets ...types.Market) {
+	f.mu.Lock()
+	f.markets = markets
+	f.mu.Unlock()
+}
+
+// TestRegistryListenerReentry checks that listeners can use the registry,
+// including triggering a refresh, without deadlocking.
+func TestRegistryListenerReentry(t *testing.T) {
+	ctx := context.Background()
+	source := &fakeMarkets{markets: []types.Market{{Symbol: "SOL_USDC"}}}
+	r := NewRegistry(source, WithTTL(time.Nanosecond))
+	if _, err := r.Market(ctx, "SOL_USDC"); err != nil {
+		t.Fatal(err)
+	}
+
+	var events []Event
+	r.OnChange(func(ev Event) {
+		events = append(events, ev)
+		if _, err := r.Market(ctx, ev.Market.Symbol); err != nil && ev.Type != EventDelisted {
+			t.Errorf("Market(%s) in listener: %v", ev.Market.Symbol, err)
+		}
+		_ = r.Refresh(ctx)
+	})
+
+	source.set(types.Market{Symbol: "SOL_USDC"}, types.Market{Symbol: "BTC_USDC"})
+	done := make(chan error, 1)
+	go func() { done <- r.Refresh(ctx) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Refresh deadlocked when a listener used the registry")
+	}
+
+	if len(events) != 1 || events[0].Type != EventListed || events[0].Market.Symbol != "BTC_USDC" {
+		t.Errorf("events = %+v, want one listing of BTC_USDC", events)
+	}
+}
@@ -0,0 +1,305 @@
+package market
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/enums"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/types"
+)
+
+// Validation errors. A *ValidationError wraps one of them, so callers can
+// match the violated rule with errors.Is.
+var (
+	ErrMarketClosed    = errors.New("market: order book is closed")
+	ErrCancelOnly      = errors.New("market: order book only accepts cancellations")
+	ErrPostOnly        = errors.New("market: order book only accepts post-only limit orders")
+	ErrLimitOnly       = errors.New("market: order book only accepts limit orders")
+	ErrInvalidNumber   = errors.New("market: invalid number")
+	ErrMissingPrice    = errors.New("market: limit order requires a price")
+	ErrTickSize        = errors.New("market: price is not a multiple of the tick size")
+	ErrPriceTooLow     = errors.New("market: price is below the minimum price")
+	ErrPriceTooHigh    = errors.New("market: price is above the maximum price")
+	ErrStepSize        = errors.New("market: quantity is not a multiple of the step size")
+	ErrQuantityTooLow  = errors.New("market: quantity is below the minimum quantity")
+	ErrQuantityTooHigh = errors.New("market: quantity is above the maximum quantity")
+)
+
+// ValidationError describes an order field that violates a market rule.
+type ValidationError struct {
+	Symbol string // Market symbol
+	Field  string // Order field, e.g. "price" or "quantity"
+	Value  string // Offending value
+	Limit  string // Violated limit, e.g. the tick size; empty if not applicable
+	Err    error  // One of the Err* rule errors
+}
+
+func (e *ValidationError) Error() string {
+	msg := fmt.Sprintf("%v (%s %s=%s", e.Err, e.Symbol, e.Field, e.Value)
+	if e.Limit != "" {
+		msg += ", limit " + e.Limit
+	}
+	return msg + ")"
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// MarketSource provides market metadata by symbol. *Registry implements MarketSource.
+type MarketSource interface {
+	Market(ctx context.Context, symbol string) (*types.Market, error)
+}
+
+// Validator checks orders against market filters and order book state
+// before they are submitted. It implements services.OrderValidator.
+type Validator struct {
+	markets MarketSource
+	round   bool
+}
+
+// ValidatorOption is a functional option for configuring a Validator.
+type ValidatorOption func(*Validator)
+
+// WithRounding makes ValidateOrder round prices and quantities to the
+// market's tick and step sizes before checking them. See Round.
+func WithRounding(enabled bool) ValidatorOption {
+	return func(v *Validator) {
+		v.round = enabled
+	}
+}
+
+// NewValidator creates a new Validator using the given market metadata.
+func NewValidator(markets MarketSource, opts ...ValidatorOption) *Validator {
+	v := &Validator{markets: markets}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// ValidateOrder checks params against the market's rules, first rounding
+// them in place if rounding is enabled.
+func (v *Validator) ValidateOrder(ctx context.Context, params *types.ExecuteOrderParams) error {
+	m, err := v.markets.Market(ctx, params.Symbol)
+	if err != nil {
+		return err
+	}
+	if v.round {
+		if err := Round(m, params); err != nil {
+			return err
+		}
+	}
+	return Check(m, params)
+}
+
+// Check validates params against the filters and order book state of m.
+func Check(m *types.Market, params *types.ExecuteOrderParams) error {
+	if err := checkState(m, params); err != nil {
+		return err
+	}
+
+	f, err := parseFilters(m)
+	if err != nil {
+		return err
+	}
+
+	if params.OrderType == enums.OrderTypeLimit && params.Price == "" {
+		return &ValidationError{Symbol: m.Symbol, Field: "price", Err: ErrMissingPrice}
+	}
+
+	for _, p := range priceFields(params) {
+		if err := f.checkPrice(m.Symbol, p.name, *p.value); err != nil {
+			return err
+		}
+	}
+	for _, q := range quantityFields(params) {
+		if err := f.checkQuantity(m.Symbol, q.name, *q.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Round rounds the prices and quantities of params in place to the tick and
+// step sizes of m. Limit prices are rounded in the passive direction (bids
+// down, asks up) so rounding never worsens the price; trigger prices are
+// rounded to the nearest tick and quantities are rounded down.
+func Round(m *types.Market, params *types.ExecuteOrderParams) error {
+	f, err := parseFilters(m)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range priceFields(params) {
+		if *p.value == "" || f.tickSize.Sign() <= 0 {
+			continue
+		}
+		d, err := types.ParseDecimal(*p.value)
+		if err != nil {
+			return &ValidationError{Symbol: m.Symbol, Field: p.name, Value: *p.value, Err: ErrInvalidNumber}
+		}
+		rounded := d
+		switch {
+		case p.name != "price":
+			rounded = d.Quantize(f.tickSize)
+		case params.Side == enums.SideAsk:
+			rounded = d.QuantizeUp(f.tickSize)
+		default:
+			rounded = d.QuantizeDown(f.tickSize)
+		}
+		if d.IsPositive() && rounded.IsZero() {
+			return f.priceTooLow(m.Symbol, p.name, *p.value)
+		}
+		*p.value = rounded.String()
+	}
+
+	for _, q := range quantityFields(params) {
+		if *q.value == "" || f.stepSize.Sign() <= 0 {
+			continue
+		}
+		d, err := types.ParseDecimal(*q.value)
+		if err != nil {
+			return &ValidationError{Symbol: m.Symbol, Field: q.name, Value: *q.value, Err: ErrInvalidNumber}
+		}
+		rounded := d.QuantizeDown(f.stepSize)
+		if d.IsPositive() && rounded.IsZero() {
+			return f.quantityTooLow(m.Symbol, q.name, *q.value)
+		}
+		*q.value = rounded.String()
+	}
+	return nil
+}
+
+func checkState(m *types.Market, params *types.ExecuteOrderParams) error {
+	var err error
+	switch m.OrderBookState {
+	case enums.OrderBookStateClosed:
+		err = ErrMarketClosed
+	case enums.OrderBookStateCancelOnly:
+		err = ErrCancelOnly
+	case enums.OrderBookStatePostOnly:
+		if params.OrderType != enums.OrderTypeLimit || params.PostOnly == nil || !*params.PostOnly {
+			err = ErrPostOnly
+		}
+	case enums.OrderBookStateLimitOnly:
+		if params.OrderType != enums.OrderTypeLimit {
+			err = ErrLimitOnly
+		}
+	}
+	if err != nil {
+		return &ValidationError{Symbol: m.Symbol, Field: "orderBookState", Value: string(m.OrderBookState), Err: err}
+	}
+	return nil
+}
+
+type field struct {
+	name  string
+	value *string
+}
+
+func priceFields(p *types.ExecuteOrderParams) []field {
+	return []field{
+		{"price", &p.Price},
+		{"triggerPrice", &p.TriggerPrice},
+		{"stopLossTriggerPrice", &p.StopLossTriggerPrice},
+		{"stopLossLimitPrice", &p.StopLossLimitPrice},
+		{"takeProfitTriggerPrice", &p.TakeProfitTriggerPrice},
+		{"takeProfitLimitPrice", &p.TakeProfitLimitPrice},
+	}
+}
+
+func quantityFields(p *types.ExecuteOrderParams) []field {
+	return []field{
+		{"quantity", &p.Quantity},
+		{"triggerQuantity", &p.TriggerQuantity},
+	}
+}
+
+// filters holds the parsed filters of a market. Zero values mean the
+// corresponding rule is not set.
+type filters struct {
+	tickSize    types.Decimal
+	minPrice    types.Decimal
+	maxPrice    types.Decimal
+	stepSize    types.Decimal
+	minQuantity types.Decimal
+	maxQuantity types.Decimal
+}
+
+func parseFilters(m *types.Market) (*filters, error) {
+	var f filters
+	var err error
+	parse := func(dst *types.Decimal, get func() (types.Decimal, error)) {
+		if err == nil {
+			*dst, err = get()
+		}
+	}
+	parse(&f.tickSize, m.Filters.Price.TickSizeDecimal)
+	parse(&f.minPrice, m.Filters.Price.MinPriceDecimal)
+	parse(&f.maxPrice, m.Filters.Price.MaxPriceDecimal)
+	parse(&f.stepSize, m.Filters.Quantity.StepSizeDecimal)
+	parse(&f.minQuantity, m.Filters.Quantity.MinQuantityDecimal)
+	parse(&f.maxQuantity, m.Filters.Quantity.MaxQuantityDecimal)
+	if err != nil {
+		return nil, fmt.Errorf("market: invalid filters for %s: %w", m.Symbol, err)
+	}
+	return &f, nil
+}
+
+func (f *filters) checkPrice(symbol, name, value string) error {
+	if value == "" {
+		return nil
+	}
+	d, err := types.ParseDecimal(value)
+	if err != nil || !d.IsPositive() {
+		return &ValidationError{Symbol: symbol, Field: name, Value: value, Err: ErrInvalidNumber}
+	}
+	switch {
+	case !d.IsMultipleOf(f.tickSize):
+		return &ValidationError{Symbol: symbol, Field: name, Value: value, Limit: f.tickSize.String(), Err: ErrTickSize}
+	case f.minPrice.IsPositive() && d.LessThan(f.minPrice):
+		return &ValidationError{Symbol: symbol, Field: name, Value: value, Limit: f.minPrice.String(), Err: ErrPriceTooLow}
+	case f.maxPrice.IsPositive() && d.GreaterThan(f.maxPrice):
+		return &ValidationError{Symbol: symbol, Field: name, Value: value, Limit: f.maxPrice.String(), Err: ErrPriceTooHigh}
+	}
+	return nil
+}
+
+func (f *filters) checkQuantity(symbol, name, value string) error {
+	if value == "" {
+		return nil
+	}
+	d, err := types.ParseDecimal(value)
+	if err != nil || !d.IsPositive() {
+		return &ValidationError{Symbol: symbol, Field: name, Value: value, Err: ErrInvalidNumber}
+	}
+	switch {
+	case !d.IsMultipleOf(f.stepSize):
+		return &ValidationError{Symbol: symbol, Field: name, Value: value, Limit: f.stepSize.String(), Err: ErrStepSize}
+	case f.minQuantity.IsPositive() && d.LessThan(f.minQuantity):
+		return &ValidationError{Symbol: symbol, Field: name, Value: value, Limit: f.minQuantity.String(), Err: ErrQuantityTooLow}
+	case f.maxQuantity.IsPositive() && d.GreaterThan(f.maxQuantity):
+		return &ValidationError{Symbol: symbol, Field: name, Value: value, Limit: f.maxQuantity.String(), Err: ErrQuantityTooHigh}
+	}
+	return nil
+}
+
+// priceTooLow returns the error for a positive price below one tick, which
+// rounding would turn into zero.
+func (f *filters) priceTooLow(symbol, name, value string) error {
+	if f.minPrice.IsPositive() {
+		return &ValidationError{Symbol: symbol, Field: name, Value: value, Limit: f.minPrice.String(), Err: ErrPriceTooLow}
+	}
+	return &ValidationError{Symbol: symbol, Field: name, Value: value, Limit: f.tickSize.String(), Err: ErrTickSize}
+}
+
+// quantityTooLow returns the error for a positive quantity below one step,
+// which rounding would turn into zero.
+func (f *filters) quantityTooLow(symbol, name, value string) error {
+	if f.minQuantity.IsPositive() {
+		return &ValidationError{Symbol: symbol, Field: name, Value: value, Limit: f.minQuantity.String(), Err: ErrQuantityTooLow}
+	}
+	return &ValidationError{Symbol: symbol, Field: name, Value: value, Limit: f.stepSize.String(), Err: ErrStepSize}
+}
@@ -0,0 +1,95 @@
+package market
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/enums"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/types"
+)
+
+func TestRoundToZero(t *testing.T) {
+	market := func(minPrice, minQuantity string) *types.Market {
+		return &types.Market{
+			Symbol: "SOL_USDC",
+			Filters: types.OrderBookFilters{
+				Price:    types.PriceFilter{MinPrice: minPrice, TickSize: "0.01"},
+				Quantity: types.QuantityFilter{MinQuantity: minQuantity, StepSize: "0.1"},
+			},
+		}
+	}
+	tests := []struct {
+		name   string
+		market *types.Market
+		params types.ExecuteOrderParams
+		field  string
+		limit  string
+		want   error
+	}{
+		{
+			name:   "quantity below the minimum",
+			market: market("0.01", "0.5"),
+			params: types.ExecuteOrderParams{Side: enums.SideBid, Price: "100", Quantity: "0.05"},
+			field:  "quantity",
+			limit:  "0.5",
+			want:   ErrQuantityTooLow,
+		},
+		{
+			name:   "quantity below one step",
+			market: market("", ""),
+			params: types.ExecuteOrderParams{Side: enums.SideBid, Price: "100", Quantity: "0.05"},
+			field:  "quantity",
+			limit:  "0.1",
+			want:   ErrStepSize,
+		},
+		{
+			name:   "bid price below the minimum",
+			market: market("0.05", "0.1"),
+			params: types.ExecuteOrderParams{Side: enums.SideBid, Price: "0.004", Quantity: "1"},
+			field:  "price",
+			limit:  "0.05",
+			want:   ErrPriceTooLow,
+		},
+		{
+			name:   "trigger price below one tick",
+			market: market("", "0.1"),
+			params: types.ExecuteOrderParams{Side: enums.SideAsk, TriggerPrice: "0.004", Quantity: "1"},
+			field:  "triggerPrice",
+			limit:  "0.01",
+			want:   ErrTickSize,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := tt.params
+			err := Round(tt.market, &params)
+			var verr *ValidationError
+			if !errors.Is(err, tt.want) || !errors.As(err, &verr) {
+				t.Fatalf("Round error = %v, want %v", err, tt.want)
+			}
+			if verr.Field != tt.field || verr.Limit != tt.limit {
+				t.Errorf("error on %s with limit %s, want %s with limit %s", verr.Field, verr.Limit, tt.field, tt.limit)
+			}
+		})
+	}
+}
+
+func TestRound(t *testing.T) {
+	m := &types.Market{
+		Symbol: "SOL_USDC",
+		Filters: types.OrderBookFilters{
+			Price:    types.PriceFilter{TickSize: "0.01"},
+			Quantity: types.QuantityFilter{MinQuantity: "0.1", StepSize: "0.1"},
+		},
+	}
+	params := types.ExecuteOrderParams{Side: enums.SideAsk, OrderType: enums.OrderTypeLimit, Price: "100.001", Quantity: "1.25"}
+	if err := Round(m, &params); err != nil {
+		t.Fatalf("Round: %v", err)
+	}
+	if params.Price != "100.01" || params.Quantity != "1.2" {
+		t.Errorf("rounded to %s at %s, want 1.2 at 100.01", params.Quantity, params.Price)
+	}
+	if err := Check(m, &params); err != nil {
+		t.Errorf("Check after Round: %v", err)
+	}
+}
@@ -0,0 +1,148 @@
+package backpack
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/fixture"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/services"
+)
+
+// Request describes a single REST call as seen by middleware.
+type Request struct {
+	Method        string            // HTTP method
+	Path          string            // Endpoint path, e.g. "api/v1/order"
+	Instruction   string            // Signing instruction; empty for public endpoints
+	Params        map[string]string // Query parameters
+	Body          any               // Request body; []map[string]any for batch orders
+	Authenticated bool              // Whether the request is signed
+	Result        any               // Destination for the decoded response; may be nil
+}
+
+// batchOrdersPath is the endpoint of batch order placement.
+const batchOrdersPath = "api/v1/orders"
+
+// Handler performs a REST call and decodes the response into req.Result.
+type Handler func(ctx context.Context, req *Request) error
+
+// Middleware wraps a Handler to observe or alter REST calls.
+// Middleware may inspect req.Result after calling next, return early
+// without calling next, or replace the returned error.
+type Middleware func(next Handler) Handler
+
+// middlewareClient routes service calls through a middleware chain before
+// handing them to the underlying HTTP client.
+type middlewareClient struct {
+	handler Handler
+}
+
+func newMiddlewareClient(next services.HTTPClient, middleware []Middleware) *middlewareClient {
+	handler := func(ctx context.Context, req *Request) error {
+		return dispatch(ctx, next, req)
+	}
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return &middlewareClient{handler: handler}
+}
+
+// recordInstruction passes the signing instruction of a request to the
+// fixture transport.
+func recordInstruction(next Handler) Handler {
+	return func(ctx context.Context, req *Request) error {
+		if req.Instruction != "" {
+			ctx = fixture.WithInstruction(ctx, req.Instruction)
+		}
+		return next(ctx, req)
+	}
+}
+
+func dispatch(ctx context.Context, client services.HTTPClient, req *Request) error {
+	if req.Authenticated {
+		switch req.Method {
+		case http.MethodGet:
+			return client.GetAuthenticated(ctx, req.Path, req.Params, req.Instruction, req.Result)
+		case http.MethodPost:
+			if isBatchOrder(req) {
+				orders, ok := req.Body.([]map[string]any)
+				if !ok {
+					return fmt.Errorf("backpack: batch order body must be []map[string]any, got %T", req.Body)
+				}
+				return client.PostBatchOrders(ctx, req.Path, orders, req.Result)
+			}
+			return client.PostAuthenticated(ctx, req.Path, req.Body, req.Instruction, req.Result)
+		case http.MethodDelete:
+			return client.DeleteAuthenticated(ctx, req.Path, req.Body, req.Instruction, req.Result)
+		case http.MethodPatch:
+			return client.PatchAuthenticated(ctx, req.Path, req.Body, req.Instruction, req.Result)
+		}
+	} else {
+		switch req.Method {
+		case http.MethodGet:
+			return client.Get(ctx, req.Path, req.Params, req.Result)
+		case http.MethodPost:
+			return client.Post(ctx, req.Path, req.Body, req.Result)
+		case http.MethodDelete:
+			return client.Delete(ctx, req.Path, req.Body, req.Result)
+		case http.MethodPatch:
+			return client.Patch(ctx, req.Path, req.Body, req.Result)
+		}
+	}
+	return fmt.Errorf("backpack: unsupported request method %q", req.Method)
+}
+
+// isBatchOrder reports whether a request places a batch of orders, which is
+// signed per order rather than as a single body.
+func isBatchOrder(req *Request) bool {
+	return req.Path == batchOrdersPath && req.Instruction == "orderExecute"
+}
+
+func (m *middlewareClient) do(ctx context.Context, req *Request) error {
+	return m.handler(ctx, req)
+}
+
+// Get implements services.HTTPClient.
+func (m *middlewareClient) Get(ctx context.Context, path string, params map[string]string, result any) error {
+	return m.do(ctx, &Request{Method: http.MethodGet, Path: path, Params: params, Result: result})
+}
+
+// GetAuthenticated implements services.HTTPClient.
+func (m *middlewareClient) GetAuthenticated(ctx context.Context, path string, params map[string]string, instruction string, result any) error {
+	return m.do(ctx, &Request{Method: http.MethodGet, Path: path, Instruction: instruction, Params: params, Authenticated: true, Result: result})
+}
+
+// Post implements services.HTTPClient.
+func (m *middlewareClient) Post(ctx context.Context, path string, body any, result any) error {
+	return m.do(ctx, &Request{Method: http.MethodPost, Path: path, Body: body, Result: result})
+}
+
+// PostAuthenticated implements services.HTTPClient.
+func (m *middlewareClient) PostAuthenticated(ctx context.Context, path string, body any, instruction string, result any) error {
+	return m.do(ctx, &Request{Method: http.MethodPost, Path: path, Instruction: instruction, Body: body, Authenticated: true, Result: result})
+}
+
+// Delete implements services.HTTPClient.
+func (m *middlewareClient) Delete(ctx context.Context, path string, body any, result any) error {
+	return m.do(ctx, &Request{Method: http.MethodDelete, Path: path, Body: body, Result: result})
+}
+
+// DeleteAuthenticated implements services.HTTPClient.
+func (m *middlewareClient) DeleteAuthenticated(ctx context.Context, path string, body any, instruction string, result any) error {
+	return m.do(ctx, &Request{Method: http.MethodDelete, Path: path, Instruction: instruction, Body: body, Authenticated: true, Result: result})
+}
+
+// Patch implements services.HTTPClient.
+func (m *middlewareClient) Patch(ctx context.Context, path string, body any, result any) error {
+	return m.do(ctx, &Request{Method: http.MethodPatch, Path: path, Body: body, Result: result})
+}
+
+// PatchAuthenticated implements services.HTTPClient.
+func (m *middlewareClient) PatchAuthenticated(ctx context.Context, path string, body any, instruction string, result any) error {
+	return m.do(ctx, &Request{Method: http.MethodPatch, Path: path, Instruction: instruction, Body: body, Authenticated: true, Result: result})
+}
+
+// PostBatchOrders implements services.HTTPClient.
+func (m *middlewareClient) PostBatchOrders(ctx context.Context, path string, orders []map[string]any, result any) error {
+	return m.do(ctx, &Request{Method: http.MethodPost, Path: path, Instruction: "orderExecute", Body: orders, Authenticated: true, Result: result})
+}
@@ -0,0 +1,537 @@
+// Package oms tracks the orders of an account from a REST snapshot and the
+// private order update stream.
+package oms
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/enums"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/types"
+)
+
+// DefaultMaxClosed is the default number of terminal orders kept.
+const DefaultMaxClosed = 1000
+
+// OrderSource lists open orders. *services.OrdersService implements
+// OrderSource.
+type OrderSource interface {
+	GetOpenOrders(ctx context.Context, params *types.GetOpenOrdersParams) ([]types.Order, error)
+}
+
+// HistorySource looks up past orders. *services.HistoryService implements
+// HistorySource.
+type HistorySource interface {
+	GetOrderHistory(ctx context.Context, params *types.OrderHistoryParams) ([]types.OrderHistoryItem, error)
+}
+
+// OrderUpdateSubscriber subscribes to order updates. *websocket.Handler
+// implements OrderUpdateSubscriber.
+type OrderUpdateSubscriber interface {
+	OnOrderUpdate(symbol string, callback func(*types.WSOrderUpdate)) error
+}
+
+// Manager keeps the state of the account's orders. It is seeded from the
+// open orders returned by REST and then follows the order update stream,
+// applying each update as a transition of the order's state machine:
+// updates that would move an order backwards, such as a late New after a
+// fill, are ignored, and fills are deduplicated by trade ID. After the
+// stream reconnects, Reconcile brings orders changed while it was down up
+// to date. Manager is safe for concurrent use.
+type Manager struct {
+	source    OrderSource
+	history   HistorySource
+	symbol    string
+	maxClosed int
+	onUpdate  func(Order)
+	onError   func(error)
+	now       func() time.Time
+
+	mu      sync.Mutex
+	orders  map[string]*Order
+	closed  []string // IDs of terminal orders, oldest first
+	waiters map[string][]chan Order
+	resync  chan struct{}
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// Option is a functional option for configuring a Manager.
+type Option func(*Manager)
+
+// WithSymbol restricts the manager to the orders of one market.
+func WithSymbol(symbol string) Option {
+	return func(m *Manager) {
+		m.symbol = symbol
+	}
+}
+
+// WithHistory sets the source used to find the final state of orders that
+// closed while the order update stream was down. Without it, such orders
+// are assumed Filled if their last known executed quantity covers their
+// quantity, and Cancelled otherwise.
+func WithHistory(history HistorySource) Option {
+	return func(m *Manager) {
+		m.history = history
+	}
+}
+
+// WithMaxClosed sets how many terminal orders are kept before the oldest
+// are forgotten.
+func WithMaxClosed(n int) Option {
+	return func(m *Manager) {
+		m.maxClosed = n
+	}
+}
+
+// WithOnUpdate sets a callback invoked with a copy of an order after it
+// changes. It is called without the manager's lock held.
+func WithOnUpdate(fn func(Order)) Option {
+	return func(m *Manager) {
+		m.onUpdate = fn
+	}
+}
+
+// WithOnError sets a callback invoked when reconciliation fails.
+func WithOnError(fn func(error)) Option {
+	return func(m *Manager) {
+		m.onError = fn
+	}
+}
+
+// New creates a new Manager listing open orders from source.
+func New(source OrderSource, opts ...Option) *Manager {
+	m := &Manager{
+		source:    source,
+		maxClosed: DefaultMaxClosed,
+		now:       time.Now,
+		orders:    make(map[string]*Order),
+		waiters:   make(map[string][]chan Order),
+		resync:    make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Subscribe feeds the manager from the order update stream. Subscribe
+// before calling Start so no updates are missed while open orders load.
+func (m *Manager) Subscribe(stream OrderUpdateSubscriber) error {
+	return stream.OnOrderUpdate(m.symbol, m.HandleOrderUpdate)
+}
+
+// Start reconciles with REST in the background, first immediately and then
+// whenever HandleReconnect is called, until Stop is called or ctx is done.
+// Failed attempts are retried with backoff and reported to the WithOnError
+// callback.
+func (m *Manager) Start(ctx context.Context) {
+	m.mu.Lock()
+	if m.cancel != nil {
+		m.mu.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	m.cancel = cancel
+	m.done = make(chan struct{})
+	done := m.done
+	m.mu.Unlock()
+
+	m.requestResync()
+	go func() {
+		defer close(done)
+		m.syncLoop(ctx)
+	}()
+}
+
+// Stop stops background reconciliation started by Start.
+func (m *Manager) Stop() {
+	m.mu.Lock()
+	cancel, done := m.cancel, m.done
+	m.cancel, m.done = nil, nil
+	m.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// HandleReconnect schedules a reconciliation. It can be passed directly to
+// websocket.Client.OnReconnect.
+func (m *Manager) HandleReconnect(attempt int) {
+	m.requestResync()
+}
+
+// HandleOrderUpdate applies an order update. It can be passed directly to
+// websocket.Handler.OnOrderUpdate.
+func (m *Manager) HandleOrderUpdate(u *types.WSOrderUpdate) {
+	if u.OrderID == "" || (m.symbol != "" && u.Symbol != m.symbol) {
+		return
+	}
+
+	m.mu.Lock()
+	o, changed := m.applyUpdateLocked(u)
+	m.mu.Unlock()
+
+	if changed {
+		m.notify(o)
+	}
+}
+
+// Track records an order returned by the REST API, such as the result of
+// OrdersService.ExecuteOrder, so it is known before its first update
+// arrives.
+func (m *Manager) Track(order *types.Order) {
+	if m.symbol != "" && order.Symbol != m.symbol {
+		return
+	}
+	m.mu.Lock()
+	o, changed := m.applyOrderLocked(order)
+	m.mu.Unlock()
+
+	if changed {
+		m.notify(o)
+	}
+}
+
+// Reconcile loads the open orders from REST and applies them. Tracked
+// orders that are no longer open have closed while the update stream was
+// down; their final state is looked up with the WithHistory source.
+func (m *Manager) Reconcile(ctx context.Context) error {
+	started := m.now()
+	params := &types.GetOpenOrdersParams{Symbol: m.symbol}
+	open, err := m.source.GetOpenOrders(ctx, params)
+	if err != nil {
+		return fmt.Errorf("oms: failed to get open orders: %w", err)
+	}
+
+	var changed []Order
+	var missing []*Order
+	m.mu.Lock()
+	seen := make(map[string]bool, len(open))
+	for i := range open {
+		if m.symbol != "" && open[i].Symbol != m.symbol {
+			continue
+		}
+		seen[open[i].ID] = true
+		if o, ok := m.applyOrderLocked(&open[i]); ok {
+			changed = append(changed, o)
+		}
+	}
+	for id, o := range m.orders {
+		// Orders changed since the request started may not be in it yet.
+		if !seen[id] && !o.Terminal() && o.UpdatedAt.Before(started) {
+			c := o.clone()
+			missing = append(missing, &c)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, o := range changed {
+		m.notify(o)
+	}
+
+	for _, o := range missing {
+		final, err := m.finalState(ctx, o)
+		if err != nil {
+			return err
+		}
+		m.mu.Lock()
+		c, ok := m.applyOrderLocked(final)
+		m.mu.Unlock()
+		if ok {
+			m.notify(c)
+		}
+	}
+	return nil
+}
+
+// finalState returns the state of an order that is no longer open.
+func (m *Manager) finalState(ctx context.Context, o *Order) (*types.Order, error) {
+	if m.history != nil {
+		history, err := m.history.GetOrderHistory(ctx, &types.OrderHistoryParams{OrderID: o.ID, Symbol: o.Symbol})
+		if err != nil {
+			return nil, fmt.Errorf("oms: failed to get order %s: %w", o.ID, err)
+		}
+		for i := range history {
+			if history[i].ID == o.ID && history[i].Status.Terminal() {
+				return &history[i], nil
+			}
+		}
+	}
+
+	final := o.Order
+	final.Status = enums.OrderStatusCancelled
+	quantity, _ := o.QuantityDecimal()
+	if quantity.IsPositive() && !o.Executed().LessThan(quantity) {
+		final.Status = enums.OrderStatusFilled
+	}
+	return &final, nil
+}
+
+// Get returns a tracked order by ID.
+func (m *Manager) Get(orderID string) (Order, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	o, ok := m.orders[orderID]
+	if !ok {
+		return Order{}, false
+	}
+	return o.clone(), true
+}
+
+// GetByClientID returns the most recent tracked order with a client ID.
+func (m *Manager) GetByClientID(clientID uint32) (Order, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var found *Order
+	for _, o := range m.orders {
+		if o.ClientID == clientID && (found == nil || o.UpdatedAt.After(found.UpdatedAt)) {
+			found = o
+		}
+	}
+	if found == nil {
+		return Order{}, false
+	}
+	return found.clone(), true
+}
+
+// Open returns the tracked orders that have not reached a terminal state.
+func (m *Manager) Open() []Order {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	orders := []Order{}
+	for _, o := range m.orders {
+		if !o.Terminal() {
+			orders = append(orders, o.clone())
+		}
+	}
+	return orders
+}
+
+// Orders returns every tracked order, including recently closed ones.
+func (m *Manager) Orders() []Order {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	orders := make([]Order, 0, len(m.orders))
+	for _, o := range m.orders {
+		orders = append(orders, o.clone())
+	}
+	return orders
+}
+
+// Wait blocks until the order reaches a terminal state and returns it. The
+// order does not need to be tracked yet, so Wait can be called right after
+// submitting it.
+func (m *Manager) Wait(ctx context.Context, orderID string) (Order, error) {
+	m.mu.Lock()
+	if o, ok := m.orders[orderID]; ok && o.Terminal() {
+		c := o.clone()
+		m.mu.Unlock()
+		return c, nil
+	}
+	ch := make(chan Order, 1)
+	m.waiters[orderID] = append(m.waiters[orderID], ch)
+	m.mu.Unlock()
+
+	select {
+	case o := <-ch:
+		return o, nil
+	case <-ctx.Done():
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		waiters := m.waiters[orderID]
+		for i, w := range waiters {
+			if w == ch {
+				m.waiters[orderID] = append(waiters[:i:i], waiters[i+1:]...)
+				break
+			}
+		}
+		if len(m.waiters[orderID]) == 0 {
+			delete(m.waiters, orderID)
+		}
+		return Order{}, ctx.Err()
+	}
+}
+
+// applyUpdateLocked applies an order update and reports whether the order
+// changed.
+func (m *Manager) applyUpdateLocked(u *types.WSOrderUpdate) (Order, bool) {
+	id := string(u.OrderID)
+	o, ok := m.orders[id]
+	if !ok {
+		o = &Order{Order: types.Order{
+			ID:                    id,
+			CreatedAt:             types.TimeString(strconv.FormatInt(u.EventTime/1000, 10)),
+			ExecutedQuantity:      "0",
+			ExecutedQuoteQuantity: "0",
+		}, Fees: make(map[string]types.Decimal)}
+		m.orders[id] = o
+	}
+	changed := !ok
+	wasTerminal := o.Terminal()
+
+	// Fills are recorded even if they arrive after a terminal status set by
+	// reconciliation.
+	if u.TradeID != nil && u.FillQuantity != "" && !o.hasFill(*u.TradeID) {
+		f := Fill{TradeID: *u.TradeID, FeeSymbol: u.FeeSymbol, Time: time.UnixMicro(u.EventTime)}
+		f.Price, _ = types.ParseDecimal(u.FillPrice)
+		f.Quantity, _ = types.ParseDecimal(u.FillQuantity)
+		f.Fee, _ = types.ParseDecimal(orZero(u.Fee))
+		if u.IsMaker != nil {
+			f.IsMaker = *u.IsMaker
+		}
+		o.Fills = append(o.Fills, f)
+		if u.FeeSymbol != "" {
+			o.Fees[u.FeeSymbol] = o.Fees[u.FeeSymbol].Add(f.Fee)
+		}
+		changed = true
+	}
+
+	if !wasTerminal && u.Status.Rank() >= o.Status.Rank() && !isBehind(o.ExecutedQuantity, u.ExecutedQuantity) {
+		o.Symbol = u.Symbol
+		o.Side = u.Side
+		o.Status = u.Status
+		setString(&o.OrderType, enums.OrderType(u.OrderType))
+		setString(&o.TimeInForce, enums.TimeInForce(u.TimeInForce))
+		setString(&o.Price, u.Price)
+		setString(&o.Quantity, u.Quantity)
+		setString(&o.QuoteQuantity, u.QuoteQuantity)
+		setString(&o.TriggerPrice, u.TriggerPrice)
+		setString(&o.TriggerBy, u.TriggerBy)
+		setString(&o.TriggerQuantity, u.TriggerQuantity)
+		setString(&o.ExecutedQuantity, u.ExecutedQuantity)
+		setString(&o.ExecutedQuoteQuantity, u.ExecutedQuoteQuantity)
+		setString(&o.ExpiryReason, u.ExpiryReason)
+		setString(&o.SelfTradePrevention, u.SelfTradePrevention)
+		setString(&o.StrategyID, string(u.StrategyID))
+		if u.ClientID != nil {
+			o.ClientID = *u.ClientID
+		}
+		if u.PostOnly != nil {
+			o.PostOnly = *u.PostOnly
+		}
+		changed = true
+	}
+
+	if !changed {
+		return Order{}, false
+	}
+	o.UpdatedAt = m.now()
+	m.settleLocked(o, wasTerminal)
+	return o.clone(), true
+}
+
+// applyOrderLocked applies the state of an order returned by REST and
+// reports whether the order changed.
+func (m *Manager) applyOrderLocked(order *types.Order) (Order, bool) {
+	o, ok := m.orders[order.ID]
+	if !ok {
+		o = &Order{Fees: make(map[string]types.Decimal)}
+		m.orders[order.ID] = o
+	} else if o.Terminal() || order.Status.Rank() < o.Status.Rank() || isBehind(o.ExecutedQuantity, order.ExecutedQuantity) {
+		return Order{}, false
+	} else if o.Order == *order {
+		return Order{}, false
+	}
+	o.Order = *order
+	o.UpdatedAt = m.now()
+	m.settleLocked(o, false)
+	return o.clone(), true
+}
+
+// settleLocked wakes the waiters of an order that has just become terminal
+// and forgets the oldest terminal orders beyond the limit.
+func (m *Manager) settleLocked(o *Order, wasTerminal bool) {
+	if wasTerminal || !o.Terminal() {
+		return
+	}
+	for _, ch := range m.waiters[o.ID] {
+		ch <- o.clone()
+	}
+	delete(m.waiters, o.ID)
+
+	m.closed = append(m.closed, o.ID)
+	for len(m.closed) > m.maxClosed {
+		delete(m.orders, m.closed[0])
+		m.closed = m.closed[1:]
+	}
+}
+
+func (m *Manager) notify(o Order) {
+	if m.onUpdate != nil {
+		m.onUpdate(o)
+	}
+}
+
+func (m *Manager) syncLoop(ctx context.Context) {
+	backoff := 500 * time.Millisecond
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.resync:
+		}
+
+		for {
+			err := m.Reconcile(ctx)
+			if err == nil {
+				backoff = 500 * time.Millisecond
+				break
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			if m.onError != nil {
+				m.onError(err)
+			}
+
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+			if backoff < 30*time.Second {
+				backoff *= 2
+			}
+		}
+	}
+}
+
+func (m *Manager) requestResync() {
+	select {
+	case m.resync <- struct{}{}:
+	default:
+	}
+}
+
+// isBehind reports whether an executed quantity is lower than the one
+// already known, i.e. the state carrying it is stale.
+func isBehind(known, executed string) bool {
+	if executed == "" {
+		return false
+	}
+	k, err1 := types.ParseDecimal(orZero(known))
+	e, err2 := types.ParseDecimal(executed)
+	return err1 == nil && err2 == nil && e.LessThan(k)
+}
+
+// setString overwrites a field with a non-empty value.
+func setString[T ~string](field *T, value T) {
+	if value != "" {
+		*field = value
+	}
+}
+
+func orZero(s string) string {
+	if s == "" {
+		return "0"
+	}
+	return s
+}
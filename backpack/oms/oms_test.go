@@ -0,0 +1,267 @@
+package oms
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/enums"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/types"
+)
+
+// fakeOrders serves open orders and order history.
+type fakeOrders struct {
+	open    []types.Order
+	history []types.Order
+}
+
+func (f *fakeOrders) GetOpenOrders(ctx context.Context, params *types.GetOpenOrdersParams) ([]types.Order, error) {
+	return f.open, nil
+}
+
+func (f *fakeOrders) GetOrderHistory(ctx context.Context, params *types.OrderHistoryParams) ([]types.OrderHistoryItem, error) {
+	var orders []types.Order
+	for _, o := range f.history {
+		if o.ID == params.OrderID {
+			orders = append(orders, o)
+		}
+	}
+	return orders, nil
+}
+
+// ticking returns a clock that advances a millisecond on every reading.
+func ticking() func() time.Time {
+	now := time.Unix(1700000000, 0)
+	return func() time.Time {
+		now = now.Add(time.Millisecond)
+		return now
+	}
+}
+
+func update(status enums.OrderStatus, executed string) *types.WSOrderUpdate {
+	return &types.WSOrderUpdate{
+		OrderID:          "1",
+		Symbol:           "SOL_USDC",
+		Side:             enums.SideBid,
+		Status:           status,
+		Price:            "100",
+		Quantity:         "2",
+		ExecutedQuantity: executed,
+	}
+}
+
+func fill(status enums.OrderStatus, executed string, tradeID int64) *types.WSOrderUpdate {
+	u := update(status, executed)
+	u.TradeID = &tradeID
+	u.FillQuantity, u.FillPrice, u.Fee, u.FeeSymbol = "1", "100", "0.01", "USDC"
+	return u
+}
+
+func TestHandleOrderUpdate(t *testing.T) {
+	tests := []struct {
+		name     string
+		updates  []*types.WSOrderUpdate
+		status   enums.OrderStatus
+		executed string
+		fills    int
+	}{
+		{
+			name:     "in order",
+			updates:  []*types.WSOrderUpdate{update(enums.OrderStatusNew, "0"), fill(enums.OrderStatusPartiallyFilled, "1", 1), fill(enums.OrderStatusFilled, "2", 2)},
+			status:   enums.OrderStatusFilled,
+			executed: "2",
+			fills:    2,
+		},
+		{
+			name:     "late new after a fill",
+			updates:  []*types.WSOrderUpdate{fill(enums.OrderStatusPartiallyFilled, "1", 1), update(enums.OrderStatusNew, "0")},
+			status:   enums.OrderStatusPartiallyFilled,
+			executed: "1",
+			fills:    1,
+		},
+		{
+			name:     "duplicate fill",
+			updates:  []*types.WSOrderUpdate{fill(enums.OrderStatusPartiallyFilled, "1", 1), fill(enums.OrderStatusPartiallyFilled, "1", 1)},
+			status:   enums.OrderStatusPartiallyFilled,
+			executed: "1",
+			fills:    1,
+		},
+		{
+			name:     "stale executed quantity",
+			updates:  []*types.WSOrderUpdate{fill(enums.OrderStatusPartiallyFilled, "2", 2), fill(enums.OrderStatusPartiallyFilled, "1", 1)},
+			status:   enums.OrderStatusPartiallyFilled,
+			executed: "2",
+			fills:    2,
+		},
+		{
+			name:     "fills out of order",
+			updates:  []*types.WSOrderUpdate{fill(enums.OrderStatusFilled, "2", 2), fill(enums.OrderStatusPartiallyFilled, "1", 1)},
+			status:   enums.OrderStatusFilled,
+			executed: "2",
+			fills:    2,
+		},
+		{
+			name:     "terminal is sticky",
+			updates:  []*types.WSOrderUpdate{update(enums.OrderStatusCancelled, "0"), update(enums.OrderStatusNew, "0")},
+			status:   enums.OrderStatusCancelled,
+			executed: "0",
+		},
+		{
+			name:     "terminal does not move to another terminal",
+			updates:  []*types.WSOrderUpdate{update(enums.OrderStatusCancelled, "0"), update(enums.OrderStatusExpired, "0")},
+			status:   enums.OrderStatusCancelled,
+			executed: "0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New(&fakeOrders{})
+			for _, u := range tt.updates {
+				m.HandleOrderUpdate(u)
+			}
+			o, ok := m.Get("1")
+			if !ok {
+				t.Fatal("order not tracked")
+			}
+			if o.Status != tt.status {
+				t.Errorf("Status = %s, want %s", o.Status, tt.status)
+			}
+			if o.ExecutedQuantity != tt.executed {
+				t.Errorf("ExecutedQuantity = %s, want %s", o.ExecutedQuantity, tt.executed)
+			}
+			if len(o.Fills) != tt.fills {
+				t.Errorf("got %d fills, want %d", len(o.Fills), tt.fills)
+			}
+			if fee := o.Fees["USDC"]; !fee.Equal(types.MustParseDecimal("0.01").Mul(types.NewDecimalFromInt(int64(tt.fills)))) {
+				t.Errorf("USDC fees = %s, want 0.01 per fill", fee)
+			}
+		})
+	}
+}
+
+func TestTrackIgnoresStaleState(t *testing.T) {
+	m := New(&fakeOrders{})
+	m.HandleOrderUpdate(fill(enums.OrderStatusPartiallyFilled, "1", 1))
+
+	// The REST response to the submission arrives after the first fill.
+	m.Track(&types.Order{ID: "1", Symbol: "SOL_USDC", Status: enums.OrderStatusNew, Quantity: "2", ExecutedQuantity: "0"})
+	if o, _ := m.Get("1"); o.Status != enums.OrderStatusPartiallyFilled || o.ExecutedQuantity != "1" {
+		t.Errorf("order = %s with %s executed, want PartiallyFilled with 1", o.Status, o.ExecutedQuantity)
+	}
+
+	m.HandleOrderUpdate(update(enums.OrderStatusCancelled, "1"))
+	m.Track(&types.Order{ID: "1", Symbol: "SOL_USDC", Status: enums.OrderStatusPartiallyFilled, Quantity: "2", ExecutedQuantity: "1"})
+	if o, _ := m.Get("1"); o.Status != enums.OrderStatusCancelled {
+		t.Errorf("Status = %s after a cancel, want Cancelled", o.Status)
+	}
+}
+
+func TestReconcileFinalState(t *testing.T) {
+	orders := &fakeOrders{}
+	tests := []struct {
+		name   string
+		order  types.Order
+		open   bool
+		filled bool // in the history as Filled
+		want   enums.OrderStatus
+	}{
+		{name: "still open", order: types.Order{ID: "1", Quantity: "2", ExecutedQuantity: "0"}, open: true, want: enums.OrderStatusNew},
+		{name: "filled in history", order: types.Order{ID: "2", Quantity: "2", ExecutedQuantity: "0"}, filled: true, want: enums.OrderStatusFilled},
+		{name: "missing and unfilled", order: types.Order{ID: "3", Quantity: "2", ExecutedQuantity: "1"}, want: enums.OrderStatusCancelled},
+		{name: "missing and fully executed", order: types.Order{ID: "4", Quantity: "2", ExecutedQuantity: "2"}, want: enums.OrderStatusFilled},
+	}
+
+	m := New(orders, WithHistory(orders))
+	m.now = ticking()
+	for _, tt := range tests {
+		o := tt.order
+		o.Symbol, o.Status = "SOL_USDC", enums.OrderStatusNew
+		m.Track(&o)
+		if tt.open {
+			orders.open = append(orders.open, o)
+		}
+		if tt.filled {
+			o.Status, o.ExecutedQuantity = enums.OrderStatusFilled, o.Quantity
+			orders.history = append(orders.history, o)
+		}
+	}
+
+	if err := m.Reconcile(context.Background()); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o, ok := m.Get(tt.order.ID)
+			if !ok {
+				t.Fatal("order not tracked")
+			}
+			if o.Status != tt.want {
+				t.Errorf("Status = %s, want %s", o.Status, tt.want)
+			}
+		})
+	}
+	if o, _ := m.Get("2"); o.ExecutedQuantity != "2" {
+		t.Errorf("filled order executed %s, want 2 from the history", o.ExecutedQuantity)
+	}
+}
+
+func TestReconcileKeepsOrdersChangedDuringRequest(t *testing.T) {
+	m := New(&fakeOrders{})
+	m.now = ticking()
+	started := m.now()
+	m.HandleOrderUpdate(update(enums.OrderStatusNew, "0"))
+	// The order was placed after the open orders were listed.
+	m.now = func() time.Time { return started }
+
+	if err := m.Reconcile(context.Background()); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if o, _ := m.Get("1"); o.Status != enums.OrderStatusNew {
+		t.Errorf("Status = %s, want New", o.Status)
+	}
+}
+
+func TestWait(t *testing.T) {
+	m := New(&fakeOrders{})
+	m.HandleOrderUpdate(update(enums.OrderStatusNew, "0"))
+
+	done := make(chan Order, 1)
+	go func() {
+		o, err := m.Wait(context.Background(), "1")
+		if err != nil {
+			t.Errorf("Wait: %v", err)
+		}
+		done <- o
+	}()
+	m.HandleOrderUpdate(fill(enums.OrderStatusFilled, "2", 1))
+
+	select {
+	case o := <-done:
+		if o.Status != enums.OrderStatusFilled {
+			t.Errorf("Wait returned %s, want Filled", o.Status)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Wait did not return after the order settled")
+	}
+
+	// A settled order is returned at once.
+	if o, err := m.Wait(context.Background(), "1"); err != nil || o.Status != enums.OrderStatusFilled {
+		t.Errorf("Wait = %s, %v, want Filled", o.Status, err)
+	}
+}
+
+func TestWaitCancelled(t *testing.T) {
+	m := New(&fakeOrders{})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := m.Wait(ctx, "1"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait error = %v, want context.DeadlineExceeded", err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.waiters) != 0 {
+		t.Errorf("%d waiters left after cancellation, want 0", len(m.waiters))
+	}
+}
@@ -0,0 +1,74 @@
+package oms
+
+import (
+	"time"
+
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/types"
+)
+
+// Fill is an execution of a tracked order.
+type Fill struct {
+	TradeID   int64
+	Price     types.Decimal
+	Quantity  types.Decimal
+	Fee       types.Decimal
+	FeeSymbol string
+	IsMaker   bool
+	Time      time.Time
+}
+
+// Order is the tracked state of an order. The embedded types.Order holds
+// the latest state reported by the exchange.
+type Order struct {
+	types.Order
+
+	// Fills are the executions received on the order update stream, oldest
+	// first. Fills that happened while the stream was down are reflected in
+	// the executed quantities but not listed.
+	Fills []Fill
+	// Fees are the fees paid on Fills, by fee asset.
+	Fees map[string]types.Decimal
+	// UpdatedAt is when the order was last changed locally.
+	UpdatedAt time.Time
+}
+
+// Executed returns the executed base quantity.
+func (o *Order) Executed() types.Decimal {
+	d, _ := o.ExecutedQuantityDecimal()
+	return d
+}
+
+// AveragePrice returns the average fill price, or zero if nothing has been
+// executed.
+func (o *Order) AveragePrice() types.Decimal {
+	executed := o.Executed()
+	if executed.IsZero() {
+		return types.Decimal{}
+	}
+	quote, _ := o.ExecutedQuoteQuantityDecimal()
+	return quote.Div(executed, 8)
+}
+
+// Terminal reports whether the order has reached a final state.
+func (o *Order) Terminal() bool {
+	return o.Status.Terminal()
+}
+
+func (o *Order) clone() Order {
+	c := *o
+	c.Fills = append([]Fill(nil), o.Fills...)
+	c.Fees = make(map[string]types.Decimal, len(o.Fees))
+	for asset, fee := range o.Fees {
+		c.Fees[asset] = fee
+	}
+	return c
+}
+
+func (o *Order) hasFill(tradeID int64) bool {
+	for _, f := range o.Fills {
+		if f.TradeID == tradeID {
+			return true
+		}
+	}
+	return false
+}
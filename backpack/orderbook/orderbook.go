@@ -0,0 +1,516 @@
+// Package orderbook maintains a local order book from a REST depth snapshot and WebSocket depth diffs.
+package orderbook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/enums"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/services"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/types"
+)
+
+const (
+	DefaultGapTimeout = 5 * time.Second
+	DefaultMaxBuffer  = 1000
+)
+
+// ErrGap is reported when a diff is missing from the depth stream and the
+// book has to be resynchronized.
+var ErrGap = errors.New("orderbook: gap in depth updates")
+
+// SnapshotSource fetches depth snapshots. *services.MarketsService implements SnapshotSource.
+type SnapshotSource interface {
+	GetDepth(ctx context.Context, params services.GetDepthParams) (*types.OrderBook, error)
+}
+
+// DepthSubscriber subscribes to depth diffs. *websocket.Handler implements DepthSubscriber.
+type DepthSubscriber interface {
+	OnDepth(symbol string, callback func(*types.WSDepth)) error
+}
+
+// Snapshot is a consistent, read-only copy of the book. Levels are ordered
+// best first.
+type Snapshot struct {
+	Symbol       string
+	LastUpdateID int64
+	Bids         []types.PriceLevel
+	Asks         []types.PriceLevel
+}
+
+// Book is a local order book kept in sync with the exchange. Diffs received
+// before the snapshot, or out of order, are buffered and applied in
+// sequence; a gap that is not filled in time triggers a new snapshot. Book
+// is safe for concurrent use.
+type Book struct {
+	symbol     string
+	source     SnapshotSource
+	limit      enums.DepthLimit
+	gapTimeout time.Duration
+	maxBuffer  int
+	onUpdate   func(*Book)
+	onResync   func(error)
+
+	mu       sync.RWMutex
+	bids     []types.PriceLevel
+	asks     []types.PriceLevel
+	lastID   int64
+	synced   bool
+	pending  []*types.WSDepth
+	gapSince time.Time
+	resync   chan struct{}
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// Option is a functional option for configuring a Book.
+type Option func(*Book)
+
+// WithDepthLimit sets the depth of the REST snapshot.
+func WithDepthLimit(limit enums.DepthLimit) Option {
+	return func(b *Book) {
+		b.limit = limit
+	}
+}
+
+// WithGapTimeout sets how long an out-of-order diff may wait for the
+// missing updates before the book is resynchronized.
+func WithGapTimeout(timeout time.Duration) Option {
+	return func(b *Book) {
+		b.gapTimeout = timeout
+	}
+}
+
+// WithMaxBuffer sets how many diffs may be buffered while waiting for a
+// snapshot or a missing update before the book is resynchronized.
+func WithMaxBuffer(n int) Option {
+	return func(b *Book) {
+		b.maxBuffer = n
+	}
+}
+
+// WithOnUpdate sets a callback invoked after the book changes. It is called
+// without the book's lock held, so it may read the book.
+func WithOnUpdate(fn func(*Book)) Option {
+	return func(b *Book) {
+		b.onUpdate = fn
+	}
+}
+
+// WithOnResync sets a callback invoked when the book starts resynchronizing,
+// with the reason, and when a snapshot fetch fails.
+func WithOnResync(fn func(error)) Option {
+	return func(b *Book) {
+		b.onResync = fn
+	}
+}
+
+// New creates a new Book for the given symbol.
+func New(symbol string, source SnapshotSource, opts ...Option) *Book {
+	b := &Book{
+		symbol:     symbol,
+		source:     source,
+		gapTimeout: DefaultGapTimeout,
+		maxBuffer:  DefaultMaxBuffer,
+		resync:     make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// Subscribe feeds the book from the depth stream of its symbol. Subscribe
+// before calling Start so no diffs are missed while the snapshot loads.
+func (b *Book) Subscribe(stream DepthSubscriber) error {
+	return stream.OnDepth(b.symbol, b.HandleDepth)
+}
+
+// Start loads the snapshot and keeps the book synchronized in the
+// background until Stop is called or ctx is done.
+func (b *Book) Start(ctx context.Context) {
+	b.mu.Lock()
+	if b.cancel != nil {
+		b.mu.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	b.cancel = cancel
+	b.done = make(chan struct{})
+	done := b.done
+	b.mu.Unlock()
+
+	b.requestResync()
+	go func() {
+		defer close(done)
+		b.syncLoop(ctx)
+	}()
+}
+
+// Stop stops background synchronization started by Start.
+func (b *Book) Stop() {
+	b.mu.Lock()
+	cancel, done := b.cancel, b.done
+	b.cancel, b.done = nil, nil
+	b.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// HandleDepth applies a depth diff. It can be passed directly to
+// websocket.Handler.OnDepth.
+func (b *Book) HandleDepth(diff *types.WSDepth) {
+	if diff.Symbol != "" && diff.Symbol != b.symbol {
+		return
+	}
+
+	b.mu.Lock()
+	b.insertPendingLocked(diff)
+	changed := false
+	var err error
+	if b.synced {
+		changed, err = b.drainLocked()
+	}
+	if err == nil && len(b.pending) > b.maxBuffer {
+		if b.synced {
+			err = fmt.Errorf("%w: %d diffs buffered", ErrGap, len(b.pending))
+			b.pending = nil
+		} else {
+			// Still waiting for the snapshot; the oldest diffs are the
+			// most likely to be covered by it.
+			b.pending = b.pending[len(b.pending)-b.maxBuffer:]
+		}
+	}
+	if err != nil {
+		b.synced = false
+	}
+	b.mu.Unlock()
+
+	if err != nil {
+		b.notifyResync(err)
+		b.requestResync()
+	}
+	if changed && b.onUpdate != nil {
+		b.onUpdate(b)
+	}
+}
+
+// Symbol returns the symbol of the book.
+func (b *Book) Symbol() string {
+	return b.symbol
+}
+
+// Synced reports whether the book reflects a snapshot and all diffs since.
+func (b *Book) Synced() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.synced
+}
+
+// LastUpdateID returns the ID of the last update applied to the book.
+func (b *Book) LastUpdateID() int64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.lastID
+}
+
+// BestBid returns the highest bid, or false if there are no bids.
+func (b *Book) BestBid() (types.PriceLevel, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if len(b.bids) == 0 {
+		return types.PriceLevel{}, false
+	}
+	return b.bids[0], true
+}
+
+// BestAsk returns the lowest ask, or false if there are no asks.
+func (b *Book) BestAsk() (types.PriceLevel, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if len(b.asks) == 0 {
+		return types.PriceLevel{}, false
+	}
+	return b.asks[0], true
+}
+
+// DepthAt returns the quantity resting at exactly the given price, or zero.
+func (b *Book) DepthAt(side enums.Side, price types.Decimal) types.Decimal {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	levels := b.levelsLocked(side)
+	i := search(side, levels, price)
+	if i < len(levels) && levels[i].Price.Equal(price) {
+		return levels[i].Quantity
+	}
+	return types.Decimal{}
+}
+
+// CumulativeSize returns the total quantity on the given side from the best
+// level up to and including price.
+func (b *Book) CumulativeSize(side enums.Side, price types.Decimal) types.Decimal {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	var total types.Decimal
+	for _, level := range b.levelsLocked(side) {
+		if better(side, price, level.Price) {
+			break
+		}
+		total = total.Add(level.Quantity)
+	}
+	return total
+}
+
+// Snapshot returns a copy of the book.
+func (b *Book) Snapshot() *Snapshot {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return &Snapshot{
+		Symbol:       b.symbol,
+		LastUpdateID: b.lastID,
+		Bids:         append([]types.PriceLevel(nil), b.bids...),
+		Asks:         append([]types.PriceLevel(nil), b.asks...),
+	}
+}
+
+func (b *Book) syncLoop(ctx context.Context) {
+	// Diffs only drain when a new one arrives, so a quiet stream would
+	// never notice a gap; check for one periodically as well.
+	gapTicker := time.NewTicker(max(b.gapTimeout/4, 10*time.Millisecond))
+	defer gapTicker.Stop()
+
+	backoff := 500 * time.Millisecond
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-gapTicker.C:
+			b.checkGap()
+			continue
+		case <-b.resync:
+		}
+
+		for {
+			err := b.loadSnapshot(ctx)
+			if err == nil {
+				backoff = 500 * time.Millisecond
+				break
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			b.notifyResync(err)
+
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+			if backoff < 30*time.Second {
+				backoff *= 2
+			}
+		}
+	}
+}
+
+func (b *Book) loadSnapshot(ctx context.Context) error {
+	book, err := b.source.GetDepth(ctx, services.GetDepthParams{Symbol: b.symbol, Limit: b.limit})
+	if err != nil {
+		return fmt.Errorf("orderbook: failed to get snapshot: %w", err)
+	}
+	lastID, err := strconv.ParseInt(book.LastUpdateID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("orderbook: invalid snapshot update ID %q: %w", book.LastUpdateID, err)
+	}
+	bids, err := book.BidLevels()
+	if err != nil {
+		return fmt.Errorf("orderbook: invalid snapshot: %w", err)
+	}
+	asks, err := book.AskLevels()
+	if err != nil {
+		return fmt.Errorf("orderbook: invalid snapshot: %w", err)
+	}
+	sortLevels(enums.SideBid, bids)
+	sortLevels(enums.SideAsk, asks)
+
+	b.mu.Lock()
+	b.bids, b.asks = bids, asks
+	b.lastID = lastID
+	b.synced = true
+	b.gapSince = time.Time{}
+	_, err = b.drainLocked()
+	if err != nil {
+		b.synced = false
+	}
+	b.mu.Unlock()
+
+	if err != nil {
+		// Buffered diffs do not connect to the snapshot; try again.
+		b.notifyResync(err)
+		b.requestResync()
+		return nil
+	}
+	if b.onUpdate != nil {
+		b.onUpdate(b)
+	}
+	return nil
+}
+
+// checkGap resynchronizes the book if buffered diffs have been waiting for a
+// missing update for longer than the gap timeout.
+func (b *Book) checkGap() {
+	b.mu.Lock()
+	var err error
+	if b.synced && len(b.pending) > 0 && !b.gapSince.IsZero() && time.Since(b.gapSince) >= b.gapTimeout {
+		err = fmt.Errorf("%w: expected update %d, got %d", ErrGap, b.lastID+1, b.pending[0].FirstUpdateID)
+		b.pending = nil
+		b.gapSince = time.Time{}
+		b.synced = false
+	}
+	b.mu.Unlock()
+
+	if err != nil {
+		b.notifyResync(err)
+		b.requestResync()
+	}
+}
+
+// insertPendingLocked buffers a diff, keeping the buffer ordered by first update ID.
+func (b *Book) insertPendingLocked(diff *types.WSDepth) {
+	i := sort.Search(len(b.pending), func(i int) bool {
+		return b.pending[i].FirstUpdateID > diff.FirstUpdateID
+	})
+	b.pending = append(b.pending, nil)
+	copy(b.pending[i+1:], b.pending[i:])
+	b.pending[i] = diff
+}
+
+// drainLocked applies buffered diffs that continue the book. It reports
+// whether the book changed, and returns ErrGap if the next diff has been
+// missing for longer than the gap timeout.
+func (b *Book) drainLocked() (bool, error) {
+	changed := false
+	for len(b.pending) > 0 {
+		diff := b.pending[0]
+		if diff.LastUpdateID <= b.lastID {
+			// Already reflected in the book.
+			b.pending = b.pending[1:]
+			continue
+		}
+		if diff.FirstUpdateID > b.lastID+1 {
+			break
+		}
+		if err := b.applyLocked(diff); err != nil {
+			b.pending = nil
+			return changed, err
+		}
+		b.pending = b.pending[1:]
+		changed = true
+	}
+
+	if len(b.pending) == 0 {
+		b.pending = nil
+		b.gapSince = time.Time{}
+		return changed, nil
+	}
+	now := time.Now()
+	if b.gapSince.IsZero() || changed {
+		b.gapSince = now
+	}
+	if now.Sub(b.gapSince) >= b.gapTimeout {
+		err := fmt.Errorf("%w: expected update %d, got %d", ErrGap, b.lastID+1, b.pending[0].FirstUpdateID)
+		b.pending = nil
+		b.gapSince = time.Time{}
+		return changed, err
+	}
+	return changed, nil
+}
+
+func (b *Book) applyLocked(diff *types.WSDepth) error {
+	bids, err := diff.BidLevels()
+	if err != nil {
+		return fmt.Errorf("orderbook: invalid diff %d: %w", diff.LastUpdateID, err)
+	}
+	asks, err := diff.AskLevels()
+	if err != nil {
+		return fmt.Errorf("orderbook: invalid diff %d: %w", diff.LastUpdateID, err)
+	}
+	for _, level := range bids {
+		b.bids = update(enums.SideBid, b.bids, level)
+	}
+	for _, level := range asks {
+		b.asks = update(enums.SideAsk, b.asks, level)
+	}
+	b.lastID = diff.LastUpdateID
+	return nil
+}
+
+func (b *Book) levelsLocked(side enums.Side) []types.PriceLevel {
+	if side == enums.SideAsk {
+		return b.asks
+	}
+	return b.bids
+}
+
+func (b *Book) requestResync() {
+	select {
+	case b.resync <- struct{}{}:
+	default:
+	}
+}
+
+func (b *Book) notifyResync(err error) {
+	if b.onResync != nil {
+		b.onResync(err)
+	}
+}
+
+// better reports whether price a ranks ahead of price b on the given side.
+func better(side enums.Side, a, b types.Decimal) bool {
+	if side == enums.SideAsk {
+		return a.LessThan(b)
+	}
+	return a.GreaterThan(b)
+}
+
+// search returns the index of the first level that does not rank ahead of price.
+func search(side enums.Side, levels []types.PriceLevel, price types.Decimal) int {
+	return sort.Search(len(levels), func(i int) bool {
+		return !better(side, levels[i].Price, price)
+	})
+}
+
+// update sets, inserts or removes (zero quantity) a level.
+func update(side enums.Side, levels []types.PriceLevel, level types.PriceLevel) []types.PriceLevel {
+	i := search(side, levels, level.Price)
+	found := i < len(levels) && levels[i].Price.Equal(level.Price)
+	switch {
+	case level.Quantity.IsZero():
+		if found {
+			levels = append(levels[:i], levels[i+1:]...)
+		}
+	case found:
+		levels[i] = level
+	default:
+		levels = append(levels, types.PriceLevel{})
+		copy(levels[i+1:], levels[i:])
+		levels[i] = level
+	}
+	return levels
+}
+
+func sortLevels(side enums.Side, levels []types.PriceLevel) {
+	sort.Slice(levels, func(i, j int) bool {
+		return better(side, levels[i].Price, levels[j].Price)
+	})
+}
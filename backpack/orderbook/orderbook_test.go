@@ -0,0 +1,124 @@
+package orderbook
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/enums"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/services"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/types"
+)
+
+// fakeSource serves snapshots from a queue, repeating the last one.
+type fakeSource struct {
+	mu        sync.Mutex
+	snapshots []*types.OrderBook
+	calls     int
+}
+
+func (s *fakeSource) GetDepth(ctx context.Context, params services.GetDepthParams) (*types.OrderBook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	book := s.snapshots[0]
+	if len(s.snapshots) > 1 {
+		s.snapshots = s.snapshots[1:]
+	}
+	return book, nil
+}
+
+func (s *fakeSource) Calls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+func snapshot(lastID int64, bids, asks [][]string) *types.OrderBook {
+	return &types.OrderBook{LastUpdateID: strconv.FormatInt(lastID, 10), Bids: bids, Asks: asks}
+}
+
+func diff(first, last int64, bids, asks [][]string) *types.WSDepth {
+	return &types.WSDepth{Symbol: "BTC_USDC", FirstUpdateID: first, LastUpdateID: last, Bids: bids, Asks: asks}
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestBookBuffersDiffsUntilSnapshot(t *testing.T) {
+	source := &fakeSource{snapshots: []*types.OrderBook{
+		snapshot(10, [][]string{{"100", "1"}, {"99", "2"}}, [][]string{{"101", "1"}}),
+	}}
+	book := New("BTC_USDC", source)
+
+	// 5..10 is covered by the snapshot, 11..12 continues it.
+	book.HandleDepth(diff(11, 12, [][]string{{"100", "0"}, {"99.5", "3"}}, nil))
+	book.HandleDepth(diff(5, 10, [][]string{{"98", "5"}}, nil))
+	book.HandleDepth(diff(13, 13, nil, [][]string{{"100.5", "4"}}))
+
+	book.Start(context.Background())
+	defer book.Stop()
+	waitFor(t, "sync", book.Synced)
+
+	if id := book.LastUpdateID(); id != 13 {
+		t.Errorf("LastUpdateID() = %d, want 13", id)
+	}
+	bid, _ := book.BestBid()
+	ask, _ := book.BestAsk()
+	if bid.Price.String() != "99.5" || ask.Price.String() != "100.5" {
+		t.Errorf("best bid %s, best ask %s; want 99.5, 100.5", bid.Price, ask.Price)
+	}
+	if got := book.DepthAt(enums.SideBid, types.MustParseDecimal("98")); !got.IsZero() {
+		t.Errorf("diff covered by the snapshot was applied: depth at 98 = %s", got)
+	}
+	if got := book.CumulativeSize(enums.SideBid, types.MustParseDecimal("99")).String(); got != "5" {
+		t.Errorf("CumulativeSize(bid, 99) = %s, want 5", got)
+	}
+}
+
+func TestBookResyncsOnQuietGap(t *testing.T) {
+	source := &fakeSource{snapshots: []*types.OrderBook{
+		snapshot(10, [][]string{{"100", "1"}}, nil),
+		snapshot(20, [][]string{{"101", "1"}}, nil),
+	}}
+	var (
+		mu      sync.Mutex
+		reasons []error
+	)
+	book := New("BTC_USDC", source,
+		WithGapTimeout(50*time.Millisecond),
+		WithOnResync(func(err error) {
+			mu.Lock()
+			reasons = append(reasons, err)
+			mu.Unlock()
+		}),
+	)
+	book.Start(context.Background())
+	defer book.Stop()
+	waitFor(t, "sync", book.Synced)
+
+	// Update 11 never arrives and no further diffs follow: the gap must be
+	// noticed without another diff to drain.
+	book.HandleDepth(diff(12, 12, [][]string{{"100", "2"}}, nil))
+	waitFor(t, "resync", func() bool { return source.Calls() == 2 && book.Synced() })
+
+	if id := book.LastUpdateID(); id != 20 {
+		t.Errorf("LastUpdateID() = %d, want 20", id)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reasons) != 1 || !errors.Is(reasons[0], ErrGap) {
+		t.Errorf("resync reasons = %v, want one ErrGap", reasons)
+	}
+}
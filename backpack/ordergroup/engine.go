@@ -0,0 +1,867 @@
+// Package ordergroup emulates one-cancels-other and bracket orders on the
+// client by linking independent orders and following the private order
+// update stream.
+package ordergroup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/clientid"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/enums"
+	bperrors "github.com/solomeowl/backpack-exchange-sdk-go/backpack/errors"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/internal/atomicfile"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/types"
+)
+
+// DefaultMaxClosed is the default number of terminal groups kept.
+const DefaultMaxClosed = 1000
+
+// maxSteps bounds the actions taken on a group in one evaluation.
+const maxSteps = 16
+
+// ErrUnknownGroup is returned for a group ID the engine does not know.
+var ErrUnknownGroup = errors.New("ordergroup: unknown group")
+
+// OrderClient places, cancels and looks up orders. *services.OrdersService
+// implements OrderClient.
+type OrderClient interface {
+	ExecuteOrder(ctx context.Context, params types.ExecuteOrderParams) (*types.Order, error)
+	CancelOrder(ctx context.Context, params types.CancelOrderParams) (*types.Order, error)
+	GetOrder(ctx context.Context, params types.GetOrderParams) (*types.Order, error)
+}
+
+// HistorySource lists past orders. *services.HistoryService implements
+// HistorySource.
+type HistorySource interface {
+	GetOrderHistory(ctx context.Context, params *types.OrderHistoryParams) ([]types.OrderHistoryItem, error)
+}
+
+// OrderUpdateSubscriber subscribes to order updates. *websocket.Handler
+// implements OrderUpdateSubscriber.
+type OrderUpdateSubscriber interface {
+	OnOrderUpdate(symbol string, callback func(*types.WSOrderUpdate)) error
+}
+
+// Engine places groups of linked orders and keeps them consistent as they
+// execute. When a leg of a group executes, the other legs are resized to
+// the quantity left, cancelling and placing them again with a new client
+// ID, and once the group's quantity has been executed they are cancelled. A
+// bracket places its exit legs when its entry executes and grows them as it
+// keeps executing.
+//
+// Order updates are applied by a background worker started with Start, so
+// the stream is never blocked on REST calls. Since the exchange does not
+// link the orders, two legs executing at the same time can both fill before
+// the engine reacts.
+//
+// With WithStateFile, the groups are persisted before each order is placed,
+// and Recover resumes them after a restart. Engine is safe for concurrent
+// use.
+type Engine struct {
+	orders    OrderClient
+	history   HistorySource
+	ids       *clientid.Allocator
+	submitter *clientid.Submitter
+	path      string
+	maxClosed int
+	onUpdate  func(Group)
+	onError   func(error)
+
+	// mu guards the groups and is held while acting on them, so actions
+	// are serialized.
+	mu       sync.Mutex
+	groups   map[string]*Group
+	byClient map[uint32]*Group
+	closed   []string // IDs of terminal groups, oldest first
+	changed  []Group
+
+	queueMu sync.Mutex
+	queue   []types.WSOrderUpdate
+	wake    chan struct{}
+	resync  chan struct{}
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// Option is a functional option for configuring an Engine.
+type Option func(*Engine)
+
+// WithHistory sets the source used to find the final state of orders that
+// closed while the engine was not following them. Without it, such orders
+// are assumed cancelled without further fills. With WithSubmitter, the
+// submitter's own history source is used instead.
+func WithHistory(history HistorySource) Option {
+	return func(e *Engine) {
+		e.history = history
+	}
+}
+
+// WithSubmitter sets the submitter placing the orders of the groups and
+// looking them up by client ID. By default, one is created from the order
+// client, the allocator and the WithHistory source.
+func WithSubmitter(s *clientid.Submitter) Option {
+	return func(e *Engine) {
+		e.submitter = s
+	}
+}
+
+// WithStateFile persists the active groups in the file at path.
+func WithStateFile(path string) Option {
+	return func(e *Engine) {
+		e.path = path
+	}
+}
+
+// WithMaxClosed sets how many terminal groups are kept before the oldest
+// are forgotten.
+func WithMaxClosed(n int) Option {
+	return func(e *Engine) {
+		e.maxClosed = n
+	}
+}
+
+// WithOnUpdate sets a callback invoked with a copy of a group after it
+// changes. It is called without the engine's lock held.
+func WithOnUpdate(fn func(Group)) Option {
+	return func(e *Engine) {
+		e.onUpdate = fn
+	}
+}
+
+// WithOnError sets a callback invoked when acting on a group in the
+// background fails. Failed actions are retried with backoff.
+func WithOnError(fn func(error)) Option {
+	return func(e *Engine) {
+		e.onError = fn
+	}
+}
+
+// New creates a new Engine placing orders with orders and client IDs from
+// ids.
+func New(orders OrderClient, ids *clientid.Allocator, opts ...Option) *Engine {
+	e := &Engine{
+		orders:    orders,
+		ids:       ids,
+		maxClosed: DefaultMaxClosed,
+		groups:    make(map[string]*Group),
+		byClient:  make(map[uint32]*Group),
+		wake:      make(chan struct{}, 1),
+		resync:    make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if e.submitter == nil {
+		var submitOpts []clientid.Option
+		if e.history != nil {
+			submitOpts = append(submitOpts, clientid.WithHistory(e.history))
+		}
+		e.submitter = clientid.NewSubmitter(orders, ids, submitOpts...)
+	}
+	return e
+}
+
+// Subscribe feeds the engine from the order update stream.
+func (e *Engine) Subscribe(stream OrderUpdateSubscriber) error {
+	return stream.OnOrderUpdate("", e.HandleOrderUpdate)
+}
+
+// Start applies order updates in the background, and reconciles the groups
+// with REST whenever HandleReconnect is called, until Stop is called or ctx
+// is done. Failed actions are retried with backoff and reported to the
+// WithOnError callback.
+func (e *Engine) Start(ctx context.Context) {
+	e.queueMu.Lock()
+	if e.cancel != nil {
+		e.queueMu.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	e.cancel = cancel
+	e.done = make(chan struct{})
+	done := e.done
+	e.queueMu.Unlock()
+
+	signal(e.wake)
+	go func() {
+		defer close(done)
+		e.loop(ctx)
+	}()
+}
+
+// Stop stops the background worker started by Start.
+func (e *Engine) Stop() {
+	e.queueMu.Lock()
+	cancel, done := e.cancel, e.done
+	e.cancel, e.done = nil, nil
+	e.queueMu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// HandleReconnect schedules a reconciliation. It can be passed directly to
+// websocket.Client.OnReconnect.
+func (e *Engine) HandleReconnect(attempt int) {
+	signal(e.resync)
+}
+
+// HandleOrderUpdate queues an order update for the worker. It can be passed
+// directly to websocket.Handler.OnOrderUpdate. Updates of orders without a
+// client ID are ignored, since every order of a group has one.
+func (e *Engine) HandleOrderUpdate(u *types.WSOrderUpdate) {
+	if u.ClientID == nil {
+		return
+	}
+	e.queueMu.Lock()
+	e.queue = append(e.queue, *u)
+	e.queueMu.Unlock()
+	signal(e.wake)
+}
+
+// PlaceOCO places linked orders on one market, all with the same quantity,
+// such as a take-profit limit order and a stop-loss trigger order closing
+// the same position. Client IDs are allocated for the legs. If a leg cannot
+// be placed, the legs already placed are cancelled and the error is
+// returned with the cancelled group.
+func (e *Engine) PlaceOCO(ctx context.Context, legs ...types.ExecuteOrderParams) (Group, error) {
+	if len(legs) < 2 {
+		return Group{}, errors.New("ordergroup: an OCO needs at least two legs")
+	}
+	quantity, err := types.ParseDecimal(legs[0].Quantity)
+	if err != nil || !quantity.IsPositive() {
+		return Group{}, fmt.Errorf("ordergroup: invalid leg quantity %q", legs[0].Quantity)
+	}
+	for _, p := range legs[1:] {
+		if p.Symbol != legs[0].Symbol {
+			return Group{}, errors.New("ordergroup: legs must be on the same market")
+		}
+		if q, err := types.ParseDecimal(p.Quantity); err != nil || !q.Equal(quantity) {
+			return Group{}, errors.New("ordergroup: legs must have the same quantity")
+		}
+	}
+
+	g := &Group{Kind: KindOCO, Symbol: legs[0].Symbol, State: StateActive, Quantity: quantity}
+	for _, p := range legs {
+		l, err := e.newLeg(p)
+		if err != nil {
+			return Group{}, err
+		}
+		g.Legs = append(g.Legs, l)
+	}
+	g.ID = strconv.FormatUint(uint64(g.Legs[0].ClientID), 10)
+
+	e.mu.Lock()
+	e.addLocked(g)
+	err = e.openLocked(ctx, g, g.Legs)
+	c := g.clone()
+	e.mu.Unlock()
+	e.flush()
+	return c, err
+}
+
+// PlaceBracket places an entry order and links exit orders to it. The exit
+// legs are placed once the entry executes, for the quantity executed, so
+// their own quantities are ignored; they are resized as the entry keeps
+// executing, and the rest of the entry is cancelled once an exit executes.
+// If the entry cannot be placed, the error is returned with the cancelled
+// group.
+func (e *Engine) PlaceBracket(ctx context.Context, entry types.ExecuteOrderParams, exits ...types.ExecuteOrderParams) (Group, error) {
+	if len(exits) == 0 {
+		return Group{}, errors.New("ordergroup: a bracket needs at least one exit leg")
+	}
+	for _, p := range exits {
+		if p.Symbol != entry.Symbol {
+			return Group{}, errors.New("ordergroup: legs must be on the same market")
+		}
+	}
+
+	g := &Group{Kind: KindBracket, Symbol: entry.Symbol, State: StatePending}
+	var err error
+	if g.Entry, err = e.newLeg(entry); err != nil {
+		return Group{}, err
+	}
+	for _, p := range exits {
+		p.Quantity = ""
+		l, err := e.newLeg(p)
+		if err != nil {
+			return Group{}, err
+		}
+		g.Legs = append(g.Legs, l)
+	}
+	g.ID = strconv.FormatUint(uint64(g.Entry.ClientID), 10)
+
+	e.mu.Lock()
+	e.addLocked(g)
+	err = e.openLocked(ctx, g, []*Leg{g.Entry})
+	c := g.clone()
+	e.mu.Unlock()
+	e.flush()
+	return c, err
+}
+
+// Cancel cancels the open orders of a group and ends it.
+func (e *Engine) Cancel(ctx context.Context, id string) (Group, error) {
+	e.mu.Lock()
+	g, ok := e.groups[id]
+	if !ok {
+		e.mu.Unlock()
+		return Group{}, ErrUnknownGroup
+	}
+	var err error
+	if !g.Terminal() {
+		err = e.closeLocked(ctx, g, StateCancelled)
+	}
+	c := g.clone()
+	e.mu.Unlock()
+	e.flush()
+	return c, err
+}
+
+// Recover loads the groups persisted with WithStateFile and reconciles them
+// with REST, catching up with what happened while the process was down.
+// Call it after Subscribe and before Start.
+func (e *Engine) Recover(ctx context.Context) error {
+	if e.path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(e.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("ordergroup: failed to read %s: %w", e.path, err)
+	}
+	var s state
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("ordergroup: failed to decode %s: %w", e.path, err)
+	}
+
+	e.mu.Lock()
+	for _, g := range s.Groups {
+		if _, ok := e.groups[g.ID]; !ok && !g.Terminal() {
+			e.addLocked(g)
+		}
+	}
+	e.mu.Unlock()
+	return e.Reconcile(ctx)
+}
+
+// Reconcile refreshes the orders of the active groups from REST and acts on
+// what changed.
+func (e *Engine) Reconcile(ctx context.Context) error {
+	e.mu.Lock()
+	var errs []error
+	for _, g := range e.groups {
+		if g.Terminal() {
+			continue
+		}
+		if err := e.refreshLocked(ctx, g); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := e.evaluateLocked(ctx, g); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	e.mu.Unlock()
+	e.flush()
+	return errors.Join(errs...)
+}
+
+// Group returns a group by ID.
+func (e *Engine) Group(id string) (Group, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	g, ok := e.groups[id]
+	if !ok {
+		return Group{}, false
+	}
+	return g.clone(), true
+}
+
+// Groups returns every group, including recently ended ones.
+func (e *Engine) Groups() []Group {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	groups := make([]Group, 0, len(e.groups))
+	for _, g := range e.groups {
+		groups = append(groups, g.clone())
+	}
+	return groups
+}
+
+// newLeg creates a leg with a client ID, allocating one unless params has
+// one.
+func (e *Engine) newLeg(params types.ExecuteOrderParams) (*Leg, error) {
+	var id uint32
+	if params.ClientID != nil {
+		id = *params.ClientID
+	} else {
+		var err error
+		if id, err = e.ids.Next(); err != nil {
+			return nil, err
+		}
+	}
+	params.ClientID = nil
+	return &Leg{Params: params, ClientID: id}, nil
+}
+
+// addLocked starts tracking a group.
+func (e *Engine) addLocked(g *Group) {
+	e.groups[g.ID] = g
+	for _, l := range g.legs() {
+		e.byClient[l.ClientID] = g
+		for _, id := range l.Retired {
+			e.byClient[id] = g
+		}
+	}
+}
+
+// openLocked places the first orders of a new group, cancelling the group
+// if one of them fails.
+func (e *Engine) openLocked(ctx context.Context, g *Group, legs []*Leg) error {
+	for _, l := range legs {
+		if err := e.placeLocked(ctx, g, l); err != nil {
+			g.Error = err.Error()
+			// The failed order may have been placed; cancelling it by
+			// client ID makes sure it is not.
+			if cerr := e.closeLocked(ctx, g, StateCancelled); cerr != nil {
+				return errors.Join(err, cerr)
+			}
+			return err
+		}
+	}
+	e.touchLocked(g)
+	return e.evaluateLocked(ctx, g)
+}
+
+// evaluateLocked brings a group's orders in line with what has executed,
+// one action at a time until nothing is left to do.
+func (e *Engine) evaluateLocked(ctx context.Context, g *Group) error {
+	var err error
+	for i := 0; i < maxSteps && !g.Terminal(); i++ {
+		var acted bool
+		if acted, err = e.stepLocked(ctx, g); err != nil {
+			g.Error = err.Error()
+			if isRejected(err) {
+				// Retrying would fail again; end the group rather than
+				// leaving it half in place.
+				err = errors.Join(err, e.closeLocked(ctx, g, StateCancelled))
+			}
+			e.touchLocked(g)
+			break
+		}
+		if !acted {
+			break
+		}
+		g.Error = ""
+		e.touchLocked(g)
+	}
+	return errors.Join(err, e.saveLocked())
+}
+
+// stepLocked performs the next action a group needs and reports whether it
+// did anything.
+func (e *Engine) stepLocked(ctx context.Context, g *Group) (bool, error) {
+	entryOpen := false
+	if g.Entry != nil {
+		if total := g.Entry.Total(); total.GreaterThan(g.Quantity) {
+			g.Quantity = total
+			return true, nil
+		}
+		entryOpen = g.Entry.Open()
+		if entryOpen && g.Executed().IsPositive() {
+			// An exit has executed; the position is being closed.
+			return true, e.cancelLocked(ctx, g, g.Entry)
+		}
+		if !g.Quantity.IsPositive() {
+			if entryOpen {
+				return false, nil
+			}
+			g.State = StateCancelled
+			e.retireLocked(g)
+			return true, nil
+		}
+		if g.State == StatePending {
+			g.State = StateActive
+			return true, nil
+		}
+	}
+
+	working := false
+	for _, l := range g.Legs {
+		if l.Status.Terminal() && l.Status != enums.OrderStatusFilled {
+			// The order ended without filling, such as when cancelled
+			// outside of the engine: the group cannot continue.
+			return true, e.closeLocked(ctx, g, StateCancelled)
+		}
+		if l.Open() || !l.placed() {
+			working = true
+		}
+	}
+
+	remaining := g.Remaining()
+	if !remaining.IsPositive() || !working {
+		if entryOpen {
+			return false, nil
+		}
+		return true, e.closeLocked(ctx, g, StateDone)
+	}
+	for _, l := range g.Legs {
+		switch {
+		case !l.placed():
+			l.Params.Quantity = remaining.String()
+			return true, e.placeLocked(ctx, g, l)
+		case l.Open() && !l.size().Sub(l.Executed).Equal(remaining):
+			return true, e.resizeLocked(ctx, g, l)
+		}
+	}
+	return false, nil
+}
+
+// resizeLocked cancels a leg's order so that it is placed again with the
+// remaining quantity. If the order turns out to have filled, it is kept.
+func (e *Engine) resizeLocked(ctx context.Context, g *Group, l *Leg) error {
+	if err := e.cancelLocked(ctx, g, l); err != nil {
+		return err
+	}
+	if l.Status == enums.OrderStatusFilled {
+		return nil
+	}
+	id, err := e.ids.Next()
+	if err != nil {
+		return err
+	}
+	l.Prior = l.Prior.Add(l.Executed)
+	l.Retired = append(l.Retired, l.ClientID)
+	l.ClientID, l.OrderID, l.Status, l.Executed = id, "", "", types.Decimal{}
+	l.PlacedAt = time.Time{}
+	e.byClient[id] = g
+	return nil
+}
+
+// closeLocked cancels the open orders of a group and ends it with state.
+func (e *Engine) closeLocked(ctx context.Context, g *Group, state State) error {
+	var errs []error
+	for _, l := range g.legs() {
+		if l.Status.Terminal() || g.unsized(l) {
+			continue
+		}
+		if err := e.cancelLocked(ctx, g, l); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		e.touchLocked(g)
+		return errors.Join(err, e.saveLocked())
+	}
+	g.State = state
+	e.retireLocked(g)
+	e.touchLocked(g)
+	return e.saveLocked()
+}
+
+// placeLocked places a leg's order with the submitter, which looks it up by
+// client ID before placing it again after an ambiguous failure. An order
+// submitted by an earlier call is looked up first too. The group is
+// persisted first so the order can be found after a crash.
+func (e *Engine) placeLocked(ctx context.Context, g *Group, l *Leg) error {
+	id := l.ClientID
+	if !l.PlacedAt.IsZero() {
+		o, err := e.submitter.Lookup(ctx, g.Symbol, id, l.PlacedAt)
+		if err != nil {
+			return fmt.Errorf("ordergroup: failed to look up order %d of group %s: %w", id, g.ID, err)
+		}
+		if o != nil {
+			applyOrder(l, o)
+			return nil
+		}
+	} else {
+		l.PlacedAt = time.Now()
+	}
+	if err := e.saveLocked(); err != nil {
+		return err
+	}
+	params := l.Params
+	params.ClientID = &id
+	o, err := e.submitter.Submit(ctx, params)
+	if err != nil {
+		return fmt.Errorf("ordergroup: failed to place order %d of group %s: %w", id, g.ID, err)
+	}
+	applyOrder(l, o)
+	return nil
+}
+
+// cancelLocked cancels a leg's order and records its final state. An order
+// that is not found has closed or was never placed; its final state is
+// looked up.
+func (e *Engine) cancelLocked(ctx context.Context, g *Group, l *Leg) error {
+	id := l.ClientID
+	params := types.CancelOrderParams{Symbol: g.Symbol, OrderID: l.OrderID}
+	if l.OrderID == "" {
+		params.ClientID = &id
+	}
+	o, err := e.orders.CancelOrder(ctx, params)
+	if err != nil {
+		if !isNotFound(err) {
+			return fmt.Errorf("ordergroup: failed to cancel order %d of group %s: %w", id, g.ID, err)
+		}
+		if o, err = e.submitter.Lookup(ctx, g.Symbol, id, l.PlacedAt); err != nil {
+			return fmt.Errorf("ordergroup: failed to look up order %d of group %s: %w", id, g.ID, err)
+		}
+		if o == nil {
+			l.Status = enums.OrderStatusCancelled
+			return nil
+		}
+	}
+	applyOrder(l, o)
+	if !l.Status.Terminal() {
+		l.Status = enums.OrderStatusCancelled
+	}
+	return nil
+}
+
+// refreshLocked updates the legs of a group from REST.
+func (e *Engine) refreshLocked(ctx context.Context, g *Group) error {
+	for _, l := range g.legs() {
+		if l.Status.Terminal() || g.unsized(l) {
+			continue
+		}
+		o, err := e.submitter.Lookup(ctx, g.Symbol, l.ClientID, l.PlacedAt)
+		if err != nil {
+			return fmt.Errorf("ordergroup: failed to look up order %d of group %s: %w", l.ClientID, g.ID, err)
+		}
+		switch {
+		case o != nil:
+			if applyOrder(l, o) {
+				e.touchLocked(g)
+			}
+		case l.placed() || l == g.Entry:
+			// The order is gone without a trace, or the entry never
+			// reached the exchange and is not placed late.
+			l.Status = enums.OrderStatusCancelled
+			e.touchLocked(g)
+		}
+	}
+	return nil
+}
+
+// applyUpdatesLocked applies queued order updates and returns the groups
+// they changed.
+func (e *Engine) applyUpdatesLocked(updates []types.WSOrderUpdate) []*Group {
+	var touched []*Group
+	seen := make(map[*Group]bool)
+	for i := range updates {
+		u := &updates[i]
+		g, ok := e.byClient[*u.ClientID]
+		if !ok || g.Terminal() {
+			continue
+		}
+		l := g.leg(*u.ClientID)
+		if l == nil || l.ClientID != *u.ClientID {
+			// Updates of replaced orders are superseded by the final
+			// state returned when they were cancelled.
+			continue
+		}
+		if !advance(l, u.Status, u.ExecutedQuantity) {
+			continue
+		}
+		if u.OrderID != "" {
+			l.OrderID = string(u.OrderID)
+		}
+		e.touchLocked(g)
+		if !seen[g] {
+			seen[g] = true
+			touched = append(touched, g)
+		}
+	}
+	return touched
+}
+
+// retireLocked records that a group has ended and forgets the oldest ended
+// groups beyond the limit.
+func (e *Engine) retireLocked(g *Group) {
+	e.closed = append(e.closed, g.ID)
+	for len(e.closed) > e.maxClosed {
+		if old, ok := e.groups[e.closed[0]]; ok {
+			delete(e.groups, old.ID)
+			for _, l := range old.legs() {
+				delete(e.byClient, l.ClientID)
+				for _, id := range l.Retired {
+					delete(e.byClient, id)
+				}
+			}
+		}
+		e.closed = e.closed[1:]
+	}
+}
+
+// touchLocked queues a notification of a group's current state, replacing
+// one already queued.
+func (e *Engine) touchLocked(g *Group) {
+	if e.onUpdate == nil {
+		return
+	}
+	for i := range e.changed {
+		if e.changed[i].ID == g.ID {
+			e.changed[i] = g.clone()
+			return
+		}
+	}
+	e.changed = append(e.changed, g.clone())
+}
+
+// flush delivers queued notifications.
+func (e *Engine) flush() {
+	e.mu.Lock()
+	changed := e.changed
+	e.changed = nil
+	e.mu.Unlock()
+	for _, g := range changed {
+		e.onUpdate(g)
+	}
+}
+
+// state is the content of the engine's state file.
+type state struct {
+	Groups []*Group `json:"groups"`
+}
+
+// saveLocked persists the active groups.
+func (e *Engine) saveLocked() error {
+	if e.path == "" {
+		return nil
+	}
+	s := state{Groups: []*Group{}}
+	for _, g := range e.groups {
+		if !g.Terminal() {
+			s.Groups = append(s.Groups, g)
+		}
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ordergroup: failed to encode state: %w", err)
+	}
+	if err := atomicfile.WriteFile(e.path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("ordergroup: failed to write %s: %w", e.path, err)
+	}
+	return nil
+}
+
+func (e *Engine) loop(ctx context.Context) {
+	backoff := 500 * time.Millisecond
+	var retry <-chan time.Time
+	for {
+		reconcile := false
+		select {
+		case <-ctx.Done():
+			return
+		case <-e.wake:
+		case <-e.resync:
+			reconcile = true
+		case <-retry:
+			retry = nil
+			reconcile = true
+		}
+
+		e.queueMu.Lock()
+		updates := e.queue
+		e.queue = nil
+		e.queueMu.Unlock()
+
+		var errs []error
+		e.mu.Lock()
+		for _, g := range e.applyUpdatesLocked(updates) {
+			if err := e.evaluateLocked(ctx, g); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		e.mu.Unlock()
+		e.flush()
+		if reconcile {
+			errs = append(errs, e.Reconcile(ctx))
+		}
+
+		err := errors.Join(errs...)
+		if err == nil {
+			if reconcile {
+				backoff = 500 * time.Millisecond
+			}
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if e.onError != nil {
+			e.onError(err)
+		}
+		if retry == nil {
+			retry = time.After(backoff)
+			if backoff < 30*time.Second {
+				backoff *= 2
+			}
+		}
+	}
+}
+
+// applyOrder applies the state of a leg's order returned by REST and
+// reports whether the leg changed.
+func applyOrder(l *Leg, o *types.Order) bool {
+	if o.ClientID != 0 && o.ClientID != l.ClientID {
+		return false
+	}
+	if !advance(l, o.Status, o.ExecutedQuantity) {
+		return false
+	}
+	if o.ID != "" {
+		l.OrderID = o.ID
+	}
+	return true
+}
+
+// advance moves a leg's order to a status and executed quantity, ignoring
+// stale states, and reports whether it changed.
+func advance(l *Leg, status enums.OrderStatus, executed string) bool {
+	changed := false
+	if e, err := types.ParseDecimal(executed); err == nil && e.GreaterThan(l.Executed) {
+		l.Executed = e
+		changed = true
+	}
+	if !l.Status.Terminal() && status != l.Status && status.Rank() >= l.Status.Rank() {
+		l.Status = status
+		changed = true
+	}
+	return changed
+}
+
+// isNotFound reports whether an error means the order does not exist.
+func isNotFound(err error) bool {
+	apiErr, ok := bperrors.IsAPIError(err)
+	return ok && (apiErr.StatusCode == http.StatusNotFound || apiErr.HasCode(bperrors.ErrCodeResourceNotFound))
+}
+
+// isRejected reports whether an error is a definite rejection by the
+// exchange, as opposed to a failure worth retrying.
+func isRejected(err error) bool {
+	apiErr, ok := bperrors.IsAPIError(err)
+	return ok && apiErr.StatusCode < http.StatusInternalServerError && !bperrors.IsRetryable(err) && !isNotFound(err)
+}
+
+// signal wakes a goroutine waiting on ch without blocking.
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,116 @@
+package ordergroup
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/clientid"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/enums"
+	bperrors "github.com/solomeowl/backpack-exchange-sdk-go/backpack/errors"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/types"
+)
+
+// exchange rests every order it is sent. The first lost placements time
+// out, although their orders are placed.
+type exchange struct {
+	orders map[uint32]*types.Order
+	sent   int
+	lost   int
+	// down fails lookups, as during an outage.
+	down bool
+}
+
+var errNotFound = &bperrors.APIError{StatusCode: http.StatusNotFound, Code: string(bperrors.ErrCodeResourceNotFound)}
+
+func (x *exchange) ExecuteOrder(ctx context.Context, params types.ExecuteOrderParams) (*types.Order, error) {
+	x.sent++
+	o := &types.Order{
+		ID:               strconv.Itoa(x.sent),
+		ClientID:         *params.ClientID,
+		Symbol:           params.Symbol,
+		Side:             params.Side,
+		OrderType:        params.OrderType,
+		Quantity:         params.Quantity,
+		ExecutedQuantity: "0",
+		Status:           enums.OrderStatusNew,
+	}
+	x.orders[o.ClientID] = o
+	if x.lost > 0 {
+		x.lost--
+		return nil, &bperrors.APIError{StatusCode: http.StatusGatewayTimeout}
+	}
+	c := *o
+	return &c, nil
+}
+
+func (x *exchange) CancelOrder(ctx context.Context, params types.CancelOrderParams) (*types.Order, error) {
+	for _, o := range x.orders {
+		if (o.ID == params.OrderID || params.ClientID != nil && o.ClientID == *params.ClientID) && o.Status == enums.OrderStatusNew {
+			o.Status = enums.OrderStatusCancelled
+			c := *o
+			return &c, nil
+		}
+	}
+	return nil, errNotFound
+}
+
+func (x *exchange) GetOrder(ctx context.Context, params types.GetOrderParams) (*types.Order, error) {
+	if x.down {
+		return nil, &bperrors.APIError{StatusCode: http.StatusServiceUnavailable}
+	}
+	if o, ok := x.orders[*params.ClientID]; ok && o.Status == enums.OrderStatusNew {
+		c := *o
+		return &c, nil
+	}
+	return nil, errNotFound
+}
+
+func TestPlaceOCOFindsLostLeg(t *testing.T) {
+	x := &exchange{orders: make(map[uint32]*types.Order), lost: 1}
+	ids := clientid.New()
+	e := New(x, ids, WithSubmitter(clientid.NewSubmitter(x, ids, clientid.WithSettleDelay(0))))
+
+	leg := types.ExecuteOrderParams{Symbol: "SOL_USDC", Side: enums.SideAsk, OrderType: enums.OrderTypeLimit, Price: "200", Quantity: "1"}
+	stop := leg
+	stop.Price, stop.TriggerPrice = "", "150"
+	stop.OrderType = enums.OrderTypeMarket
+	g, err := e.PlaceOCO(context.Background(), leg, stop)
+	if err != nil {
+		t.Fatalf("PlaceOCO: %v", err)
+	}
+	if g.State != StateActive {
+		t.Errorf("group is %s, want Active", g.State)
+	}
+	if x.sent != 2 {
+		t.Errorf("sent %d orders, want 2", x.sent)
+	}
+	for _, l := range g.Legs {
+		if l.OrderID == "" || l.Status != enums.OrderStatusNew || l.PlacedAt.IsZero() {
+			t.Errorf("leg %d is %q with order %q placed at %s, want a New order", l.ClientID, l.Status, l.OrderID, l.PlacedAt)
+		}
+	}
+}
+
+func TestPlaceAfterUnknownOutcomeLooksUpFirst(t *testing.T) {
+	x := &exchange{orders: make(map[uint32]*types.Order), lost: 1, down: true}
+	ids := clientid.New()
+	e := New(x, ids, WithSubmitter(clientid.NewSubmitter(x, ids, clientid.WithSettleDelay(0))))
+
+	g := &Group{ID: "1", Kind: KindOCO, Symbol: "SOL_USDC", State: StateActive}
+	l := &Leg{Params: types.ExecuteOrderParams{Symbol: "SOL_USDC", Side: enums.SideAsk, OrderType: enums.OrderTypeLimit, Price: "200", Quantity: "1"}, ClientID: 9}
+	g.Legs = []*Leg{l}
+	if err := e.placeLocked(context.Background(), g, l); !errors.Is(err, clientid.ErrUnknownOutcome) {
+		t.Fatalf("placeLocked error = %v, want ErrUnknownOutcome", err)
+	}
+
+	x.down = false
+	if err := e.placeLocked(context.Background(), g, l); err != nil {
+		t.Fatalf("placeLocked: %v", err)
+	}
+	if x.sent != 1 || l.OrderID != "1" {
+		t.Errorf("sent %d orders and the leg has order %q, want the first order only", x.sent, l.OrderID)
+	}
+}
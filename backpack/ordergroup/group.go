@@ -0,0 +1,172 @@
+package ordergroup
+
+import (
+	"time"
+
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/enums"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/types"
+)
+
+// Kind is the type of an order group.
+type Kind string
+
+const (
+	// KindOCO links orders so that when one executes, the others shrink by
+	// the executed quantity, and are cancelled once one fills.
+	KindOCO Kind = "OCO"
+	// KindBracket places an entry order and, once it executes, an OCO of
+	// exit orders covering the executed quantity.
+	KindBracket Kind = "Bracket"
+)
+
+// State is the state of an order group.
+type State string
+
+const (
+	// StatePending is a bracket whose entry has not executed yet.
+	StatePending State = "Pending"
+	// StateActive is a group whose linked orders are working.
+	StateActive State = "Active"
+	// StateDone is a group whose quantity has been executed by its legs;
+	// the remaining orders have been cancelled.
+	StateDone State = "Done"
+	// StateCancelled is a group that ended before its quantity was
+	// executed, because it was cancelled, one of its orders ended without
+	// filling, or it could not be placed.
+	StateCancelled State = "Cancelled"
+)
+
+// Leg is an order of a group. Resizing a leg cancels its order and places
+// a new one with a new client ID, so a leg can span several orders over its
+// life; Executed counts the current one and Prior the ones before it.
+type Leg struct {
+	// Params is the order as placed. Quantity is the size of the current
+	// order; its client ID is ClientID.
+	Params   types.ExecuteOrderParams `json:"params"`
+	OrderID  string                   `json:"orderId,omitempty"`
+	ClientID uint32                   `json:"clientId"`
+	// Status is empty until the order is placed.
+	Status   enums.OrderStatus `json:"status,omitempty"`
+	Executed types.Decimal     `json:"executed"`
+	Prior    types.Decimal     `json:"prior"`
+	// Retired lists the client IDs of orders the leg replaced.
+	Retired []uint32 `json:"retired,omitempty"`
+	// PlacedAt is when the current order was first submitted, bounding how
+	// far back the order history is searched for it. It is zero until then.
+	PlacedAt time.Time `json:"placedAt"`
+}
+
+// Total returns the quantity executed by the leg across its orders.
+func (l *Leg) Total() types.Decimal {
+	return l.Executed.Add(l.Prior)
+}
+
+// Open reports whether the leg's current order is working.
+func (l *Leg) Open() bool {
+	return l.Status != "" && !l.Status.Terminal()
+}
+
+// placed reports whether the leg's current order has been acknowledged by
+// the exchange.
+func (l *Leg) placed() bool {
+	return l.Status != "" || l.OrderID != ""
+}
+
+// size returns the quantity of the leg's current order.
+func (l *Leg) size() types.Decimal {
+	d, _ := types.ParseDecimal(l.Params.Quantity)
+	return d
+}
+
+// owns reports whether a client ID belongs to the leg's current order or one
+// it replaced.
+func (l *Leg) owns(clientID uint32) bool {
+	if l.ClientID == clientID {
+		return true
+	}
+	for _, id := range l.Retired {
+		if id == clientID {
+			return true
+		}
+	}
+	return false
+}
+
+// Group is a set of linked orders on one market.
+type Group struct {
+	ID     string `json:"id"`
+	Kind   Kind   `json:"kind"`
+	Symbol string `json:"symbol"`
+	State  State  `json:"state"`
+	// Quantity is the quantity the exit legs cover together: the common
+	// quantity of an OCO, or the executed quantity of a bracket's entry.
+	Quantity types.Decimal `json:"quantity"`
+	// Entry is the entry order of a bracket; nil for an OCO.
+	Entry *Leg `json:"entry,omitempty"`
+	// Legs are the exit orders, at most one of which executes in full.
+	// The legs of a bracket are not placed until its entry executes.
+	Legs []*Leg `json:"legs"`
+	// Error is the last error acting on the group, cleared once an action
+	// succeeds.
+	Error string `json:"error,omitempty"`
+}
+
+// Terminal reports whether the group has ended.
+func (g *Group) Terminal() bool {
+	return g.State == StateDone || g.State == StateCancelled
+}
+
+// Executed returns the quantity executed by the exit legs.
+func (g *Group) Executed() types.Decimal {
+	var total types.Decimal
+	for _, l := range g.Legs {
+		total = total.Add(l.Total())
+	}
+	return total
+}
+
+// Remaining returns the quantity the exit legs have yet to execute.
+func (g *Group) Remaining() types.Decimal {
+	return g.Quantity.Sub(g.Executed())
+}
+
+// legs returns the entry, if any, followed by the exit legs.
+func (g *Group) legs() []*Leg {
+	if g.Entry == nil {
+		return g.Legs
+	}
+	return append([]*Leg{g.Entry}, g.Legs...)
+}
+
+// leg returns the leg owning a client ID.
+func (g *Group) leg(clientID uint32) *Leg {
+	for _, l := range g.legs() {
+		if l.owns(clientID) {
+			return l
+		}
+	}
+	return nil
+}
+
+// unsized reports whether a leg is an exit of a bracket that has not been
+// given a quantity, and so never placed.
+func (g *Group) unsized(l *Leg) bool {
+	return g.Kind == KindBracket && l != g.Entry && l.Params.Quantity == ""
+}
+
+func (g *Group) clone() Group {
+	c := *g
+	cloneLeg := func(l *Leg) *Leg {
+		lc := *l
+		lc.Retired = append([]uint32(nil), l.Retired...)
+		return &lc
+	}
+	if g.Entry != nil {
+		c.Entry = cloneLeg(g.Entry)
+	}
+	c.Legs = make([]*Leg, len(g.Legs))
+	for i, l := range g.Legs {
+		c.Legs[i] = cloneLeg(l)
+	}
+	return c
+}
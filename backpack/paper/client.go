@@ -0,0 +1,253 @@
+package paper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/enums"
+	bperrors "github.com/solomeowl/backpack-exchange-sdk-go/backpack/errors"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/services"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/types"
+)
+
+// Client is a services.HTTPClient that serves the order, capital, position
+// and history endpoints of the account from an Engine, so OrdersService,
+// CapitalService, PositionsService and HistoryService trade on paper.
+// Public requests and the remaining authenticated queries are passed to
+// the next client; other authenticated writes are rejected.
+type Client struct {
+	engine *Engine
+	next   services.HTTPClient
+}
+
+var _ services.HTTPClient = (*Client)(nil)
+
+// NewClient creates a Client trading on engine. next handles the requests
+// the engine does not serve and may be nil if only paper endpoints are used.
+func NewClient(engine *Engine, next services.HTTPClient) *Client {
+	return &Client{engine: engine, next: next}
+}
+
+// Get passes a public request to the next client.
+func (c *Client) Get(ctx context.Context, path string, params map[string]string, result any) error {
+	if c.next == nil {
+		return unsupported(path)
+	}
+	return c.next.Get(ctx, path, params, result)
+}
+
+// GetAuthenticated serves account queries from the engine.
+func (c *Client) GetAuthenticated(ctx context.Context, path string, params map[string]string, instruction string, result any) error {
+	switch path {
+	case "api/v1/order":
+		var clientID *uint32
+		if s := params["clientId"]; s != "" {
+			id, err := strconv.ParseUint(s, 10, 32)
+			if err != nil {
+				return apiError(http.StatusBadRequest, bperrors.ErrCodeInvalidClientRequest, "invalid clientId %q", s)
+			}
+			v := uint32(id)
+			clientID = &v
+		}
+		o, err := c.engine.Order(params["symbol"], params["orderId"], clientID)
+		if err != nil {
+			return err
+		}
+		return convert(o, result)
+	case "api/v1/orders":
+		orders := c.engine.OpenOrders(params["symbol"])
+		if mt := params["marketType"]; mt != "" {
+			orders, err := c.filterMarketType(ctx, orders, enums.MarketType(mt))
+			if err != nil {
+				return err
+			}
+			return convert(orders, result)
+		}
+		return convert(orders, result)
+	case "api/v1/capital":
+		return convert(c.engine.Balances(), result)
+	case "api/v1/position":
+		positions := c.engine.Positions()
+		if symbol := params["symbol"]; symbol != "" {
+			filtered := []types.Position{}
+			for _, p := range positions {
+				if p.Symbol == symbol {
+					filtered = append(filtered, p)
+				}
+			}
+			positions = filtered
+		}
+		return convert(positions, result)
+	case "wapi/v1/history/orders":
+		var orders []types.Order
+		history := c.engine.OrderHistory(params["symbol"])
+		for i := len(history) - 1; i >= 0; i-- {
+			if params["orderId"] == "" || history[i].ID == params["orderId"] {
+				orders = append(orders, history[i])
+			}
+		}
+		return convert(page(orders, params), result)
+	case "wapi/v1/history/fills":
+		var fills []types.Fill
+		history := c.engine.Fills()
+		for i := len(history) - 1; i >= 0; i-- {
+			f := history[i]
+			if (params["symbol"] == "" || f.Symbol == params["symbol"]) && (params["orderId"] == "" || f.OrderID == params["orderId"]) {
+				fills = append(fills, f)
+			}
+		}
+		return convert(page(fills, params), result)
+	case "wapi/v1/capital/deposits", "wapi/v1/capital/withdrawals":
+		return convert([]any{}, result)
+	case "api/v1/capital/collateral":
+		return apiError(http.StatusBadRequest, bperrors.ErrCodeNotImplemented, "collateral is not simulated in paper trading")
+	}
+	if c.next == nil {
+		return unsupported(path)
+	}
+	return c.next.GetAuthenticated(ctx, path, params, instruction, result)
+}
+
+// Post passes a public request to the next client.
+func (c *Client) Post(ctx context.Context, path string, body any, result any) error {
+	if c.next == nil {
+		return unsupported(path)
+	}
+	return c.next.Post(ctx, path, body, result)
+}
+
+// PostAuthenticated places paper orders.
+func (c *Client) PostAuthenticated(ctx context.Context, path string, body any, instruction string, result any) error {
+	if path != "api/v1/order" {
+		return unsupported(path)
+	}
+	var params types.ExecuteOrderParams
+	if err := convert(body, &params); err != nil {
+		return err
+	}
+	o, err := c.engine.Execute(ctx, params)
+	if err != nil {
+		return err
+	}
+	return convert(o, result)
+}
+
+// Delete passes a public request to the next client.
+func (c *Client) Delete(ctx context.Context, path string, body any, result any) error {
+	if c.next == nil {
+		return unsupported(path)
+	}
+	return c.next.Delete(ctx, path, body, result)
+}
+
+// DeleteAuthenticated cancels paper orders.
+func (c *Client) DeleteAuthenticated(ctx context.Context, path string, body any, instruction string, result any) error {
+	var params struct {
+		Symbol    string                `json:"symbol"`
+		OrderID   string                `json:"orderId"`
+		ClientID  *uint32               `json:"clientId"`
+		OrderType enums.CancelOrderType `json:"orderType"`
+	}
+	switch path {
+	case "api/v1/order":
+		if err := convert(body, &params); err != nil {
+			return err
+		}
+		o, err := c.engine.Cancel(params.Symbol, params.OrderID, params.ClientID)
+		if err != nil {
+			return err
+		}
+		return convert(o, result)
+	case "api/v1/orders":
+		if err := convert(body, &params); err != nil {
+			return err
+		}
+		return convert(c.engine.CancelAll(params.Symbol, params.OrderType), result)
+	}
+	return unsupported(path)
+}
+
+// Patch passes a public request to the next client.
+func (c *Client) Patch(ctx context.Context, path string, body any, result any) error {
+	if c.next == nil {
+		return unsupported(path)
+	}
+	return c.next.Patch(ctx, path, body, result)
+}
+
+// PatchAuthenticated rejects account changes, which are not simulated.
+func (c *Client) PatchAuthenticated(ctx context.Context, path string, body any, instruction string, result any) error {
+	return unsupported(path)
+}
+
+// PostBatchOrders places several paper orders.
+func (c *Client) PostBatchOrders(ctx context.Context, path string, orders []map[string]any, result any) error {
+	params := make([]types.ExecuteOrderParams, len(orders))
+	for i, order := range orders {
+		if err := convert(order, &params[i]); err != nil {
+			return err
+		}
+	}
+	return convert(c.engine.ExecuteBatch(ctx, params), result)
+}
+
+func (c *Client) filterMarketType(ctx context.Context, orders []types.Order, marketType enums.MarketType) ([]types.Order, error) {
+	filtered := []types.Order{}
+	for _, o := range orders {
+		m, err := c.engine.market(ctx, o.Symbol)
+		if err != nil {
+			return nil, err
+		}
+		if m.MarketType == marketType {
+			filtered = append(filtered, o)
+		}
+	}
+	return filtered, nil
+}
+
+// unsupported is returned for requests the paper client cannot serve.
+func unsupported(path string) error {
+	return apiError(http.StatusBadRequest, bperrors.ErrCodeNotImplemented, "%s is not supported in paper trading", path)
+}
+
+// convert copies v into result through its JSON encoding, the way a
+// response body would be decoded.
+func convert(v any, result any) error {
+	if result == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("paper: failed to encode: %w", err)
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		return fmt.Errorf("paper: failed to decode: %w", err)
+	}
+	return nil
+}
+
+// page applies the limit and offset query parameters to newest-first
+// history, reversing it if sortDirection is Asc.
+func page[T any](items []T, query map[string]string) []T {
+	if query["sortDirection"] == string(enums.SortDirectionAsc) {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+	offset, _ := strconv.Atoi(query["offset"])
+	limit, err := strconv.Atoi(query["limit"])
+	if err != nil || limit <= 0 {
+		limit = 100
+	}
+	if offset >= len(items) {
+		return []T{}
+	}
+	items = items[offset:]
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return items
+}
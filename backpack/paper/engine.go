@@ -0,0 +1,408 @@
+// Package paper simulates trading on Backpack Exchange. An Engine keeps a
+// virtual account and matches orders locally against live public market
+// data, and Client serves the order, capital and position endpoints from it
+// so the SDK's services can be used without touching real funds.
+package paper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/enums"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/market"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/orderbook"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/types"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/websocket"
+)
+
+// FeeSource provides the account fee tiers. *services.AccountService
+// implements FeeSource.
+type FeeSource interface {
+	GetAccount(ctx context.Context) (*types.Account, error)
+}
+
+// Fees are fee rates in basis points, as reported by GET /api/v1/account.
+type Fees struct {
+	SpotMaker    types.Decimal
+	SpotTaker    types.Decimal
+	FuturesMaker types.Decimal
+	FuturesTaker types.Decimal
+}
+
+// balance is the holding of an asset.
+type balance struct {
+	available types.Decimal
+	locked    types.Decimal
+}
+
+// position is the net position of a perpetual market.
+type position struct {
+	quantity types.Decimal // Positive for long, negative for short
+	entry    types.Decimal
+	realized types.Decimal
+	id       string
+}
+
+// book is the simulated liquidity of a market. Liquidity taken by paper
+// orders is removed until the next book update.
+type book struct {
+	bids []types.PriceLevel
+	asks []types.PriceLevel
+}
+
+// Engine is a simulated account with a local matching engine. It is safe
+// for concurrent use.
+//
+// Orders take liquidity from the latest order book of their market, walking
+// the levels as a real taker would. Resting orders fill as maker at their
+// limit price when the book moves through them or a public trade prints at
+// or through their price, up to the trade's quantity; queue position is not
+// modelled. Trigger orders fire on the last trade price. Perpetual fills
+// update a net position and realize PnL into the quote asset; margin is
+// not checked.
+type Engine struct {
+	mu        sync.Mutex
+	markets   market.MarketSource
+	fees      Fees
+	balances  map[string]*balance
+	positions map[string]*position
+	books     map[string]*book
+	last      map[string]types.Decimal
+	orders    []*order
+	fills     []types.Fill
+	nextID    int64
+	nextTrade int64
+	now       func() time.Time
+
+	// Order updates wait in pending, in the order they happened, until
+	// flush delivers them; flushing is set while a goroutine is delivering.
+	pending  []types.WSOrderUpdate
+	flushing bool
+
+	listenersMu sync.RWMutex
+	onUpdate    []func(types.WSOrderUpdate)
+}
+
+// Option is a functional option for configuring an Engine.
+type Option func(*Engine)
+
+// WithBalance credits the simulated account with an available balance.
+func WithBalance(asset, amount string) Option {
+	return func(e *Engine) {
+		e.balanceLocked(asset).available = types.MustParseDecimal(amount)
+	}
+}
+
+// WithFees sets the fee rates charged on fills.
+func WithFees(fees Fees) Option {
+	return func(e *Engine) {
+		e.fees = fees
+	}
+}
+
+// WithAccountFees charges the fee tiers of an account, as returned by
+// AccountService.GetAccount.
+func WithAccountFees(account types.Account) Option {
+	return func(e *Engine) {
+		e.fees = accountFees(account)
+	}
+}
+
+// WithMarkets sets the source of market metadata, such as a
+// *market.Registry. Without it, or until it is set with SetMarkets, symbols
+// are assumed to be BASE_QUOTE spot markets, or BASE_QUOTE_PERP perpetuals,
+// without filters.
+func WithMarkets(markets market.MarketSource) Option {
+	return func(e *Engine) {
+		e.markets = markets
+	}
+}
+
+// NewEngine creates a new Engine with an empty account.
+func NewEngine(opts ...Option) *Engine {
+	e := &Engine{
+		balances:  make(map[string]*balance),
+		positions: make(map[string]*position),
+		books:     make(map[string]*book),
+		last:      make(map[string]types.Decimal),
+		now:       time.Now,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// SetMarkets sets the source of market metadata if none was configured.
+func (e *Engine) SetMarkets(markets market.MarketSource) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.markets == nil {
+		e.markets = markets
+	}
+}
+
+// LoadFees charges the fee tiers of the account returned by src.
+func (e *Engine) LoadFees(ctx context.Context, src FeeSource) error {
+	account, err := src.GetAccount(ctx)
+	if err != nil {
+		return fmt.Errorf("paper: failed to load fees: %w", err)
+	}
+	e.mu.Lock()
+	e.fees = accountFees(*account)
+	e.mu.Unlock()
+	return nil
+}
+
+func accountFees(account types.Account) Fees {
+	parse := func(s string) types.Decimal {
+		d, _ := types.ParseDecimal(s)
+		return d
+	}
+	return Fees{
+		SpotMaker:    parse(account.SpotMakerFee),
+		SpotTaker:    parse(account.SpotTakerFee),
+		FuturesMaker: parse(account.FuturesMakerFee),
+		FuturesTaker: parse(account.FuturesTakerFee),
+	}
+}
+
+// OnOrderUpdate registers a listener invoked for every change of a paper
+// order, shaped like the account.orderUpdate stream. Updates are delivered
+// one at a time, in the order they happened, from the goroutine that caused
+// them or from one delivering earlier updates at the time. Listeners may
+// call back into the engine; the updates this causes are delivered after
+// the current one.
+func (e *Engine) OnOrderUpdate(fn func(types.WSOrderUpdate)) {
+	e.listenersMu.Lock()
+	e.onUpdate = append(e.onUpdate, fn)
+	e.listenersMu.Unlock()
+}
+
+// queueLocked adds updates to the delivery queue. They are queued under
+// e.mu, in the order the changes were made, so that an order's fill can
+// never be delivered before its acceptance.
+func (e *Engine) queueLocked(updates []types.WSOrderUpdate) {
+	e.pending = append(e.pending, updates...)
+}
+
+// flush delivers queued updates unless another goroutine already is, in
+// which case that goroutine delivers them too.
+func (e *Engine) flush() {
+	e.mu.Lock()
+	if e.flushing {
+		e.mu.Unlock()
+		return
+	}
+	e.flushing = true
+	for len(e.pending) > 0 {
+		updates := e.pending
+		e.pending = nil
+		e.mu.Unlock()
+
+		e.listenersMu.RLock()
+		listeners := e.onUpdate
+		e.listenersMu.RUnlock()
+		for _, u := range updates {
+			for _, fn := range listeners {
+				fn(u)
+			}
+		}
+
+		e.mu.Lock()
+	}
+	e.flushing = false
+	e.mu.Unlock()
+}
+
+// UpdateBook replaces the simulated liquidity of a market and fills resting
+// orders the book has moved through.
+func (e *Engine) UpdateBook(snapshot *orderbook.Snapshot) {
+	e.mu.Lock()
+	e.books[snapshot.Symbol] = &book{
+		bids: append([]types.PriceLevel(nil), snapshot.Bids...),
+		asks: append([]types.PriceLevel(nil), snapshot.Asks...),
+	}
+	e.queueLocked(e.matchRestingLocked(snapshot.Symbol))
+	e.mu.Unlock()
+
+	e.flush()
+}
+
+// HandleTrade records a public trade: it sets the last price, fires trigger
+// orders and fills resting orders the trade printed at or through.
+func (e *Engine) HandleTrade(trade *types.WSTrade) {
+	price, err := types.ParseDecimal(trade.Price)
+	if err != nil {
+		return
+	}
+	quantity, err := types.ParseDecimal(trade.Quantity)
+	if err != nil {
+		return
+	}
+
+	e.mu.Lock()
+	e.last[trade.Symbol] = price
+	updates, triggered := e.triggerLocked(trade.Symbol, price)
+	e.queueLocked(updates)
+	// Orders the trade triggered have already executed against the book;
+	// the trade does not fill them again.
+	e.queueLocked(e.fillOnTradeLocked(trade.Symbol, price, quantity, triggered))
+	e.mu.Unlock()
+
+	e.flush()
+}
+
+// Track feeds the engine with the order book and trades of a symbol from
+// the WebSocket API until ctx is done. source loads order book snapshots,
+// e.g. MarketsService.
+func (e *Engine) Track(ctx context.Context, h *websocket.Handler, source orderbook.SnapshotSource, symbol string) error {
+	depth, err := h.Depth(ctx, symbol)
+	if err != nil {
+		return fmt.Errorf("paper: failed to subscribe to depth: %w", err)
+	}
+	trades, err := h.Trades(ctx, symbol)
+	if err != nil {
+		depth.Close()
+		return fmt.Errorf("paper: failed to subscribe to trades: %w", err)
+	}
+
+	b := orderbook.New(symbol, source, orderbook.WithOnUpdate(func(b *orderbook.Book) {
+		e.UpdateBook(b.Snapshot())
+	}))
+	b.Start(ctx)
+	go func() {
+		defer b.Stop()
+		diffs, prints := depth.C, trades.C
+		for diffs != nil || prints != nil {
+			select {
+			case diff, ok := <-diffs:
+				if !ok {
+					diffs = nil
+					continue
+				}
+				b.HandleDepth(&diff)
+			case trade, ok := <-prints:
+				if !ok {
+					prints = nil
+					continue
+				}
+				e.HandleTrade(&trade)
+			}
+		}
+	}()
+	return nil
+}
+
+// Balances returns the balances of the simulated account.
+func (e *Engine) Balances() types.Balances {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	balances := make(types.Balances, len(e.balances))
+	for asset, b := range e.balances {
+		balances[asset] = types.Balance{Available: b.available.String(), Locked: b.locked.String(), Staked: "0"}
+	}
+	return balances
+}
+
+// Positions returns the open perpetual positions of the simulated account,
+// sorted by symbol.
+func (e *Engine) Positions() []types.Position {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	positions := []types.Position{}
+	for symbol, p := range e.positions {
+		if p.quantity.IsZero() {
+			continue
+		}
+		mark := e.markLocked(symbol)
+		unrealized := types.Decimal{}
+		if !mark.IsZero() {
+			unrealized = mark.Sub(p.entry).Mul(p.quantity)
+		}
+		positions = append(positions, types.Position{
+			Symbol:              symbol,
+			PositionID:          p.id,
+			NetQuantity:         p.quantity.String(),
+			NetExposureQuantity: p.quantity.Abs().String(),
+			NetExposureNotional: p.quantity.Abs().Mul(mark).String(),
+			NetCost:             p.quantity.Mul(p.entry).String(),
+			EntryPrice:          p.entry.String(),
+			BreakEvenPrice:      p.entry.String(),
+			MarkPrice:           mark.String(),
+			PnlRealized:         p.realized.String(),
+			PnlUnrealized:       unrealized.String(),
+		})
+	}
+	sort.Slice(positions, func(i, j int) bool { return positions[i].Symbol < positions[j].Symbol })
+	return positions
+}
+
+// OpenOrders returns the open orders of a symbol, or of all symbols if
+// symbol is empty, oldest first.
+func (e *Engine) OpenOrders(symbol string) []types.Order {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	orders := []types.Order{}
+	for _, o := range e.orders {
+		if o.open() && (symbol == "" || o.Symbol == symbol) {
+			orders = append(orders, o.Order)
+		}
+	}
+	return orders
+}
+
+// Fills returns every fill of the simulated account, oldest first.
+func (e *Engine) Fills() []types.Fill {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]types.Fill{}, e.fills...)
+}
+
+// markLocked returns the reference price of a symbol: the last trade price,
+// or the mid price of the book before any trade.
+func (e *Engine) markLocked(symbol string) types.Decimal {
+	if p, ok := e.last[symbol]; ok {
+		return p
+	}
+	if b := e.books[symbol]; b != nil && len(b.bids) > 0 && len(b.asks) > 0 {
+		return b.bids[0].Price.Add(b.asks[0].Price).Div(types.NewDecimalFromInt(2), 8)
+	}
+	return types.Decimal{}
+}
+
+func (e *Engine) balanceLocked(asset string) *balance {
+	b := e.balances[asset]
+	if b == nil {
+		b = &balance{}
+		e.balances[asset] = b
+	}
+	return b
+}
+
+// errUnknownMarket is returned for symbols that cannot be resolved.
+var errUnknownMarket = errors.New("paper: unknown market")
+
+// parseMarket derives the metadata of a symbol from its name.
+func parseMarket(symbol string) (*types.Market, error) {
+	parts := strings.Split(symbol, "_")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("%w: %s", errUnknownMarket, symbol)
+	}
+	m := &types.Market{
+		Symbol:         symbol,
+		BaseSymbol:     enums.CustodyAsset(parts[0]),
+		QuoteSymbol:    enums.CustodyAsset(parts[1]),
+		MarketType:     enums.MarketTypeSpot,
+		OrderBookState: enums.OrderBookStateOpen,
+	}
+	if len(parts) == 3 && parts[2] == "PERP" {
+		m.MarketType = enums.MarketTypePerp
+	}
+	return m, nil
+}
@@ -0,0 +1,176 @@
+package paper
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/enums"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/orderbook"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/types"
+)
+
+func level(price, quantity string) types.PriceLevel {
+	return types.PriceLevel{Price: types.MustParseDecimal(price), Quantity: types.MustParseDecimal(quantity)}
+}
+
+func limit(side enums.Side, price, quantity string) types.ExecuteOrderParams {
+	return types.ExecuteOrderParams{
+		Symbol:    "SOL_USDC",
+		Side:      side,
+		OrderType: enums.OrderTypeLimit,
+		Price:     price,
+		Quantity:  quantity,
+	}
+}
+
+// history returns the state of an order, open or not.
+func history(t *testing.T, e *Engine, id string) types.Order {
+	t.Helper()
+	for _, o := range e.OrderHistory("SOL_USDC") {
+		if o.ID == id {
+			return o
+		}
+	}
+	t.Fatalf("order %s not found", id)
+	return types.Order{}
+}
+
+func TestExecuteWalksBook(t *testing.T) {
+	e := NewEngine(WithBalance("USDC", "1000"))
+	e.UpdateBook(&orderbook.Snapshot{
+		Symbol: "SOL_USDC",
+		Asks:   []types.PriceLevel{level("100", "1"), level("101", "1"), level("102", "1")},
+	})
+
+	o, err := e.Execute(context.Background(), limit(enums.SideBid, "101", "3"))
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if o.Status != enums.OrderStatusPartiallyFilled || o.ExecutedQuantity != "2" || o.ExecutedQuoteQuantity != "201" {
+		t.Errorf("order = %s executed %s for %s, want PartiallyFilled executed 2 for 201", o.Status, o.ExecutedQuantity, o.ExecutedQuoteQuantity)
+	}
+	if fills := e.Fills(); len(fills) != 2 {
+		t.Errorf("got %d fills, want 2", len(fills))
+	}
+	usdc := e.Balances()["USDC"]
+	if !types.MustParseDecimal(usdc.Available).Equal(types.MustParseDecimal("698")) || !types.MustParseDecimal(usdc.Locked).Equal(types.MustParseDecimal("101")) {
+		t.Errorf("USDC available %s locked %s, want 698 and 101", usdc.Available, usdc.Locked)
+	}
+
+	// The taken levels stay gone until the next book update.
+	if _, err := e.Execute(context.Background(), limit(enums.SideBid, "101", "1")); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if open := e.OpenOrders("SOL_USDC"); len(open) != 2 {
+		t.Errorf("got %d open orders, want 2", len(open))
+	}
+}
+
+func TestTradeFillsRestingOrders(t *testing.T) {
+	e := NewEngine(WithBalance("USDC", "1000"))
+	first, _ := e.Execute(context.Background(), limit(enums.SideBid, "100", "1"))
+	second, _ := e.Execute(context.Background(), limit(enums.SideBid, "99", "1"))
+
+	e.HandleTrade(&types.WSTrade{Symbol: "SOL_USDC", Price: "99.5", Quantity: "5"})
+	if o := history(t, e, first.ID); o.Status != enums.OrderStatusFilled {
+		t.Errorf("order at 100 is %s, want Filled", o.Status)
+	}
+	if o := history(t, e, second.ID); o.Status != enums.OrderStatusNew {
+		t.Errorf("order at 99 is %s, want New", o.Status)
+	}
+
+	// A trade fills no more than its own quantity.
+	e.HandleTrade(&types.WSTrade{Symbol: "SOL_USDC", Price: "98", Quantity: "0.25"})
+	if o := history(t, e, second.ID); o.ExecutedQuantity != "0.25" {
+		t.Errorf("order at 99 executed %s, want 0.25", o.ExecutedQuantity)
+	}
+}
+
+func TestTriggeredOrderNotFilledBySameTrade(t *testing.T) {
+	e := NewEngine(WithBalance("USDC", "1000"))
+	e.UpdateBook(&orderbook.Snapshot{
+		Symbol: "SOL_USDC",
+		Asks:   []types.PriceLevel{level("101", "0.5")},
+	})
+	params := limit(enums.SideBid, "101", "1")
+	params.TriggerPrice = "100"
+	o, err := e.Execute(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	e.HandleTrade(&types.WSTrade{Symbol: "SOL_USDC", Price: "100", Quantity: "5"})
+	got := history(t, e, o.ID)
+	if got.Status != enums.OrderStatusPartiallyFilled || got.ExecutedQuantity != "0.5" {
+		t.Errorf("triggered order is %s executed %s, want PartiallyFilled executed 0.5", got.Status, got.ExecutedQuantity)
+	}
+
+	// Later trades fill what rests.
+	e.HandleTrade(&types.WSTrade{Symbol: "SOL_USDC", Price: "100", Quantity: "5"})
+	if got := history(t, e, o.ID); got.Status != enums.OrderStatusFilled {
+		t.Errorf("triggered order is %s after the next trade, want Filled", got.Status)
+	}
+}
+
+func TestOrderUpdatesInOrder(t *testing.T) {
+	e := NewEngine(WithBalance("USDC", "1000000"))
+
+	var mu sync.Mutex
+	events := make(map[types.WSID][]string)
+	e.OnOrderUpdate(func(u types.WSOrderUpdate) {
+		mu.Lock()
+		events[u.OrderID] = append(events[u.OrderID], u.Event)
+		mu.Unlock()
+	})
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			e.Execute(context.Background(), limit(enums.SideBid, "100", "1"))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			e.UpdateBook(&orderbook.Snapshot{Symbol: "SOL_USDC", Asks: []types.PriceLevel{level("100", "1")}})
+		}
+	}()
+	wg.Wait()
+
+	for id, got := range events {
+		if got[0] != "orderAccepted" {
+			t.Fatalf("order %s events %v, want orderAccepted first", id, got)
+		}
+	}
+}
+
+func TestListenerMayCallEngine(t *testing.T) {
+	e := NewEngine(WithBalance("USDC", "1000"))
+
+	var events []string
+	e.OnOrderUpdate(func(u types.WSOrderUpdate) {
+		events = append(events, u.Event)
+		if u.Event == "orderAccepted" {
+			if _, err := e.Cancel(u.Symbol, string(u.OrderID), nil); err != nil {
+				t.Errorf("Cancel: %v", err)
+			}
+			events = append(events, "cancelReturned")
+		}
+	})
+
+	if _, err := e.Execute(context.Background(), limit(enums.SideBid, "100", "1")); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	want := []string{"orderAccepted", "cancelReturned", "orderCancelled"}
+	if len(events) != len(want) {
+		t.Fatalf("events = %v, want %v", events, want)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Fatalf("events = %v, want %v", events, want)
+		}
+	}
+}
@@ -0,0 +1,723 @@
+package paper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/enums"
+	bperrors "github.com/solomeowl/backpack-exchange-sdk-go/backpack/errors"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/market"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/types"
+)
+
+// bps converts basis points to a rate.
+var bps = types.NewDecimalFromInt(10000)
+
+// order is a paper order.
+type order struct {
+	types.Order
+
+	market        *types.Market
+	price         types.Decimal
+	quantity      types.Decimal
+	quoteQuantity types.Decimal
+	executed      types.Decimal
+	executedQuote types.Decimal
+	locked        types.Decimal // Funds locked by the resting order
+	triggerPrice  types.Decimal
+	triggerAbove  bool // Whether the trigger fires on the price rising to triggerPrice
+	reduceOnly    bool
+}
+
+func (o *order) open() bool {
+	switch o.Status {
+	case enums.OrderStatusNew, enums.OrderStatusPartiallyFilled, enums.OrderStatusTriggerPending:
+		return true
+	}
+	return false
+}
+
+func (o *order) perp() bool {
+	return o.market.MarketType == enums.MarketTypePerp
+}
+
+func (o *order) remaining() types.Decimal {
+	return o.quantity.Sub(o.executed)
+}
+
+// apiError builds an error shaped like the exchange's error responses.
+func apiError(status int, code bperrors.ApiErrorCode, format string, args ...any) *bperrors.APIError {
+	return &bperrors.APIError{StatusCode: status, Code: string(code), Message: fmt.Sprintf(format, args...)}
+}
+
+// market resolves the metadata of a symbol without holding the engine lock,
+// since the source may have to fetch it.
+func (e *Engine) market(ctx context.Context, symbol string) (*types.Market, error) {
+	e.mu.Lock()
+	src := e.markets
+	e.mu.Unlock()
+
+	if src == nil {
+		return parseMarket(symbol)
+	}
+	return src.Market(ctx, symbol)
+}
+
+// Execute places a paper order.
+func (e *Engine) Execute(ctx context.Context, params types.ExecuteOrderParams) (*types.Order, error) {
+	m, err := e.market(ctx, params.Symbol)
+	if err != nil {
+		if errors.Is(err, errUnknownMarket) || errors.Is(err, market.ErrUnknownMarket) {
+			return nil, apiError(http.StatusBadRequest, bperrors.ErrCodeInvalidMarket, "market %q not found", params.Symbol)
+		}
+		return nil, err
+	}
+
+	e.mu.Lock()
+	o, updates, err := e.executeLocked(m, params)
+	e.queueLocked(updates)
+	e.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	e.flush()
+	return &o, nil
+}
+
+// ExecuteBatch places several paper orders, reporting errors per order.
+func (e *Engine) ExecuteBatch(ctx context.Context, orders []types.ExecuteOrderParams) []types.BatchOrderResult {
+	results := make([]types.BatchOrderResult, len(orders))
+	for i, params := range orders {
+		o, err := e.Execute(ctx, params)
+		if err != nil {
+			results[i].Error = err.Error()
+			if apiErr, ok := bperrors.IsAPIError(err); ok {
+				results[i].Error = apiErr.Message
+			}
+			continue
+		}
+		results[i].Order = o
+	}
+	return results
+}
+
+func (e *Engine) executeLocked(m *types.Market, p types.ExecuteOrderParams) (types.Order, []types.WSOrderUpdate, error) {
+	if err := market.Check(m, &p); err != nil {
+		code := bperrors.ErrCodeInvalidOrder
+		if errors.Is(err, market.ErrMarketClosed) || errors.Is(err, market.ErrCancelOnly) {
+			code = bperrors.ErrCodeTradingPaused
+		}
+		return types.Order{}, nil, apiError(http.StatusBadRequest, code, "%v", err)
+	}
+	if p.Side != enums.SideBid && p.Side != enums.SideAsk {
+		return types.Order{}, nil, apiError(http.StatusBadRequest, bperrors.ErrCodeInvalidOrder, "invalid side %q", p.Side)
+	}
+	if p.OrderType != enums.OrderTypeLimit && p.OrderType != enums.OrderTypeMarket {
+		return types.Order{}, nil, apiError(http.StatusBadRequest, bperrors.ErrCodeInvalidOrder, "invalid order type %q", p.OrderType)
+	}
+
+	o := &order{market: m, reduceOnly: p.ReduceOnly != nil && *p.ReduceOnly}
+	o.Order = types.Order{
+		CreatedAt:             types.TimeString(strconv.FormatInt(e.now().UnixMilli(), 10)),
+		OrderType:             p.OrderType,
+		SelfTradePrevention:   p.SelfTradePrevention,
+		Side:                  p.Side,
+		Symbol:                p.Symbol,
+		TimeInForce:           p.TimeInForce,
+		Price:                 p.Price,
+		Quantity:              p.Quantity,
+		QuoteQuantity:         p.QuoteQuantity,
+		TriggerPrice:          p.TriggerPrice,
+		TriggerBy:             p.TriggerBy,
+		TriggerQuantity:       p.TriggerQuantity,
+		PostOnly:              p.PostOnly != nil && *p.PostOnly,
+		ExecutedQuantity:      "0",
+		ExecutedQuoteQuantity: "0",
+	}
+	if o.SelfTradePrevention == "" {
+		o.SelfTradePrevention = enums.SelfTradePreventionRejectTaker
+	}
+	if o.TimeInForce == "" {
+		o.TimeInForce = enums.TimeInForceGTC
+	}
+	if p.ClientID != nil {
+		o.ClientID = *p.ClientID
+	}
+
+	// Check has validated the numbers; empty fields parse as zero.
+	o.price, _ = types.ParseDecimal(orZero(p.Price))
+	o.quantity, _ = types.ParseDecimal(orZero(p.Quantity))
+	o.quoteQuantity, _ = types.ParseDecimal(orZero(p.QuoteQuantity))
+	o.triggerPrice, _ = types.ParseDecimal(orZero(p.TriggerPrice))
+	switch {
+	case o.quantity.IsPositive():
+	case p.OrderType == enums.OrderTypeMarket && o.quoteQuantity.IsPositive():
+	case o.triggerPrice.IsPositive() && p.TriggerQuantity != "":
+	default:
+		return types.Order{}, nil, apiError(http.StatusBadRequest, bperrors.ErrCodeInvalidClientRequest, "quantity is required")
+	}
+
+	e.nextID++
+	o.ID = strconv.FormatInt(e.nextID, 10)
+
+	if o.triggerPrice.IsPositive() {
+		mark := e.markLocked(o.Symbol)
+		o.triggerAbove = mark.IsZero() || o.triggerPrice.GreaterThan(mark)
+		o.Status = enums.OrderStatusTriggerPending
+		e.orders = append(e.orders, o)
+		return o.Order, []types.WSOrderUpdate{o.update(e, "triggerPlaced")}, nil
+	}
+
+	updates, err := e.placeLocked(o)
+	if err != nil {
+		return types.Order{}, nil, err
+	}
+	e.orders = append(e.orders, o)
+	return o.Order, updates, nil
+}
+
+func orZero(s string) string {
+	if s == "" {
+		return "0"
+	}
+	return s
+}
+
+// fill is a planned execution against the book.
+type fill struct {
+	price    types.Decimal
+	quantity types.Decimal
+}
+
+// placeLocked executes a new or triggered order against the book, then
+// rests or expires the remainder.
+func (e *Engine) placeLocked(o *order) ([]types.WSOrderUpdate, error) {
+	if o.reduceOnly {
+		reducible := types.Decimal{}
+		if pos := e.positions[o.Symbol]; o.perp() && pos != nil {
+			if (o.Side == enums.SideAsk && pos.quantity.IsPositive()) || (o.Side == enums.SideBid && pos.quantity.IsNegative()) {
+				reducible = pos.quantity.Abs()
+			}
+		}
+		if !reducible.IsPositive() {
+			return e.expireLocked(o, enums.OrderExpiryReasonReduceOnlyNotReduced, "orderAccepted"), nil
+		}
+		if o.quantity.GreaterThan(reducible) {
+			o.quantity = reducible
+			o.Quantity = reducible.String()
+		}
+	}
+
+	fills, cost := e.planLocked(o)
+	if o.PostOnly && len(fills) > 0 {
+		return nil, apiError(http.StatusBadRequest, bperrors.ErrCodeInvalidOrder, "order would immediately match and take")
+	}
+	if o.TimeInForce == enums.TimeInForceFOK && (o.quantity.IsPositive() && sum(fills).LessThan(o.quantity) || len(fills) == 0) {
+		return e.expireLocked(o, enums.OrderExpiryReasonFillOrKill, "orderAccepted"), nil
+	}
+	if o.OrderType == enums.OrderTypeMarket && o.quantity.IsZero() {
+		// Quote quantity market orders are sized by what the book fills.
+		o.quantity = sum(fills)
+		o.Quantity = o.quantity.String()
+	}
+
+	rest := o.OrderType == enums.OrderTypeLimit && o.TimeInForce == enums.TimeInForceGTC
+	restQuantity := o.quantity.Sub(sum(fills))
+	if !o.perp() {
+		base, quote := e.balanceLocked(string(o.market.BaseSymbol)), e.balanceLocked(string(o.market.QuoteSymbol))
+		need, have := cost, quote.available
+		if o.Side == enums.SideAsk {
+			need, have = sum(fills), base.available
+		}
+		if rest {
+			if o.Side == enums.SideBid {
+				need = need.Add(restQuantity.Mul(o.price))
+			} else {
+				need = need.Add(restQuantity)
+			}
+		}
+		if have.LessThan(need) {
+			return nil, apiError(http.StatusBadRequest, bperrors.ErrCodeInsufficientFunds, "insufficient funds")
+		}
+	}
+
+	o.Status = enums.OrderStatusNew
+	updates := []types.WSOrderUpdate{o.update(e, "orderAccepted")}
+	b := e.books[o.Symbol]
+	for _, f := range fills {
+		updates = append(updates, e.fillLocked(o, f.quantity, f.price, false))
+		b.take(o.Side, f.price, f.quantity)
+	}
+
+	if !o.remaining().IsPositive() && o.executed.IsPositive() {
+		return updates, nil
+	}
+	switch {
+	case o.OrderType == enums.OrderTypeMarket:
+		return append(updates, e.expireLocked(o, enums.OrderExpiryReasonInsufficientLiquidity, "")...), nil
+	case !rest:
+		return append(updates, e.expireLocked(o, enums.OrderExpiryReasonImmediateOrCancel, "")...), nil
+	}
+
+	if !o.perp() {
+		asset, amount := string(o.market.BaseSymbol), o.remaining()
+		if o.Side == enums.SideBid {
+			asset, amount = string(o.market.QuoteSymbol), o.remaining().Mul(o.price)
+		}
+		bal := e.balanceLocked(asset)
+		bal.available = bal.available.Sub(amount)
+		bal.locked = bal.locked.Add(amount)
+		o.locked = amount
+	}
+	return updates, nil
+}
+
+// planLocked walks the opposite side of the book and returns the fills the
+// order would take, and their notional.
+func (e *Engine) planLocked(o *order) ([]fill, types.Decimal) {
+	b := e.books[o.Symbol]
+	if b == nil {
+		return nil, types.Decimal{}
+	}
+	levels := b.asks
+	if o.Side == enums.SideAsk {
+		levels = b.bids
+	}
+
+	step, _ := o.market.Filters.Quantity.StepSizeDecimal()
+	var fills []fill
+	var notional types.Decimal
+	remaining := o.remaining()
+	budget := o.quoteQuantity
+	for _, level := range levels {
+		if o.OrderType == enums.OrderTypeLimit {
+			if (o.Side == enums.SideBid && level.Price.GreaterThan(o.price)) || (o.Side == enums.SideAsk && level.Price.LessThan(o.price)) {
+				break
+			}
+		}
+		qty := level.Quantity
+		if o.quantity.IsPositive() {
+			if !remaining.IsPositive() {
+				break
+			}
+			qty = types.MinDecimal(qty, remaining)
+		} else {
+			if !budget.IsPositive() {
+				break
+			}
+			affordable := budget.Div(level.Price, 8)
+			if step.IsPositive() {
+				affordable = affordable.QuantizeDown(step)
+			}
+			qty = types.MinDecimal(qty, affordable)
+		}
+		if !qty.IsPositive() {
+			break
+		}
+		fills = append(fills, fill{price: level.Price, quantity: qty})
+		notional = notional.Add(qty.Mul(level.Price))
+		remaining = remaining.Sub(qty)
+		budget = budget.Sub(qty.Mul(level.Price))
+	}
+	return fills, notional
+}
+
+func sum(fills []fill) types.Decimal {
+	var total types.Decimal
+	for _, f := range fills {
+		total = total.Add(f.quantity)
+	}
+	return total
+}
+
+// take removes liquidity consumed by a paper order from the book.
+func (b *book) take(side enums.Side, price, quantity types.Decimal) {
+	levels := &b.asks
+	if side == enums.SideAsk {
+		levels = &b.bids
+	}
+	for i, level := range *levels {
+		if level.Price.Equal(price) {
+			level.Quantity = level.Quantity.Sub(quantity)
+			if level.Quantity.IsPositive() {
+				(*levels)[i] = level
+			} else {
+				*levels = append((*levels)[:i:i], (*levels)[i+1:]...)
+			}
+			return
+		}
+	}
+}
+
+// expireLocked expires an order, releasing its locked funds. If accepted is
+// set, the order is reported as accepted first.
+func (e *Engine) expireLocked(o *order, reason enums.OrderExpiryReason, accepted string) []types.WSOrderUpdate {
+	var updates []types.WSOrderUpdate
+	if accepted != "" {
+		o.Status = enums.OrderStatusNew
+		updates = append(updates, o.update(e, accepted))
+	}
+	e.releaseLocked(o)
+	o.Status = enums.OrderStatusExpired
+	o.ExpiryReason = reason
+	return append(updates, o.update(e, "orderExpired"))
+}
+
+// releaseLocked unlocks the funds held by a resting order.
+func (e *Engine) releaseLocked(o *order) {
+	if !o.locked.IsPositive() {
+		return
+	}
+	asset := string(o.market.BaseSymbol)
+	if o.Side == enums.SideBid {
+		asset = string(o.market.QuoteSymbol)
+	}
+	b := e.balanceLocked(asset)
+	b.locked = b.locked.Sub(o.locked)
+	b.available = b.available.Add(o.locked)
+	o.locked = types.Decimal{}
+}
+
+// fillLocked executes quantity of an order at price and settles balances,
+// fees and positions.
+func (e *Engine) fillLocked(o *order, quantity, price types.Decimal, maker bool) types.WSOrderUpdate {
+	notional := quantity.Mul(price)
+	rate := e.fees.SpotTaker
+	switch {
+	case o.perp() && maker:
+		rate = e.fees.FuturesMaker
+	case o.perp():
+		rate = e.fees.FuturesTaker
+	case maker:
+		rate = e.fees.SpotMaker
+	}
+	rate = rate.Div(bps, 8)
+
+	baseAsset, quoteAsset := string(o.market.BaseSymbol), string(o.market.QuoteSymbol)
+	quote := e.balanceLocked(quoteAsset)
+	var fee types.Decimal
+	feeAsset := quoteAsset
+
+	switch {
+	case o.perp():
+		fee = notional.Mul(rate)
+		quote.available = quote.available.Sub(fee)
+		e.applyPositionLocked(o, quantity, price)
+	case o.Side == enums.SideBid:
+		// Spot fees are charged in the received asset.
+		fee, feeAsset = quantity.Mul(rate), baseAsset
+		if maker {
+			quote.locked = quote.locked.Sub(quantity.Mul(o.price))
+			o.locked = o.locked.Sub(quantity.Mul(o.price))
+			quote.available = quote.available.Add(quantity.Mul(o.price.Sub(price)))
+		} else {
+			quote.available = quote.available.Sub(notional)
+		}
+		base := e.balanceLocked(baseAsset)
+		base.available = base.available.Add(quantity.Sub(fee))
+	default:
+		fee = notional.Mul(rate)
+		base := e.balanceLocked(baseAsset)
+		if maker {
+			base.locked = base.locked.Sub(quantity)
+			o.locked = o.locked.Sub(quantity)
+		} else {
+			base.available = base.available.Sub(quantity)
+		}
+		quote.available = quote.available.Add(notional.Sub(fee))
+	}
+
+	o.executed = o.executed.Add(quantity)
+	o.executedQuote = o.executedQuote.Add(notional)
+	o.ExecutedQuantity = o.executed.String()
+	o.ExecutedQuoteQuantity = o.executedQuote.String()
+	o.Status = enums.OrderStatusFilled
+	if o.remaining().IsPositive() {
+		o.Status = enums.OrderStatusPartiallyFilled
+	}
+
+	e.nextTrade++
+	f := types.Fill{
+		Fee:       fee.String(),
+		FeeSymbol: feeAsset,
+		IsMaker:   maker,
+		OrderID:   o.ID,
+		Price:     price.String(),
+		Quantity:  quantity.String(),
+		Side:      o.Side,
+		Symbol:    o.Symbol,
+		Timestamp: e.now().UTC().Format("2006-01-02T15:04:05.000"),
+		TradeID:   e.nextTrade,
+	}
+	if o.ClientID != 0 {
+		f.ClientID = strconv.FormatUint(uint64(o.ClientID), 10)
+	}
+	e.fills = append(e.fills, f)
+
+	u := o.update(e, "orderFill")
+	u.TradeID = &f.TradeID
+	u.FillQuantity = f.Quantity
+	u.FillPrice = f.Price
+	u.IsMaker = &maker
+	u.Fee = f.Fee
+	u.FeeSymbol = f.FeeSymbol
+	return u
+}
+
+// applyPositionLocked updates the net position of a perpetual market with a
+// fill, realizing PnL into the quote asset when it reduces the position.
+func (e *Engine) applyPositionLocked(o *order, quantity, price types.Decimal) {
+	pos := e.positions[o.Symbol]
+	if pos == nil {
+		pos = &position{id: o.ID}
+		e.positions[o.Symbol] = pos
+	}
+	signed := quantity
+	if o.Side == enums.SideAsk {
+		signed = quantity.Neg()
+	}
+
+	if pos.quantity.IsZero() || pos.quantity.Sign() == signed.Sign() {
+		size := pos.quantity.Abs()
+		pos.entry = pos.entry.Mul(size).Add(price.Mul(quantity)).Div(size.Add(quantity), 8)
+		pos.quantity = pos.quantity.Add(signed)
+		return
+	}
+
+	closed := types.MinDecimal(pos.quantity.Abs(), quantity)
+	pnl := price.Sub(pos.entry).Mul(closed)
+	if pos.quantity.IsNegative() {
+		pnl = pnl.Neg()
+	}
+	pos.realized = pos.realized.Add(pnl)
+	quote := e.balanceLocked(string(o.market.QuoteSymbol))
+	quote.available = quote.available.Add(pnl)
+
+	pos.quantity = pos.quantity.Add(signed)
+	switch {
+	case pos.quantity.IsZero():
+		pos.entry = types.Decimal{}
+	case pos.quantity.Sign() == signed.Sign():
+		// The fill flipped the position.
+		pos.entry = price
+	}
+}
+
+// matchRestingLocked fills resting orders of a symbol that the book has
+// moved through.
+func (e *Engine) matchRestingLocked(symbol string) []types.WSOrderUpdate {
+	b := e.books[symbol]
+	var updates []types.WSOrderUpdate
+	for _, o := range e.orders {
+		if o.Symbol != symbol || !o.resting() {
+			continue
+		}
+		levels := &b.asks
+		if o.Side == enums.SideAsk {
+			levels = &b.bids
+		}
+		for len(*levels) > 0 && o.remaining().IsPositive() {
+			level := (*levels)[0]
+			if (o.Side == enums.SideBid && level.Price.GreaterThan(o.price)) || (o.Side == enums.SideAsk && level.Price.LessThan(o.price)) {
+				break
+			}
+			qty := types.MinDecimal(level.Quantity, o.remaining())
+			updates = append(updates, e.fillLocked(o, qty, o.price, true))
+			b.take(o.Side, level.Price, qty)
+		}
+	}
+	return updates
+}
+
+// fillOnTradeLocked fills resting orders a public trade printed at or
+// through, in time priority, up to the trade quantity. Orders in skip are
+// left alone.
+func (e *Engine) fillOnTradeLocked(symbol string, price, quantity types.Decimal, skip map[*order]bool) []types.WSOrderUpdate {
+	var updates []types.WSOrderUpdate
+	for _, o := range e.orders {
+		if !quantity.IsPositive() {
+			break
+		}
+		if o.Symbol != symbol || !o.resting() || skip[o] {
+			continue
+		}
+		if (o.Side == enums.SideBid && price.GreaterThan(o.price)) || (o.Side == enums.SideAsk && price.LessThan(o.price)) {
+			continue
+		}
+		qty := types.MinDecimal(quantity, o.remaining())
+		updates = append(updates, e.fillLocked(o, qty, o.price, true))
+		quantity = quantity.Sub(qty)
+	}
+	return updates
+}
+
+// triggerLocked fires the trigger orders of a symbol crossed by price, and
+// returns the orders it fired.
+func (e *Engine) triggerLocked(symbol string, price types.Decimal) ([]types.WSOrderUpdate, map[*order]bool) {
+	var updates []types.WSOrderUpdate
+	triggered := make(map[*order]bool)
+	for _, o := range e.orders {
+		if o.Symbol != symbol || o.Status != enums.OrderStatusTriggerPending {
+			continue
+		}
+		if o.triggerAbove && price.LessThan(o.triggerPrice) || !o.triggerAbove && price.GreaterThan(o.triggerPrice) {
+			continue
+		}
+
+		if o.quantity.IsZero() && o.TriggerQuantity != "" {
+			o.quantity, _ = types.ParseDecimal(o.TriggerQuantity)
+			o.Quantity = o.TriggerQuantity
+		}
+		o.Status = enums.OrderStatusNew
+		triggered[o] = true
+		updates = append(updates, o.update(e, "triggered"))
+
+		placed, err := e.placeLocked(o)
+		if err != nil {
+			o.Status = enums.OrderStatusTriggerFailed
+			updates = append(updates, o.update(e, "triggerFailed"))
+			continue
+		}
+		// placeLocked reports acceptance again; the trigger already did.
+		updates = append(updates, placed[1:]...)
+	}
+	return updates, triggered
+}
+
+// resting reports whether an order is a limit order waiting on the book.
+func (o *order) resting() bool {
+	return o.OrderType == enums.OrderTypeLimit &&
+		(o.Status == enums.OrderStatusNew || o.Status == enums.OrderStatusPartiallyFilled)
+}
+
+// update describes the current state of an order as an order update event.
+func (o *order) update(e *Engine, event string) types.WSOrderUpdate {
+	now := e.now().UnixMicro()
+	u := types.WSOrderUpdate{
+		Event:                 event,
+		EventTime:             now,
+		Symbol:                o.Symbol,
+		Side:                  o.Side,
+		OrderType:             string(o.OrderType),
+		TimeInForce:           string(o.TimeInForce),
+		Quantity:              o.Quantity,
+		QuoteQuantity:         o.QuoteQuantity,
+		Price:                 o.Price,
+		TriggerPrice:          o.TriggerPrice,
+		TriggerQuantity:       o.TriggerQuantity,
+		Status:                o.Status,
+		ExpiryReason:          o.ExpiryReason,
+		OrderID:               types.WSID(o.ID),
+		ExecutedQuantity:      o.ExecutedQuantity,
+		ExecutedQuoteQuantity: o.ExecutedQuoteQuantity,
+		SelfTradePrevention:   o.SelfTradePrevention,
+		EngineTimestamp:       now,
+	}
+	if o.ClientID != 0 {
+		clientID := o.ClientID
+		u.ClientID = &clientID
+	}
+	if o.PostOnly {
+		postOnly := true
+		u.PostOnly = &postOnly
+	}
+	if o.reduceOnly {
+		reduceOnly := true
+		u.ReduceOnly = &reduceOnly
+	}
+	return u
+}
+
+// findLocked returns the order of a symbol with orderID, or clientID if
+// orderID is empty.
+func (e *Engine) findLocked(symbol, orderID string, clientID *uint32) *order {
+	for i := len(e.orders) - 1; i >= 0; i-- {
+		o := e.orders[i]
+		if o.Symbol != symbol {
+			continue
+		}
+		if orderID != "" && o.ID == orderID {
+			return o
+		}
+		if orderID == "" && clientID != nil && o.ClientID == *clientID {
+			return o
+		}
+	}
+	return nil
+}
+
+// Order returns an open order by ID, or by client ID if orderID is empty.
+func (e *Engine) Order(symbol, orderID string, clientID *uint32) (*types.Order, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	o := e.findLocked(symbol, orderID, clientID)
+	if o == nil || !o.open() {
+		return nil, apiError(http.StatusNotFound, bperrors.ErrCodeResourceNotFound, "order not found")
+	}
+	result := o.Order
+	return &result, nil
+}
+
+// Cancel cancels an open order by ID, or by client ID if orderID is empty.
+func (e *Engine) Cancel(symbol, orderID string, clientID *uint32) (*types.Order, error) {
+	e.mu.Lock()
+	o := e.findLocked(symbol, orderID, clientID)
+	if o == nil || !o.open() {
+		e.mu.Unlock()
+		return nil, apiError(http.StatusNotFound, bperrors.ErrCodeResourceNotFound, "order not found")
+	}
+	e.queueLocked([]types.WSOrderUpdate{e.cancelLocked(o)})
+	result := o.Order
+	e.mu.Unlock()
+
+	e.flush()
+	return &result, nil
+}
+
+// CancelAll cancels the open orders of a symbol. orderType restricts it to
+// resting or conditional orders; empty cancels both.
+func (e *Engine) CancelAll(symbol string, orderType enums.CancelOrderType) []types.Order {
+	e.mu.Lock()
+	cancelled := []types.Order{}
+	var updates []types.WSOrderUpdate
+	for _, o := range e.orders {
+		if o.Symbol != symbol || !o.open() {
+			continue
+		}
+		conditional := o.Status == enums.OrderStatusTriggerPending
+		if (orderType == enums.CancelOrderTypeResting && conditional) || (orderType == enums.CancelOrderTypeConditional && !conditional) {
+			continue
+		}
+		updates = append(updates, e.cancelLocked(o))
+		cancelled = append(cancelled, o.Order)
+	}
+	e.queueLocked(updates)
+	e.mu.Unlock()
+
+	e.flush()
+	return cancelled
+}
+
+func (e *Engine) cancelLocked(o *order) types.WSOrderUpdate {
+	e.releaseLocked(o)
+	o.Status = enums.OrderStatusCancelled
+	return o.update(e, "orderCancelled")
+}
+
+// OrderHistory returns every order of a symbol, or of all symbols if symbol
+// is empty, oldest first.
+func (e *Engine) OrderHistory(symbol string) []types.Order {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	orders := []types.Order{}
+	for _, o := range e.orders {
+		if symbol == "" || o.Symbol == symbol {
+			orders = append(orders, o.Order)
+		}
+	}
+	return orders
+}
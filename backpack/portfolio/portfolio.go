@@ -0,0 +1,542 @@
+// Package portfolio keeps a live view of an account's positions, balances
+// and collateral from REST snapshots and the WebSocket API.
+package portfolio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/services"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/types"
+)
+
+// DefaultInterval is the default interval between reconciliations.
+const DefaultInterval = 30 * time.Second
+
+// PositionSource lists open positions. *services.PositionsService
+// implements PositionSource.
+type PositionSource interface {
+	GetPositions(ctx context.Context, params *services.GetPositionsParams) ([]types.Position, error)
+}
+
+// CapitalSource provides balances and collateral. *services.CapitalService
+// implements CapitalSource.
+type CapitalSource interface {
+	GetBalances(ctx context.Context) (types.Balances, error)
+	GetCollateral(ctx context.Context, params *services.GetCollateralParams) (*types.MarginAccountSummary, error)
+}
+
+// StreamSubscriber subscribes to position and mark price updates.
+// *websocket.Handler implements StreamSubscriber.
+type StreamSubscriber interface {
+	OnPositionUpdate(symbol string, callback func(*types.WSPositionUpdate)) error
+	OnMarkPrice(symbol string, callback func(*types.WSMarkPrice)) error
+}
+
+// ChangeType identifies what changed in the portfolio.
+type ChangeType string
+
+const (
+	ChangePosition   ChangeType = "position"   // A position update was received
+	ChangeMark       ChangeType = "mark"       // A position was re-marked
+	ChangeBalances   ChangeType = "balances"   // Balances were reconciled
+	ChangeCollateral ChangeType = "collateral" // Collateral was reconciled
+)
+
+// Change describes a change of the portfolio.
+type Change struct {
+	Type ChangeType
+	// Symbol is the market of a position or mark change.
+	Symbol string
+	// Position is the new state of the position for position and mark
+	// changes. It is nil if the position was closed.
+	Position *types.Position
+}
+
+// Snapshot is a consistent copy of the portfolio.
+type Snapshot struct {
+	// Positions are the open positions, sorted by symbol.
+	Positions []types.Position
+	Balances  types.Balances
+	// Collateral is the latest margin account summary from REST, or nil if
+	// it has not been loaded.
+	Collateral *types.MarginAccountSummary
+	// NetEquity is the net equity of the collateral summary adjusted for
+	// the change of PnL reported by position and mark updates since it was
+	// loaded.
+	NetEquity types.Decimal
+	// MarginFraction is NetEquity over the notional of the open positions.
+	// It is nil without positions or before collateral has been loaded,
+	// since NetEquity is unknown then.
+	MarginFraction *types.Decimal
+	UpdatedAt      time.Time
+}
+
+// Tracker combines REST snapshots of positions, balances and collateral
+// with position and mark price updates into an always-current view of the
+// account. Positions are re-marked as mark prices arrive, and the whole
+// portfolio is reconciled with REST periodically and after reconnects.
+// Tracker is safe for concurrent use.
+type Tracker struct {
+	positionSource PositionSource
+	capitalSource  CapitalSource
+	interval       time.Duration
+	onError        func(error)
+	now            func() time.Time
+
+	mu         sync.Mutex
+	positions  map[string]*types.Position
+	balances   types.Balances
+	collateral *types.MarginAccountSummary
+	// drift is the change of PnL from position and mark updates since the
+	// collateral summary was loaded.
+	drift     types.Decimal
+	updatedAt time.Time
+	// touched and remarked are when the position of each symbol last
+	// changed from a position or mark price update, so that reconciliation
+	// does not roll back updates newer than its snapshot.
+	touched   map[string]time.Time
+	remarked  map[string]time.Time
+	stream    StreamSubscriber
+	marked    map[string]bool // Symbols subscribed to mark prices
+	resync    chan struct{}
+	subscribe chan struct{}
+	cancel    context.CancelFunc
+	done      chan struct{}
+
+	listenersMu sync.RWMutex
+	onChange    []func(Change)
+}
+
+// Option is a functional option for configuring a Tracker.
+type Option func(*Tracker)
+
+// WithInterval sets the interval between reconciliations with REST. Zero
+// disables periodic reconciliation.
+func WithInterval(interval time.Duration) Option {
+	return func(t *Tracker) {
+		t.interval = interval
+	}
+}
+
+// WithOnError sets a callback invoked when reconciliation or a mark price
+// subscription fails.
+func WithOnError(fn func(error)) Option {
+	return func(t *Tracker) {
+		t.onError = fn
+	}
+}
+
+// New creates a new Tracker loading positions and capital from REST.
+func New(positions PositionSource, capital CapitalSource, opts ...Option) *Tracker {
+	t := &Tracker{
+		positionSource: positions,
+		capitalSource:  capital,
+		interval:       DefaultInterval,
+		now:            time.Now,
+		positions:      make(map[string]*types.Position),
+		balances:       make(types.Balances),
+		marked:         make(map[string]bool),
+		touched:        make(map[string]time.Time),
+		remarked:       make(map[string]time.Time),
+		resync:         make(chan struct{}, 1),
+		subscribe:      make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// OnChange registers a listener invoked after the portfolio changes.
+// Listeners are called without the tracker's lock held, so they may read
+// the tracker.
+func (t *Tracker) OnChange(fn func(Change)) {
+	t.listenersMu.Lock()
+	t.onChange = append(t.onChange, fn)
+	t.listenersMu.Unlock()
+}
+
+// Subscribe feeds the tracker from the position update stream and the mark
+// price streams of its positions. Mark prices of positions opened later are
+// subscribed to by the background loop of Start.
+func (t *Tracker) Subscribe(stream StreamSubscriber) error {
+	if err := stream.OnPositionUpdate("", t.HandlePositionUpdate); err != nil {
+		return err
+	}
+	t.mu.Lock()
+	t.stream = stream
+	t.mu.Unlock()
+	return t.subscribeMarks()
+}
+
+// Start reconciles with REST in the background, first immediately, then
+// at the configured interval and whenever HandleReconnect is called, until
+// Stop is called or ctx is done.
+func (t *Tracker) Start(ctx context.Context) {
+	t.mu.Lock()
+	if t.cancel != nil {
+		t.mu.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	t.cancel = cancel
+	t.done = make(chan struct{})
+	done := t.done
+	t.mu.Unlock()
+
+	t.requestResync()
+	go func() {
+		defer close(done)
+		t.loop(ctx)
+	}()
+}
+
+// Stop stops the background loop started by Start.
+func (t *Tracker) Stop() {
+	t.mu.Lock()
+	cancel, done := t.cancel, t.done
+	t.cancel, t.done = nil, nil
+	t.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// HandleReconnect schedules a reconciliation. It can be passed directly to
+// websocket.Client.OnReconnect.
+func (t *Tracker) HandleReconnect(attempt int) {
+	t.requestResync()
+}
+
+// HandlePositionUpdate applies a position update. It can be passed
+// directly to websocket.Handler.OnPositionUpdate.
+func (t *Tracker) HandlePositionUpdate(u *types.WSPositionUpdate) {
+	if u.Symbol == "" {
+		return
+	}
+
+	t.mu.Lock()
+	p := t.positions[u.Symbol]
+	if p == nil {
+		p = &types.Position{Symbol: u.Symbol}
+	}
+	before := pnl(p)
+	setString(&p.BreakEvenPrice, u.BreakEvenPrice)
+	setString(&p.EntryPrice, u.EntryPrice)
+	setString(&p.IMF, u.IMF)
+	setString(&p.MarkPrice, u.MarkPrice)
+	setString(&p.MMF, u.MMF)
+	setString(&p.NetQuantity, u.NetQuantity)
+	setString(&p.NetExposureQuantity, u.NetExposureQuantity)
+	setString(&p.NetExposureNotional, u.NetExposureNotional)
+	setString(&p.PositionID, string(u.PositionID))
+	setString(&p.PnlRealized, u.PnlRealized)
+	setString(&p.PnlUnrealized, u.PnlUnrealized)
+	t.drift = t.drift.Add(pnl(p).Sub(before))
+
+	var position *types.Position
+	if quantity, _ := p.NetQuantityDecimal(); quantity.IsZero() {
+		delete(t.positions, u.Symbol)
+	} else {
+		t.positions[u.Symbol] = p
+		c := *p
+		position = &c
+	}
+	subscribe := position != nil && t.stream != nil && !t.marked[u.Symbol]
+	t.updatedAt = t.now()
+	t.touched[u.Symbol] = t.updatedAt
+	t.mu.Unlock()
+
+	if subscribe {
+		select {
+		case t.subscribe <- struct{}{}:
+		default:
+		}
+	}
+	t.emit(Change{Type: ChangePosition, Symbol: u.Symbol, Position: position})
+}
+
+// HandleMarkPrice re-marks the position of the mark price's market. It can
+// be passed directly to websocket.Handler.OnMarkPrice.
+func (t *Tracker) HandleMarkPrice(mp *types.WSMarkPrice) {
+	mark, err := types.ParseDecimal(mp.MarkPrice)
+	if err != nil {
+		return
+	}
+
+	t.mu.Lock()
+	p := t.positions[mp.Symbol]
+	if p == nil {
+		t.mu.Unlock()
+		return
+	}
+	before := pnl(p)
+	remark(p, mark)
+	t.drift = t.drift.Add(pnl(p).Sub(before))
+	c := *p
+	t.updatedAt = t.now()
+	t.remarked[mp.Symbol] = t.updatedAt
+	t.mu.Unlock()
+
+	t.emit(Change{Type: ChangeMark, Symbol: mp.Symbol, Position: &c})
+}
+
+// remark updates the mark price and the values derived from it.
+func remark(p *types.Position, mark types.Decimal) {
+	quantity, _ := p.NetQuantityDecimal()
+	entry, _ := p.EntryPriceDecimal()
+	p.MarkPrice = mark.String()
+	p.NetExposureNotional = quantity.Abs().Mul(mark).String()
+	p.PnlUnrealized = mark.Sub(entry).Mul(quantity).String()
+}
+
+// Reconcile replaces the portfolio with REST snapshots of positions,
+// balances and collateral. Parts that load successfully are applied even
+// if others fail. Positions updated from the stream after the snapshots
+// were requested are kept, and positions re-marked since keep their newer
+// mark price, as the snapshots may predate the updates.
+func (t *Tracker) Reconcile(ctx context.Context) error {
+	t.mu.Lock()
+	started := t.now()
+	drift := t.drift
+	t.mu.Unlock()
+
+	var errs []error
+	positions, err := t.positionSource.GetPositions(ctx, nil)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("portfolio: failed to get positions: %w", err))
+	}
+	balances, err := t.capitalSource.GetBalances(ctx)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("portfolio: failed to get balances: %w", err))
+	}
+	collateral, err := t.capitalSource.GetCollateral(ctx, nil)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("portfolio: failed to get collateral: %w", err))
+	}
+
+	var changes []Change
+	t.mu.Lock()
+	if positions != nil {
+		current := make(map[string]*types.Position, len(positions))
+		for symbol, p := range t.positions {
+			if after(t.touched, symbol, started) {
+				current[symbol] = p
+			}
+		}
+		for i := range positions {
+			p := positions[i]
+			if after(t.touched, p.Symbol, started) {
+				continue
+			}
+			if quantity, _ := p.NetQuantityDecimal(); quantity.IsZero() {
+				continue
+			}
+			old := t.positions[p.Symbol]
+			if old != nil && after(t.remarked, p.Symbol, started) {
+				if mark, err := old.MarkPriceDecimal(); err == nil {
+					remark(&p, mark)
+				}
+			}
+			current[p.Symbol] = &p
+			if old == nil || *old != p {
+				c := p
+				changes = append(changes, Change{Type: ChangePosition, Symbol: p.Symbol, Position: &c})
+			}
+		}
+		for symbol := range t.positions {
+			if current[symbol] == nil {
+				changes = append(changes, Change{Type: ChangePosition, Symbol: symbol})
+			}
+		}
+		t.positions = current
+		forget(t.touched, started)
+		forget(t.remarked, started)
+	}
+	if balances != nil {
+		t.balances = balances
+		changes = append(changes, Change{Type: ChangeBalances})
+	}
+	if collateral != nil {
+		t.collateral = collateral
+		// Keep the drift of updates received since the request started.
+		t.drift = t.drift.Sub(drift)
+		changes = append(changes, Change{Type: ChangeCollateral})
+	}
+	subscribe := t.stream != nil
+	t.updatedAt = t.now()
+	t.mu.Unlock()
+
+	if subscribe {
+		if err := t.subscribeMarks(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, c := range changes {
+		t.emit(c)
+	}
+	return errors.Join(errs...)
+}
+
+// Snapshot returns a copy of the portfolio.
+func (t *Tracker) Snapshot() *Snapshot {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := &Snapshot{
+		Positions: make([]types.Position, 0, len(t.positions)),
+		Balances:  make(types.Balances, len(t.balances)),
+		UpdatedAt: t.updatedAt,
+	}
+	var notional types.Decimal
+	for _, p := range t.positions {
+		s.Positions = append(s.Positions, *p)
+		n, _ := p.NetExposureNotionalDecimal()
+		notional = notional.Add(n.Abs())
+	}
+	sort.Slice(s.Positions, func(i, j int) bool { return s.Positions[i].Symbol < s.Positions[j].Symbol })
+	for asset, b := range t.balances {
+		s.Balances[asset] = b
+	}
+	if t.collateral != nil {
+		c := *t.collateral
+		c.Collateral = append([]types.CollateralItem(nil), t.collateral.Collateral...)
+		s.Collateral = &c
+		equity, _ := types.ParseDecimal(orZero(c.NetEquity))
+		s.NetEquity = equity.Add(t.drift)
+	}
+	if notional.IsPositive() && s.Collateral != nil {
+		fraction := s.NetEquity.Div(notional, 8)
+		s.MarginFraction = &fraction
+	}
+	return s
+}
+
+// Position returns the open position of a market.
+func (t *Tracker) Position(symbol string) (types.Position, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p := t.positions[symbol]
+	if p == nil {
+		return types.Position{}, false
+	}
+	return *p, true
+}
+
+// Balance returns the balance of an asset.
+func (t *Tracker) Balance(asset string) (types.Balance, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b, ok := t.balances[asset]
+	return b, ok
+}
+
+// after reports whether symbol was updated at or after started.
+func after(updated map[string]time.Time, symbol string, started time.Time) bool {
+	at, ok := updated[symbol]
+	return ok && !at.Before(started)
+}
+
+// forget removes the update times before started.
+func forget(updated map[string]time.Time, started time.Time) {
+	for symbol, at := range updated {
+		if at.Before(started) {
+			delete(updated, symbol)
+		}
+	}
+}
+
+// pnl returns the realized and unrealized PnL of a position.
+func pnl(p *types.Position) types.Decimal {
+	realized, _ := p.PnlRealizedDecimal()
+	unrealized, _ := p.PnlUnrealizedDecimal()
+	return realized.Add(unrealized)
+}
+
+// subscribeMarks subscribes to the mark prices of positions that are not
+// subscribed yet.
+func (t *Tracker) subscribeMarks() error {
+	t.mu.Lock()
+	stream := t.stream
+	var symbols []string
+	for symbol := range t.positions {
+		if !t.marked[symbol] {
+			t.marked[symbol] = true
+			symbols = append(symbols, symbol)
+		}
+	}
+	t.mu.Unlock()
+
+	var errs []error
+	for _, symbol := range symbols {
+		if err := stream.OnMarkPrice(symbol, t.HandleMarkPrice); err != nil {
+			t.mu.Lock()
+			delete(t.marked, symbol)
+			t.mu.Unlock()
+			errs = append(errs, fmt.Errorf("portfolio: failed to subscribe to %s mark price: %w", symbol, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (t *Tracker) loop(ctx context.Context) {
+	var tick <-chan time.Time
+	if t.interval > 0 {
+		ticker := time.NewTicker(t.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.resync:
+			err = t.Reconcile(ctx)
+		case <-tick:
+			err = t.Reconcile(ctx)
+		case <-t.subscribe:
+			err = t.subscribeMarks()
+		}
+		if err != nil && ctx.Err() == nil && t.onError != nil {
+			t.onError(err)
+		}
+	}
+}
+
+func (t *Tracker) requestResync() {
+	select {
+	case t.resync <- struct{}{}:
+	default:
+	}
+}
+
+func (t *Tracker) emit(c Change) {
+	t.listenersMu.RLock()
+	listeners := t.onChange
+	t.listenersMu.RUnlock()
+	for _, fn := range listeners {
+		fn(c)
+	}
+}
+
+// setString overwrites a field with a non-empty value.
+func setString(field *string, value string) {
+	if value != "" {
+		*field = value
+	}
+}
+
+func orZero(s string) string {
+	if s == "" {
+		return "0"
+	}
+	return s
+}
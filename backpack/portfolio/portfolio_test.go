@@ -0,0 +1,99 @@
+package portfolio
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/services"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/types"
+)
+
+// fakeSource serves positions and capital, calling during, if set, while
+// positions are being requested.
+type fakeSource struct {
+	positions  []types.Position
+	collateral *types.MarginAccountSummary
+	during     func()
+}
+
+func (f *fakeSource) GetPositions(ctx context.Context, params *services.GetPositionsParams) ([]types.Position, error) {
+	if f.during != nil {
+		f.during()
+	}
+	return f.positions, nil
+}
+
+func (f *fakeSource) GetBalances(ctx context.Context) (types.Balances, error) {
+	return types.Balances{}, nil
+}
+
+func (f *fakeSource) GetCollateral(ctx context.Context, params *services.GetCollateralParams) (*types.MarginAccountSummary, error) {
+	if f.collateral == nil {
+		return nil, errors.New("unavailable")
+	}
+	return f.collateral, nil
+}
+
+func TestReconcileKeepsNewerUpdates(t *testing.T) {
+	src := &fakeSource{
+		positions: []types.Position{
+			{Symbol: "SOL_USDC_PERP", NetQuantity: "1", EntryPrice: "100", MarkPrice: "100", NetExposureNotional: "100", PnlUnrealized: "0"},
+			{Symbol: "BTC_USDC_PERP", NetQuantity: "0.1", EntryPrice: "50000", MarkPrice: "50000", NetExposureNotional: "5000", PnlUnrealized: "0"},
+		},
+		collateral: &types.MarginAccountSummary{NetEquity: "1000"},
+	}
+	tr := New(src, src)
+	if err := tr.Reconcile(context.Background()); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+
+	// Updates arriving while the next snapshot is requested are newer than
+	// the snapshot.
+	src.during = func() {
+		tr.HandlePositionUpdate(&types.WSPositionUpdate{Symbol: "SOL_USDC_PERP", NetQuantity: "2"})
+		tr.HandleMarkPrice(&types.WSMarkPrice{Symbol: "BTC_USDC_PERP", MarkPrice: "51000"})
+	}
+	if err := tr.Reconcile(context.Background()); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if p, _ := tr.Position("SOL_USDC_PERP"); p.NetQuantity != "2" {
+		t.Errorf("SOL position quantity = %s, want 2 from the stream", p.NetQuantity)
+	}
+	if p, _ := tr.Position("BTC_USDC_PERP"); p.MarkPrice != "51000" || !types.MustParseDecimal(p.PnlUnrealized).Equal(types.MustParseDecimal("100")) {
+		t.Errorf("BTC position marked %s with PnL %s, want 51000 and 100", p.MarkPrice, p.PnlUnrealized)
+	}
+	if got := tr.Snapshot().NetEquity; !got.Equal(types.MustParseDecimal("1100")) {
+		t.Errorf("NetEquity = %s, want 1100", got)
+	}
+
+	// Older updates do not survive the next reconciliation.
+	src.during = nil
+	if err := tr.Reconcile(context.Background()); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if p, _ := tr.Position("SOL_USDC_PERP"); p.NetQuantity != "1" {
+		t.Errorf("SOL position quantity = %s, want 1 from REST", p.NetQuantity)
+	}
+}
+
+func TestMarginFractionNeedsCollateral(t *testing.T) {
+	src := &fakeSource{
+		positions: []types.Position{{Symbol: "SOL_USDC_PERP", NetQuantity: "1", NetExposureNotional: "100"}},
+	}
+	tr := New(src, src)
+	if err := tr.Reconcile(context.Background()); err == nil {
+		t.Fatal("Reconcile succeeded without collateral")
+	}
+	if s := tr.Snapshot(); s.MarginFraction != nil {
+		t.Errorf("MarginFraction = %s without collateral, want nil", s.MarginFraction)
+	}
+
+	src.collateral = &types.MarginAccountSummary{NetEquity: "50"}
+	if err := tr.Reconcile(context.Background()); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if s := tr.Snapshot(); s.MarginFraction == nil || !s.MarginFraction.Equal(types.MustParseDecimal("0.5")) {
+		t.Errorf("MarginFraction = %v, want 0.5", s.MarginFraction)
+	}
+}
@@ -0,0 +1,260 @@
+// Package ratelimit provides a client-side token bucket rate limiter for Backpack Exchange API requests.
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Group identifies a class of REST endpoints that share a rate limit.
+type Group int
+
+const (
+	// GroupPublic covers unauthenticated market data endpoints.
+	GroupPublic Group = iota
+	// GroupQuery covers authenticated queries such as account, capital and history.
+	GroupQuery
+	// GroupOrder covers order entry and cancellation.
+	GroupOrder
+)
+
+// String returns the name of the group.
+func (g Group) String() string {
+	switch g {
+	case GroupPublic:
+		return "public"
+	case GroupQuery:
+		return "query"
+	case GroupOrder:
+		return "order"
+	default:
+		return "unknown"
+	}
+}
+
+// Limit configures a token bucket. A zero Rate means unlimited.
+type Limit struct {
+	Rate  float64 // Tokens added per second
+	Burst int     // Maximum number of tokens; defaults to 1
+}
+
+// Config configures a Limiter.
+type Config struct {
+	// Global is shared by every group and models the exchange-wide limit.
+	Global Limit
+	// Groups holds per-group limits. Groups without an entry are only
+	// subject to the global limit.
+	Groups map[Group]Limit
+	// Priorities decides which group is served first when several groups
+	// wait on the global limit. Higher values go first.
+	Priorities map[Group]int
+}
+
+// DefaultConfig returns a configuration that keeps order entry responsive
+// while throttling bulk queries.
+func DefaultConfig() Config {
+	return Config{
+		Global: Limit{Rate: 20, Burst: 20},
+		Groups: map[Group]Limit{
+			GroupPublic: {Rate: 10, Burst: 20},
+			GroupQuery:  {Rate: 5, Burst: 10},
+		},
+		Priorities: map[Group]int{
+			GroupOrder:  2,
+			GroupPublic: 1,
+			GroupQuery:  0,
+		},
+	}
+}
+
+// Limiter is a token bucket rate limiter shared by all services of a client.
+// It is safe for concurrent use.
+type Limiter struct {
+	mu       sync.Mutex
+	global   *bucket
+	groups   map[Group]*bucket
+	priority map[Group]int
+	waiting  map[Group]int
+	wake     chan struct{}
+}
+
+// New creates a new Limiter from the given configuration.
+func New(cfg Config) *Limiter {
+	now := time.Now()
+	l := &Limiter{
+		global:   newBucket(cfg.Global, now),
+		groups:   make(map[Group]*bucket, len(cfg.Groups)),
+		priority: make(map[Group]int, len(cfg.Priorities)),
+		waiting:  make(map[Group]int),
+		wake:     make(chan struct{}),
+	}
+	for g, limit := range cfg.Groups {
+		if b := newBucket(limit, now); b != nil {
+			l.groups[g] = b
+		}
+	}
+	for g, p := range cfg.Priorities {
+		l.priority[g] = p
+	}
+	return l
+}
+
+// Wait blocks until a request in the given group may proceed or ctx is done.
+func (l *Limiter) Wait(ctx context.Context, g Group) error {
+	l.mu.Lock()
+	l.waiting[g]++
+	defer func() {
+		l.waiting[g]--
+		l.broadcastLocked()
+		l.mu.Unlock()
+	}()
+
+	for {
+		now := time.Now()
+		delay := l.delayLocked(g, now)
+		if delay == 0 && !l.preemptedLocked(g, now) {
+			l.takeLocked(g)
+			return nil
+		}
+
+		// If the tokens are available but a higher priority group is ready
+		// to use the next global token, there is no timer: the wait ends
+		// when that group has been served, as every Wait wakes the others
+		// on return.
+		var timer *time.Timer
+		var expired <-chan time.Time
+		if delay > 0 {
+			timer = time.NewTimer(delay)
+			expired = timer.C
+		}
+		wake := l.wake
+		l.mu.Unlock()
+		select {
+		case <-ctx.Done():
+		case <-expired:
+		case <-wake:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		l.mu.Lock()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+// WaitTime estimates how long a request in the given group would wait if it
+// were issued now, taking already queued requests into account.
+func (l *Limiter) WaitTime(g Group) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	var wait time.Duration
+	if b := l.groups[g]; b != nil {
+		wait = b.delay(now, float64(l.waiting[g]+1))
+	}
+	if l.global != nil {
+		ahead := 0
+		for group, n := range l.waiting {
+			if l.priority[group] >= l.priority[g] {
+				ahead += n
+			}
+		}
+		if d := l.global.delay(now, float64(ahead+1)); d > wait {
+			wait = d
+		}
+	}
+	return wait
+}
+
+// Waiting returns the number of requests currently queued in the given group.
+func (l *Limiter) Waiting(g Group) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.waiting[g]
+}
+
+func (l *Limiter) delayLocked(g Group, now time.Time) time.Duration {
+	var delay time.Duration
+	if b := l.groups[g]; b != nil {
+		delay = b.delay(now, 1)
+	}
+	if l.global != nil {
+		if d := l.global.delay(now, 1); d > delay {
+			delay = d
+		}
+	}
+	return delay
+}
+
+// preemptedLocked reports whether a waiting group with a higher priority is
+// ready to proceed and should receive the next global token instead.
+func (l *Limiter) preemptedLocked(g Group, now time.Time) bool {
+	if l.global == nil {
+		return false
+	}
+	for other, n := range l.waiting {
+		if n == 0 || other == g || l.priority[other] <= l.priority[g] {
+			continue
+		}
+		if b := l.groups[other]; b == nil || b.delay(now, 1) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *Limiter) takeLocked(g Group) {
+	if b := l.groups[g]; b != nil {
+		b.tokens--
+	}
+	if l.global != nil {
+		l.global.tokens--
+	}
+}
+
+func (l *Limiter) broadcastLocked() {
+	close(l.wake)
+	l.wake = make(chan struct{})
+}
+
+type bucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newBucket(limit Limit, now time.Time) *bucket {
+	if limit.Rate <= 0 {
+		return nil
+	}
+	burst := float64(limit.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+	return &bucket{
+		rate:   limit.Rate,
+		burst:  burst,
+		tokens: burst,
+		last:   now,
+	}
+}
+
+// delay refills the bucket and returns the time until it holds n tokens.
+func (b *bucket) delay(now time.Time, n float64) time.Duration {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+	}
+	if b.tokens >= n {
+		return 0
+	}
+	return time.Duration((n - b.tokens) / b.rate * float64(time.Second))
+}
@@ -0,0 +1,135 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// blocked reports whether Wait would block for at least 20ms.
+func blocked(t *testing.T, l *Limiter, g Group) bool {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := l.Wait(ctx, g)
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait(%s): %v", g, err)
+	}
+	return err != nil
+}
+
+func TestBucketRefill(t *testing.T) {
+	l := New(Config{Global: Limit{Rate: 100, Burst: 5}})
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if err := l.Wait(context.Background(), GroupOrder); err != nil {
+			t.Fatalf("Wait: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Errorf("burst took %v, want no wait", elapsed)
+	}
+
+	// The next 5 tokens come in at 10ms intervals.
+	start = time.Now()
+	for i := 0; i < 5; i++ {
+		if err := l.Wait(context.Background(), GroupOrder); err != nil {
+			t.Fatalf("Wait: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 45*time.Millisecond || elapsed > time.Second {
+		t.Errorf("5 tokens at 100/s took %v, want 50ms", elapsed)
+	}
+}
+
+func TestGroupAndGlobalLimits(t *testing.T) {
+	l := New(Config{
+		Global: Limit{Rate: 0.1, Burst: 3},
+		Groups: map[Group]Limit{GroupQuery: {Rate: 0.1, Burst: 1}},
+	})
+
+	if blocked(t, l, GroupQuery) {
+		t.Fatal("first query blocked")
+	}
+	if !blocked(t, l, GroupQuery) {
+		t.Error("second query passed its group limit")
+	}
+	// Other groups only share the global limit.
+	if blocked(t, l, GroupOrder) || blocked(t, l, GroupOrder) {
+		t.Fatal("orders blocked by the query limit")
+	}
+	if !blocked(t, l, GroupOrder) {
+		t.Error("order passed the global limit")
+	}
+	if w := l.WaitTime(GroupOrder); w < 9*time.Second {
+		t.Errorf("WaitTime = %v, want about 10s", w)
+	}
+}
+
+func TestPriority(t *testing.T) {
+	l := New(Config{
+		Global:     Limit{Rate: 20, Burst: 1},
+		Priorities: map[Group]int{GroupOrder: 1, GroupQuery: 0},
+	})
+	if err := l.Wait(context.Background(), GroupQuery); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+
+	served := make(chan Group, 2)
+	wait := func(g Group) {
+		if err := l.Wait(context.Background(), g); err != nil {
+			t.Errorf("Wait(%s): %v", g, err)
+		}
+		served <- g
+	}
+	go wait(GroupQuery)
+	waitQueued(t, l, GroupQuery)
+	go wait(GroupOrder)
+	waitQueued(t, l, GroupOrder)
+
+	if g := <-served; g != GroupOrder {
+		t.Errorf("%s served first, want order", g)
+	}
+	if g := <-served; g != GroupQuery {
+		t.Errorf("%s served second, want query", g)
+	}
+}
+
+// waitQueued waits until a request in the group is waiting.
+func waitQueued(t *testing.T, l *Limiter, g Group) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for l.Waiting(g) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("no %s request waiting", g)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestWaitCancelled(t *testing.T) {
+	l := New(Config{Global: Limit{Rate: 0.1, Burst: 1}})
+	if err := l.Wait(context.Background(), GroupOrder); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() { result <- l.Wait(ctx, GroupOrder) }()
+	waitQueued(t, l, GroupOrder)
+	cancel()
+
+	select {
+	case err := <-result:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Wait error = %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Wait did not return after cancellation")
+	}
+	if n := l.Waiting(GroupOrder); n != 0 {
+		t.Errorf("Waiting = %d after cancellation, want 0", n)
+	}
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"time"
+
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/types"
+)
+
+// DefaultPageSize is the page size used by history iterators when the
+// params do not set a limit. The iterators treat the params' Limit as the
+// page size and Offset as the starting position; other filters, such as a
+// From/To time window, apply to every page.
+const DefaultPageSize = 100
+
+// MaxPageSize is the largest page size the history endpoints accept. Larger
+// limits are clamped to it, since the server would return a short page that
+// ends the iteration early.
+const MaxPageSize = 1000
+
+// DefaultWindow is the time window used by window iterators when none is
+// given.
+const DefaultWindow = 24 * time.Hour
+
+// paginate returns an iterator that fetches pages of at most limit items,
+// starting at offset, until a short page is returned. Iteration stops at the
+// first error, which is yielded with the zero value, or when ctx is done.
+func paginate[T any](ctx context.Context, limit, offset int, fetch func(limit, offset int) ([]T, error)) iter.Seq2[T, error] {
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	limit = min(limit, MaxPageSize)
+	return func(yield func(T, error) bool) {
+		var zero T
+		for next := offset; ; next += limit {
+			if err := ctx.Err(); err != nil {
+				yield(zero, err)
+				return
+			}
+			page, err := fetch(limit, next)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			for _, item := range page {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if len(page) < limit {
+				return
+			}
+		}
+	}
+}
+
+// offsetIter pages through a history endpoint by offset. page returns the
+// limit and offset fields of a params value; nil params page through
+// everything.
+func offsetIter[P, T any](ctx context.Context, params *P, page func(*P) (limit, offset *int), fetch func(context.Context, *P) ([]T, error)) iter.Seq2[T, error] {
+	var p P
+	if params != nil {
+		p = *params
+	}
+	limit, offset := page(&p)
+	return paginate(ctx, *limit, *offset, func(l, o int) ([]T, error) {
+		q := p
+		ql, qo := page(&q)
+		*ql, *qo = l, o
+		return fetch(ctx, &q)
+	})
+}
+
+// windowIter walks the time range [from, to] in milliseconds in consecutive
+// windows, oldest first, and yields the items of each window from pages.
+// Both ends of a window are inclusive, so windows do not overlap. A zero to
+// means now.
+func windowIter[T any](ctx context.Context, from, to int64, window time.Duration, pages func(from, to int64) iter.Seq2[T, error]) iter.Seq2[T, error] {
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	step := max(window.Milliseconds(), 1)
+	return func(yield func(T, error) bool) {
+		var zero T
+		if from <= 0 {
+			yield(zero, errors.New("window iteration requires a start time"))
+			return
+		}
+		end := to
+		if end <= 0 {
+			end = time.Now().UnixMilli()
+		}
+		for start := from; start <= end; start += step {
+			for item, err := range pages(start, min(start+step-1, end)) {
+				if !yield(item, err) || err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// BorrowsIter returns an iterator over the borrow and lend history, fetching pages as needed.
+func (s *HistoryService) BorrowsIter(ctx context.Context, params *types.BorrowLendHistoryParams) iter.Seq2[types.BorrowLendMovement, error] {
+	return offsetIter(ctx, params, func(p *types.BorrowLendHistoryParams) (*int, *int) { return &p.Limit, &p.Offset }, s.GetBorrowHistory)
+}
+
+// InterestIter returns an iterator over the interest payment history, fetching pages as needed.
+func (s *HistoryService) InterestIter(ctx context.Context, params *types.InterestHistoryParams) iter.Seq2[types.InterestHistoryItem, error] {
+	return offsetIter(ctx, params, func(p *types.InterestHistoryParams) (*int, *int) { return &p.Limit, &p.Offset }, s.GetInterestHistory)
+}
+
+// BorrowPositionsIter returns an iterator over the borrow and lend position history, fetching pages as needed.
+func (s *HistoryService) BorrowPositionsIter(ctx context.Context, params *types.BorrowLendPositionHistoryParams) iter.Seq2[types.BorrowLendPositionHistoryRow, error] {
+	return offsetIter(ctx, params, func(p *types.BorrowLendPositionHistoryParams) (*int, *int) { return &p.Limit, &p.Offset }, s.GetBorrowPositionHistory)
+}
+
+// FillsIter returns an iterator over the fill history, fetching pages as needed.
+func (s *HistoryService) FillsIter(ctx context.Context, params *types.FillHistoryParams) iter.Seq2[types.Fill, error] {
+	return offsetIter(ctx, params, func(p *types.FillHistoryParams) (*int, *int) { return &p.Limit, &p.Offset }, s.GetFillHistory)
+}
+
+// FundingPaymentsIter returns an iterator over the funding payment history, fetching pages as needed.
+func (s *HistoryService) FundingPaymentsIter(ctx context.Context, params *types.FundingHistoryParams) iter.Seq2[types.FundingPayment, error] {
+	return offsetIter(ctx, params, func(p *types.FundingHistoryParams) (*int, *int) { return &p.Limit, &p.Offset }, s.GetFundingPayments)
+}
+
+// OrdersIter returns an iterator over the order history, fetching pages as needed.
+func (s *HistoryService) OrdersIter(ctx context.Context, params *types.OrderHistoryParams) iter.Seq2[types.OrderHistoryItem, error] {
+	return offsetIter(ctx, params, func(p *types.OrderHistoryParams) (*int, *int) { return &p.Limit, &p.Offset }, s.GetOrderHistory)
+}
+
+// SettlementsIter returns an iterator over the settlement history, fetching pages as needed.
+func (s *HistoryService) SettlementsIter(ctx context.Context, params *types.SettlementHistoryParams) iter.Seq2[types.Settlement, error] {
+	return offsetIter(ctx, params, func(p *types.SettlementHistoryParams) (*int, *int) { return &p.Limit, &p.Offset }, s.GetSettlementHistory)
+}
+
+// DustIter returns an iterator over the dust conversion history, fetching pages as needed.
+func (s *HistoryService) DustIter(ctx context.Context, params *types.DustHistoryParams) iter.Seq2[types.DustHistoryItem, error] {
+	return offsetIter(ctx, params, func(p *types.DustHistoryParams) (*int, *int) { return &p.Limit, &p.Offset }, s.GetDustHistory)
+}
+
+// RFQsIter returns an iterator over the RFQ history, fetching pages as needed.
+func (s *HistoryService) RFQsIter(ctx context.Context, params *types.RFQHistoryParams) iter.Seq2[types.RFQHistoryItem, error] {
+	return offsetIter(ctx, params, func(p *types.RFQHistoryParams) (*int, *int) { return &p.Limit, &p.Offset }, s.GetRFQHistory)
+}
+
+// QuotesIter returns an iterator over the quote history, fetching pages as needed.
+func (s *HistoryService) QuotesIter(ctx context.Context, params *types.QuoteHistoryParams) iter.Seq2[types.QuoteHistoryItem, error] {
+	return offsetIter(ctx, params, func(p *types.QuoteHistoryParams) (*int, *int) { return &p.Limit, &p.Offset }, s.GetQuoteHistory)
+}
+
+// RFQFillsIter returns an iterator over the RFQ fill history, fetching pages as needed.
+func (s *HistoryService) RFQFillsIter(ctx context.Context, params *types.RFQFillHistoryParams) iter.Seq2[types.RFQFillHistoryItem, error] {
+	return offsetIter(ctx, params, func(p *types.RFQFillHistoryParams) (*int, *int) { return &p.Limit, &p.Offset }, s.GetRFQFillHistory)
+}
+
+// QuoteFillsIter returns an iterator over the quote fill history, fetching pages as needed.
+func (s *HistoryService) QuoteFillsIter(ctx context.Context, params *types.QuoteFillHistoryParams) iter.Seq2[types.QuoteFillHistoryItem, error] {
+	return offsetIter(ctx, params, func(p *types.QuoteFillHistoryParams) (*int, *int) { return &p.Limit, &p.Offset }, s.GetQuoteFillHistory)
+}
+
+// StrategiesIter returns an iterator over the strategy history, fetching pages as needed.
+func (s *HistoryService) StrategiesIter(ctx context.Context, params *types.StrategyHistoryParams) iter.Seq2[types.StrategyHistoryItem, error] {
+	return offsetIter(ctx, params, func(p *types.StrategyHistoryParams) (*int, *int) { return &p.Limit, &p.Offset }, s.GetStrategyHistory)
+}
+
+// PositionsIter returns an iterator over the position history, fetching pages as needed.
+func (s *HistoryService) PositionsIter(ctx context.Context, params *types.PositionHistoryParams) iter.Seq2[types.PositionHistoryItem, error] {
+	return offsetIter(ctx, params, func(p *types.PositionHistoryParams) (*int, *int) { return &p.Limit, &p.Offset }, s.GetPositionHistory)
+}
+
+// FillsWindowIter returns an iterator over the fill history from params.From
+// to params.To, or now if To is zero, fetched in consecutive time windows of
+// the given size, oldest first, each paged by offset. Unlike FillsIter,
+// offsets stay small however long the range is. params.From is required.
+func (s *HistoryService) FillsWindowIter(ctx context.Context, params *types.FillHistoryParams, window time.Duration) iter.Seq2[types.Fill, error] {
+	var p types.FillHistoryParams
+	if params != nil {
+		p = *params
+	}
+	return windowIter(ctx, p.From, p.To, window, func(from, to int64) iter.Seq2[types.Fill, error] {
+		q := p
+		q.From, q.To = from, to
+		return s.FillsIter(ctx, &q)
+	})
+}
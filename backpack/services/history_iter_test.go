@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/types"
+)
+
+// fillServer serves fills with trade IDs and timestamps 1..n in milliseconds,
+// capping pages at maxLimit like the exchange does.
+type fillServer struct {
+	HTTPClient
+	n        int
+	maxLimit int
+	requests []map[string]string
+}
+
+func (f *fillServer) GetAuthenticated(ctx context.Context, path string, params map[string]string, instruction string, result any) error {
+	f.requests = append(f.requests, params)
+	atoi := func(key string, def int) int {
+		if v, err := strconv.Atoi(params[key]); err == nil {
+			return v
+		}
+		return def
+	}
+	from, to := atoi("from", 1), atoi("to", f.n)
+	limit, offset := min(atoi("limit", 100), f.maxLimit), atoi("offset", 0)
+
+	var fills []types.Fill
+	for ts := from; ts <= min(to, f.n); ts++ {
+		fills = append(fills, types.Fill{TradeID: int64(ts)})
+	}
+	fills = fills[min(offset, len(fills)):]
+	fills = fills[:min(limit, len(fills))]
+	data, _ := json.Marshal(fills)
+	return json.Unmarshal(data, result)
+}
+
+func collect(t *testing.T, seq func(func(types.Fill, error) bool)) []int64 {
+	t.Helper()
+	var ids []int64
+	for f, err := range seq {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, f.TradeID)
+	}
+	return ids
+}
+
+func checkSequence(t *testing.T, ids []int64, n int) {
+	t.Helper()
+	if len(ids) != n {
+		t.Fatalf("got %d fills, want %d", len(ids), n)
+	}
+	for i, id := range ids {
+		if id != int64(i+1) {
+			t.Fatalf("fill %d has trade ID %d, want %d", i, id, i+1)
+		}
+	}
+}
+
+func TestFillsIterClampsLimit(t *testing.T) {
+	server := &fillServer{n: 2500, maxLimit: MaxPageSize}
+	s := NewHistoryService(server)
+
+	ids := collect(t, s.FillsIter(context.Background(), &types.FillHistoryParams{Limit: 5000}))
+	checkSequence(t, ids, 2500)
+	if got := server.requests[0]["limit"]; got != strconv.Itoa(MaxPageSize) {
+		t.Errorf("first request limit = %s, want %d", got, MaxPageSize)
+	}
+}
+
+func TestFillsIterOffset(t *testing.T) {
+	server := &fillServer{n: 25, maxLimit: MaxPageSize}
+	s := NewHistoryService(server)
+
+	ids := collect(t, s.FillsIter(context.Background(), &types.FillHistoryParams{Limit: 10, Offset: 5}))
+	if len(ids) != 20 || ids[0] != 6 || ids[19] != 25 {
+		t.Errorf("got %v, want fills 6..25", ids)
+	}
+	if len(server.requests) != 3 {
+		t.Errorf("made %d requests, want 3", len(server.requests))
+	}
+}
+
+func TestFillsWindowIter(t *testing.T) {
+	server := &fillServer{n: 95, maxLimit: MaxPageSize}
+	s := NewHistoryService(server)
+
+	// Windows of 10ms with pages of 4: every window needs several pages and
+	// no fill is seen twice at window boundaries.
+	ids := collect(t, s.FillsWindowIter(context.Background(), &types.FillHistoryParams{From: 1, To: 95, Limit: 4}, 10_000_000))
+	checkSequence(t, ids, 95)
+	for _, r := range server.requests {
+		if r["offset"] != "" && r["offset"] != "4" && r["offset"] != "8" {
+			t.Errorf("request %v pages beyond its window", r)
+		}
+	}
+
+	for _, err := range s.FillsWindowIter(context.Background(), &types.FillHistoryParams{}, 0) {
+		if err == nil {
+			t.Error("FillsWindowIter without From did not fail")
+		}
+		break
+	}
+}
@@ -2,15 +2,34 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"strconv"
 
 	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/enums"
+	bperrors "github.com/solomeowl/backpack-exchange-sdk-go/backpack/errors"
 	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/types"
 )
 
+// OrderValidator checks an order before it is submitted. It may adjust
+// params in place, e.g. to round prices to the market's tick size.
+type OrderValidator interface {
+	ValidateOrder(ctx context.Context, params *types.ExecuteOrderParams) error
+}
+
+// ErrNotReplaceable is returned by ReplaceOrder for orders that are not
+// resting limit orders, or when neither price nor quantity is given.
+var ErrNotReplaceable = errors.New("order cannot be replaced")
+
+// ErrReplacementUnknown is returned by ReplaceOrder when placing the
+// replacement failed in a way that may still have placed it, and it could
+// not be found.
+var ErrReplacementUnknown = errors.New("replacement outcome unknown")
+
 // OrdersService provides order operations.
 type OrdersService struct {
-	client HTTPClient
+	client    HTTPClient
+	validator OrderValidator
 }
 
 // NewOrdersService creates a new OrdersService.
@@ -18,6 +37,12 @@ func NewOrdersService(client HTTPClient) *OrdersService {
 	return &OrdersService{client: client}
 }
 
+// SetValidator sets the validator run before orders are submitted.
+// A nil validator disables validation.
+func (s *OrdersService) SetValidator(v OrderValidator) {
+	s.validator = v
+}
+
 // GetOrder retrieves an open order from the order book.
 func (s *OrdersService) GetOrder(ctx context.Context, params types.GetOrderParams) (*types.Order, error) {
 	var result types.Order
@@ -37,6 +62,11 @@ func (s *OrdersService) GetOrder(ctx context.Context, params types.GetOrderParam
 // ExecuteOrder executes a new order.
 func (s *OrdersService) ExecuteOrder(ctx context.Context, params types.ExecuteOrderParams) (*types.Order, error) {
 	var result types.Order
+	if s.validator != nil {
+		if err := s.validator.ValidateOrder(ctx, &params); err != nil {
+			return nil, err
+		}
+	}
 	if err := s.client.PostAuthenticated(ctx, "api/v1/order", params.ToMap(), "orderExecute", &result); err != nil {
 		return nil, err
 	}
@@ -97,6 +127,11 @@ func (s *OrdersService) ExecuteBatchOrders(ctx context.Context, orders []types.E
 	// Convert to map format for signing
 	orderMaps := make([]map[string]any, len(orders))
 	for i, order := range orders {
+		if s.validator != nil {
+			if err := s.validator.ValidateOrder(ctx, &order); err != nil {
+				return nil, fmt.Errorf("order %d: %w", i, err)
+			}
+		}
 		orderMaps[i] = order.ToMap()
 	}
 
@@ -126,3 +161,118 @@ func (s *OrdersService) LimitOrder(ctx context.Context, symbol string, side enum
 		Quantity:  quantity,
 	})
 }
+
+// ReplaceOrder cancels a resting limit order and places a new one with the
+// given price and quantity in its stead. The new quantity is the order's
+// total: what was executed before the cancel took effect, including fills
+// that happened during the replacement, is deducted, and nothing is placed
+// if it is all executed. The replacement keeps the side, time in force,
+// post-only flag and self-trade prevention of the original and, unless
+// NewClientID is set, its client ID. The order API does not report whether
+// an order is reduce-only, so the replacement is reduce-only only if
+// params.ReduceOnly is set.
+//
+// If the cancel fails, the original order is left as it was and nothing is
+// placed. If the order closed before it could be cancelled, such as by
+// filling, its final state is returned as Cancelled and nothing is placed.
+// If the replacement is rejected, the result is returned with the error so
+// the caller knows the original was cancelled and nothing rests. If placing
+// it failed ambiguously, such as with a timeout, and it is not found open
+// by its client ID, the error wraps ErrReplacementUnknown.
+func (s *OrdersService) ReplaceOrder(ctx context.Context, params types.ReplaceOrderParams) (*types.ReplaceOrderResult, error) {
+	original, err := s.GetOrder(ctx, types.GetOrderParams{Symbol: params.Symbol, OrderID: params.OrderID, ClientID: params.ClientID})
+	if err != nil {
+		return nil, err
+	}
+	if original.OrderType != enums.OrderTypeLimit || original.Status == enums.OrderStatusTriggerPending || original.TriggerPrice != "" {
+		return nil, fmt.Errorf("%w: order %s is a %s order with status %s", ErrNotReplaceable, original.ID, original.OrderType, original.Status)
+	}
+	if params.Price == "" && params.Quantity == "" {
+		return nil, fmt.Errorf("%w: price or quantity is required", ErrNotReplaceable)
+	}
+
+	cancelled, err := s.CancelOrder(ctx, types.CancelOrderParams{Symbol: original.Symbol, OrderID: original.ID})
+	if errors.Is(err, bperrors.ErrResourceNotFound) {
+		if final := s.finalState(ctx, original); final != nil {
+			return &types.ReplaceOrderResult{Cancelled: final}, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	result := &types.ReplaceOrderResult{Cancelled: cancelled}
+
+	quantity := params.Quantity
+	if quantity == "" {
+		quantity = cancelled.Quantity
+	}
+	total, err := types.ParseDecimal(quantity)
+	if err != nil {
+		return result, fmt.Errorf("invalid quantity %q: %w", quantity, err)
+	}
+	executed, err := cancelled.ExecutedQuantityDecimal()
+	if err != nil {
+		return result, fmt.Errorf("invalid executed quantity %q: %w", cancelled.ExecutedQuantity, err)
+	}
+	remaining := total.Sub(executed)
+	if !remaining.IsPositive() {
+		return result, nil
+	}
+
+	replacement := types.ExecuteOrderParams{
+		Symbol:              cancelled.Symbol,
+		Side:                cancelled.Side,
+		OrderType:           enums.OrderTypeLimit,
+		Price:               params.Price,
+		Quantity:            remaining.String(),
+		TimeInForce:         cancelled.TimeInForce,
+		SelfTradePrevention: cancelled.SelfTradePrevention,
+		ClientID:            params.NewClientID,
+	}
+	if replacement.Price == "" {
+		replacement.Price = cancelled.Price
+	}
+	if cancelled.PostOnly {
+		postOnly := true
+		replacement.PostOnly = &postOnly
+	}
+	if params.ReduceOnly {
+		reduceOnly := true
+		replacement.ReduceOnly = &reduceOnly
+	}
+	if replacement.ClientID == nil && cancelled.ClientID != 0 {
+		clientID := cancelled.ClientID
+		replacement.ClientID = &clientID
+	}
+
+	placed, err := s.ExecuteOrder(ctx, replacement)
+	if err != nil && bperrors.IsAmbiguous(err) {
+		if replacement.ClientID != nil {
+			placed, _ = s.GetOrder(ctx, types.GetOrderParams{Symbol: replacement.Symbol, ClientID: replacement.ClientID})
+		}
+		if placed == nil {
+			return result, fmt.Errorf("%w: %w", ErrReplacementUnknown, err)
+		}
+		err = nil
+	}
+	if err != nil {
+		return result, err
+	}
+	result.Replacement = placed
+	return result, nil
+}
+
+// finalState looks up an order that is no longer open in the order
+// history. It returns nil if the order is not found.
+func (s *OrdersService) finalState(ctx context.Context, o *types.Order) *types.Order {
+	history, err := NewHistoryService(s.client).GetOrderHistory(ctx, &types.OrderHistoryParams{Symbol: o.Symbol, OrderID: o.ID})
+	if err != nil {
+		return nil
+	}
+	for i := range history {
+		if history[i].ID == o.ID {
+			return &history[i]
+		}
+	}
+	return nil
+}
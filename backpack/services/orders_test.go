@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/enums"
+	bperrors "github.com/solomeowl/backpack-exchange-sdk-go/backpack/errors"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/types"
+)
+
+// orderServer holds one open order and records the orders placed. Orders
+// that fill or fail to place are set up by the tests.
+type orderServer struct {
+	HTTPClient
+	open    *types.Order
+	history []types.Order
+	// placeErr fails placements; the order is placed anyway if lost.
+	placeErr error
+	lost     bool
+	placed   []map[string]any
+}
+
+var errNotFound = &bperrors.APIError{StatusCode: http.StatusNotFound, Code: string(bperrors.ErrCodeResourceNotFound), Message: "Order not found"}
+
+func reply(v any, result any) error {
+	data, _ := json.Marshal(v)
+	return json.Unmarshal(data, result)
+}
+
+func (s *orderServer) GetAuthenticated(ctx context.Context, path string, params map[string]string, instruction string, result any) error {
+	switch instruction {
+	case "orderQuery":
+		if s.open == nil || params["orderId"] != "" && params["orderId"] != s.open.ID {
+			return errNotFound
+		}
+		return reply(s.open, result)
+	case "orderHistoryQueryAll":
+		return reply(s.history, result)
+	}
+	return errors.New("unexpected " + instruction)
+}
+
+func (s *orderServer) DeleteAuthenticated(ctx context.Context, path string, body any, instruction string, result any) error {
+	if s.open == nil {
+		return errNotFound
+	}
+	cancelled := *s.open
+	cancelled.Status = enums.OrderStatusCancelled
+	s.open = nil
+	return reply(cancelled, result)
+}
+
+func (s *orderServer) PostAuthenticated(ctx context.Context, path string, body any, instruction string, result any) error {
+	params := body.(map[string]any)
+	s.placed = append(s.placed, params)
+	o := &types.Order{ID: "2", Symbol: params["symbol"].(string), Status: enums.OrderStatusNew}
+	if id, ok := params["clientId"].(uint32); ok {
+		o.ClientID = id
+	}
+	if s.placeErr != nil {
+		if s.lost {
+			s.open = o
+		}
+		return s.placeErr
+	}
+	s.open = o
+	return reply(o, result)
+}
+
+func resting() *types.Order {
+	return &types.Order{
+		ID:               "1",
+		ClientID:         7,
+		Symbol:           "SOL_USDC_PERP",
+		Side:             enums.SideAsk,
+		OrderType:        enums.OrderTypeLimit,
+		Status:           enums.OrderStatusNew,
+		Price:            "150",
+		Quantity:         "2",
+		ExecutedQuantity: "0",
+		TimeInForce:      enums.TimeInForceGTC,
+	}
+}
+
+func TestReplaceOrderKeepsReduceOnly(t *testing.T) {
+	srv := &orderServer{open: resting()}
+	result, err := NewOrdersService(srv).ReplaceOrder(context.Background(), types.ReplaceOrderParams{
+		Symbol: "SOL_USDC_PERP", OrderID: "1", Price: "151", ReduceOnly: true,
+	})
+	if err != nil {
+		t.Fatalf("ReplaceOrder: %v", err)
+	}
+	if result.Replacement == nil || len(srv.placed) != 1 {
+		t.Fatalf("replacement = %v after %d placements, want one", result.Replacement, len(srv.placed))
+	}
+	if srv.placed[0]["reduceOnly"] != true || srv.placed[0]["price"] != "151" || srv.placed[0]["quantity"] != "2" {
+		t.Errorf("placed %v, want a reduce-only order of 2 at 151", srv.placed[0])
+	}
+}
+
+func TestReplaceOrderFilledBeforeCancel(t *testing.T) {
+	srv := &fillingServer{orderServer: &orderServer{open: resting()}}
+	filled := *srv.open
+	filled.Status, filled.ExecutedQuantity = enums.OrderStatusFilled, "2"
+	srv.history = []types.Order{filled}
+
+	result, err := NewOrdersService(srv).ReplaceOrder(context.Background(), types.ReplaceOrderParams{Symbol: "SOL_USDC_PERP", OrderID: "1", Price: "151"})
+	if err != nil {
+		t.Fatalf("ReplaceOrder: %v", err)
+	}
+	if result.Cancelled == nil || result.Cancelled.Status != enums.OrderStatusFilled || result.Replacement != nil {
+		t.Errorf("result = %+v, want the filled order and no replacement", result)
+	}
+	if len(srv.placed) != 0 {
+		t.Errorf("placed %d orders, want none", len(srv.placed))
+	}
+}
+
+// fillingServer fills the open order just before it is cancelled.
+type fillingServer struct {
+	*orderServer
+}
+
+func (s *fillingServer) DeleteAuthenticated(ctx context.Context, path string, body any, instruction string, result any) error {
+	s.open = nil
+	return errNotFound
+}
+
+func TestReplaceOrderAmbiguousPlacement(t *testing.T) {
+	timeout := &bperrors.APIError{StatusCode: http.StatusGatewayTimeout}
+
+	srv := &orderServer{open: resting(), placeErr: timeout, lost: true}
+	result, err := NewOrdersService(srv).ReplaceOrder(context.Background(), types.ReplaceOrderParams{Symbol: "SOL_USDC_PERP", OrderID: "1", Price: "151"})
+	if err != nil {
+		t.Fatalf("ReplaceOrder: %v", err)
+	}
+	if result.Replacement == nil || result.Replacement.ClientID != 7 {
+		t.Errorf("replacement = %+v, want the placed order found by client ID", result.Replacement)
+	}
+
+	srv = &orderServer{open: resting(), placeErr: timeout}
+	result, err = NewOrdersService(srv).ReplaceOrder(context.Background(), types.ReplaceOrderParams{Symbol: "SOL_USDC_PERP", OrderID: "1", Price: "151"})
+	if !errors.Is(err, ErrReplacementUnknown) || result == nil || result.Cancelled == nil {
+		t.Errorf("ReplaceOrder = %+v, %v, want the cancelled order and ErrReplacementUnknown", result, err)
+	}
+}
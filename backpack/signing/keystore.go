@@ -0,0 +1,158 @@
+package signing
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	keystoreVersion    = 1
+	keystoreKDF        = "pbkdf2-sha256"
+	keystoreIterations = 600000
+	keystoreSaltSize   = 16
+	keystoreKeySize    = 32
+)
+
+// keystoreMaxIterations bounds the iteration count accepted from a keystore
+// file, which may not raise it so high that loading never finishes, nor
+// lower it below keystoreIterations.
+const keystoreMaxIterations = 10 * keystoreIterations
+
+// ErrWrongPassphrase is returned when a keystore cannot be decrypted.
+var ErrWrongPassphrase = errors.New("signing: wrong passphrase or corrupted keystore")
+
+// keystoreFile is the on-disk format of an encrypted keystore. The private
+// key seed is encrypted with AES-256-GCM under a key derived from the
+// passphrase, and the public key is authenticated as additional data.
+type keystoreFile struct {
+	Version    int    `json:"version"`
+	PublicKey  string `json:"publicKey"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       string `json:"salt"`
+	Nonce      string `json:"nonce"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+// WriteKeystore encrypts the base64-encoded key pair with passphrase and
+// writes it to path with owner-only permissions.
+func WriteKeystore(path, publicKey, secretKey string, passphrase []byte) error {
+	signer, err := NewKeySigner(publicKey, secretKey)
+	if err != nil {
+		return err
+	}
+
+	salt := make([]byte, keystoreSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	gcm, err := keystoreCipher(passphrase, salt, keystoreIterations)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	ciphertext := gcm.Seal(nil, nonce, signer.privateKey.Seed(), []byte(publicKey))
+
+	data, err := json.MarshalIndent(keystoreFile{
+		Version:    keystoreVersion,
+		PublicKey:  publicKey,
+		KDF:        keystoreKDF,
+		Iterations: keystoreIterations,
+		Salt:       base64.StdEncoding.EncodeToString(salt),
+		Nonce:      base64.StdEncoding.EncodeToString(nonce),
+		Ciphertext: base64.StdEncoding.EncodeToString(ciphertext),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal keystore: %w", err)
+	}
+
+	return os.WriteFile(path, data, 0o600)
+}
+
+// LoadKeystore decrypts the keystore at path and returns a signer holding
+// the decrypted key in memory.
+func LoadKeystore(path string, passphrase []byte) (*KeySigner, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore: %w", err)
+	}
+
+	var ks keystoreFile
+	if err := json.Unmarshal(data, &ks); err != nil {
+		return nil, fmt.Errorf("failed to parse keystore: %w", err)
+	}
+	if ks.Version != keystoreVersion || ks.KDF != keystoreKDF {
+		return nil, fmt.Errorf("unsupported keystore version %d (%s)", ks.Version, ks.KDF)
+	}
+	if ks.Iterations < keystoreIterations || ks.Iterations > keystoreMaxIterations {
+		return nil, fmt.Errorf("keystore iteration count %d outside [%d, %d]", ks.Iterations, keystoreIterations, keystoreMaxIterations)
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(ks.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode keystore salt: %w", err)
+	}
+	nonce, err := base64.StdEncoding.DecodeString(ks.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode keystore nonce: %w", err)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(ks.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode keystore ciphertext: %w", err)
+	}
+
+	gcm, err := keystoreCipher(passphrase, salt, ks.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+
+	seed, err := gcm.Open(nil, nonce, ciphertext, []byte(ks.PublicKey))
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+
+	privateKey, err := privateKeyFromBytes(seed)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KeySigner{
+		publicKey:  ks.PublicKey,
+		privateKey: privateKey,
+	}, nil
+}
+
+func keystoreCipher(passphrase, salt []byte, iterations int) (cipher.AEAD, error) {
+	if iterations < 1 {
+		return nil, fmt.Errorf("invalid keystore iteration count %d", iterations)
+	}
+	block, err := aes.NewCipher(deriveKey(passphrase, salt, iterations))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// deriveKey derives the keystore encryption key from a passphrase with
+// PBKDF2-HMAC-SHA256.
+func deriveKey(passphrase, salt []byte, iterations int) []byte {
+	return pbkdf2.Key(passphrase, salt, iterations, keystoreKeySize, sha256.New)
+}
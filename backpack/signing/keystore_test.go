@@ -0,0 +1,91 @@
+package signing
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestDeriveKey checks the keystore KDF against the PBKDF2-HMAC-SHA256 test
+// vectors of RFC 7914 §11. The keystore key is 32 bytes, the first block of
+// the 64-byte vectors.
+func TestDeriveKey(t *testing.T) {
+	tests := []struct {
+		passphrase string
+		salt       string
+		iterations int
+		want       string
+	}{
+		{"passwd", "salt", 1, "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc"},
+		{"Password", "NaCl", 80000, "4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56"},
+	}
+	for _, tt := range tests {
+		want, _ := hex.DecodeString(tt.want)
+		got := deriveKey([]byte(tt.passphrase), []byte(tt.salt), tt.iterations)
+		if !bytes.Equal(got, want) {
+			t.Errorf("deriveKey(%q, %q, %d) = %x, want %x", tt.passphrase, tt.salt, tt.iterations, got, want)
+		}
+	}
+}
+
+func TestKeystoreRoundTrip(t *testing.T) {
+	seed := bytes.Repeat([]byte{7}, ed25519.SeedSize)
+	privateKey := ed25519.NewKeyFromSeed(seed)
+	publicKey := base64.StdEncoding.EncodeToString(privateKey.Public().(ed25519.PublicKey))
+	path := filepath.Join(t.TempDir(), "keystore.json")
+
+	if err := WriteKeystore(path, publicKey, base64.StdEncoding.EncodeToString(seed), []byte("correct horse")); err != nil {
+		t.Fatalf("WriteKeystore: %v", err)
+	}
+
+	signer, err := LoadKeystore(path, []byte("correct horse"))
+	if err != nil {
+		t.Fatalf("LoadKeystore: %v", err)
+	}
+	if signer.PublicKey() != publicKey {
+		t.Errorf("PublicKey() = %q, want %q", signer.PublicKey(), publicKey)
+	}
+	sig, _ := signer.Sign([]byte("message"))
+	if !ed25519.Verify(privateKey.Public().(ed25519.PublicKey), []byte("message"), sig) {
+		t.Error("signature from loaded keystore does not verify")
+	}
+
+	if _, err := LoadKeystore(path, []byte("wrong")); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("LoadKeystore with wrong passphrase: err = %v, want ErrWrongPassphrase", err)
+	}
+}
+
+func TestLoadKeystoreIterationBounds(t *testing.T) {
+	seed := bytes.Repeat([]byte{7}, ed25519.SeedSize)
+	publicKey := base64.StdEncoding.EncodeToString(ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey))
+	path := filepath.Join(t.TempDir(), "keystore.json")
+	if err := WriteKeystore(path, publicKey, base64.StdEncoding.EncodeToString(seed), []byte("correct horse")); err != nil {
+		t.Fatalf("WriteKeystore: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, iterations := range []int{0, 1, keystoreIterations - 1, keystoreMaxIterations + 1} {
+		var ks keystoreFile
+		if err := json.Unmarshal(data, &ks); err != nil {
+			t.Fatal(err)
+		}
+		ks.Iterations = iterations
+		tampered, _ := json.Marshal(ks)
+		if err := os.WriteFile(path, tampered, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		_, err := LoadKeystore(path, []byte("correct horse"))
+		if err == nil || errors.Is(err, ErrWrongPassphrase) {
+			t.Errorf("LoadKeystore with %d iterations: err = %v, want an iteration count error", iterations, err)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package signing
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// DefaultRemoteTimeout bounds a single exchange with a signing daemon.
+const DefaultRemoteTimeout = 5 * time.Second
+
+// remoteRequest and remoteResponse are exchanged with a signing daemon as
+// newline-delimited JSON. Messages and signatures are base64-encoded.
+type remoteRequest struct {
+	Method  string `json:"method"`
+	Message []byte `json:"message,omitempty"`
+}
+
+type remoteResponse struct {
+	PublicKey string `json:"publicKey,omitempty"`
+	Signature []byte `json:"signature,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// RemoteSigner signs through a signing daemon listening on a Unix socket,
+// so the private key never enters this process. See Serve for the daemon side.
+type RemoteSigner struct {
+	socketPath string
+	timeout    time.Duration
+	publicKey  string
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// RemoteOption is a functional option for configuring a RemoteSigner.
+type RemoteOption func(*RemoteSigner)
+
+// WithRemoteTimeout sets the timeout for a single request to the daemon.
+func WithRemoteTimeout(timeout time.Duration) RemoteOption {
+	return func(r *RemoteSigner) {
+		r.timeout = timeout
+	}
+}
+
+// NewRemoteSigner connects to the signing daemon at socketPath and fetches
+// its public key.
+func NewRemoteSigner(socketPath string, opts ...RemoteOption) (*RemoteSigner, error) {
+	r := &RemoteSigner{
+		socketPath: socketPath,
+		timeout:    DefaultRemoteTimeout,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	resp, err := r.call(remoteRequest{Method: "publicKey"})
+	if err != nil {
+		return nil, err
+	}
+	if resp.PublicKey == "" {
+		return nil, errors.New("signing: daemon returned an empty public key")
+	}
+	r.publicKey = resp.PublicKey
+	return r, nil
+}
+
+// PublicKey returns the public key (API key) reported by the daemon.
+func (r *RemoteSigner) PublicKey() string {
+	return r.publicKey
+}
+
+// Sign asks the daemon to sign message.
+func (r *RemoteSigner) Sign(message []byte) ([]byte, error) {
+	resp, err := r.call(remoteRequest{Method: "sign", Message: message})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Signature, nil
+}
+
+// Close closes the connection to the daemon.
+func (r *RemoteSigner) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.closeLocked()
+}
+
+func (r *RemoteSigner) call(req remoteRequest) (*remoteResponse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// A cached connection may have been closed by the daemon; retry once on
+	// a fresh connection before giving up.
+	reused := r.conn != nil
+	resp, err := r.roundTripLocked(req)
+	if err != nil && reused {
+		resp, err = r.roundTripLocked(req)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("signing: daemon error: %s", resp.Error)
+	}
+	return resp, nil
+}
+
+func (r *RemoteSigner) roundTripLocked(req remoteRequest) (*remoteResponse, error) {
+	if r.conn == nil {
+		conn, err := net.DialTimeout("unix", r.socketPath, r.timeout)
+		if err != nil {
+			return nil, fmt.Errorf("signing: failed to connect to daemon: %w", err)
+		}
+		r.conn = conn
+		r.reader = bufio.NewReader(conn)
+	}
+
+	if err := r.conn.SetDeadline(time.Now().Add(r.timeout)); err != nil {
+		r.closeLocked()
+		return nil, fmt.Errorf("signing: %w", err)
+	}
+
+	if err := json.NewEncoder(r.conn).Encode(req); err != nil {
+		r.closeLocked()
+		return nil, fmt.Errorf("signing: failed to send request: %w", err)
+	}
+
+	line, err := r.reader.ReadBytes('\n')
+	if err != nil {
+		r.closeLocked()
+		return nil, fmt.Errorf("signing: failed to read response: %w", err)
+	}
+
+	var resp remoteResponse
+	if err := json.Unmarshal(line, &resp); err != nil {
+		r.closeLocked()
+		return nil, fmt.Errorf("signing: invalid response: %w", err)
+	}
+	return &resp, nil
+}
+
+func (r *RemoteSigner) closeLocked() error {
+	if r.conn == nil {
+		return nil
+	}
+	err := r.conn.Close()
+	r.conn = nil
+	r.reader = nil
+	return err
+}
+
+// Serve runs a signing daemon on ln, answering RemoteSigner requests with
+// signer until ln is closed. It is intended for a separate process that owns
+// the private key, typically listening on a Unix socket with restrictive
+// file permissions.
+func Serve(ln net.Listener, signer Signer) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go serveConn(conn, signer)
+	}
+}
+
+func serveConn(conn net.Conn, signer Signer) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	encoder := json.NewEncoder(conn)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			return
+		}
+
+		var req remoteRequest
+		var resp remoteResponse
+		if err := json.Unmarshal(line, &req); err != nil {
+			resp.Error = "invalid request"
+		} else {
+			switch req.Method {
+			case "publicKey":
+				resp.PublicKey = signer.PublicKey()
+			case "sign":
+				signature, err := signer.Sign(req.Message)
+				if err != nil {
+					resp.Error = err.Error()
+				} else {
+					resp.Signature = signature
+				}
+			default:
+				resp.Error = fmt.Sprintf("unknown method %q", req.Method)
+			}
+		}
+
+		if err := encoder.Encode(resp); err != nil {
+			return
+		}
+	}
+}
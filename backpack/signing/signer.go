@@ -0,0 +1,75 @@
+// Package signing provides ED25519 signers for authenticating Backpack Exchange API requests.
+package signing
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+)
+
+// Signer signs API requests. Implementations may keep the private key
+// outside the process, e.g. in a separate signing daemon.
+type Signer interface {
+	// PublicKey returns the base64-encoded public key used as the API key.
+	PublicKey() string
+	// Sign returns the raw ED25519 signature of message.
+	Sign(message []byte) ([]byte, error)
+}
+
+// KeySigner signs with an ED25519 private key held in memory.
+type KeySigner struct {
+	publicKey  string
+	privateKey ed25519.PrivateKey
+}
+
+// NewKeySigner creates a KeySigner from base64-encoded keys.
+// The secret key may be either a 32-byte seed or a 64-byte private key.
+func NewKeySigner(publicKey, secretKey string) (*KeySigner, error) {
+	privateKeyBytes, err := base64.StdEncoding.DecodeString(secretKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode secret key: %w", err)
+	}
+
+	privateKey, err := privateKeyFromBytes(privateKeyBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KeySigner{
+		publicKey:  publicKey,
+		privateKey: privateKey,
+	}, nil
+}
+
+// NewKeySignerFromPrivateKey creates a KeySigner from an ED25519 private key,
+// deriving the API key from its public half.
+func NewKeySignerFromPrivateKey(privateKey ed25519.PrivateKey) *KeySigner {
+	publicKey := privateKey.Public().(ed25519.PublicKey)
+	return &KeySigner{
+		publicKey:  base64.StdEncoding.EncodeToString(publicKey),
+		privateKey: privateKey,
+	}
+}
+
+// PublicKey returns the public key (API key).
+func (s *KeySigner) PublicKey() string {
+	return s.publicKey
+}
+
+// Sign signs a message with the private key.
+func (s *KeySigner) Sign(message []byte) ([]byte, error) {
+	return ed25519.Sign(s.privateKey, message), nil
+}
+
+func privateKeyFromBytes(b []byte) (ed25519.PrivateKey, error) {
+	// ED25519 seed is 32 bytes, full private key is 64 bytes
+	switch len(b) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(b), nil
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(b), nil
+	default:
+		return nil, fmt.Errorf("invalid secret key length: expected %d or %d bytes, got %d",
+			ed25519.SeedSize, ed25519.PrivateKeySize, len(b))
+	}
+}
@@ -0,0 +1,213 @@
+// Package timesync keeps request timestamps aligned with the Backpack Exchange server clock.
+package timesync
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultInterval is the default interval between measurements.
+	DefaultInterval = 5 * time.Minute
+	// DefaultSamples is the default number of requests per measurement.
+	DefaultSamples = 3
+)
+
+// Clock provides the current time used to timestamp signed requests.
+type Clock interface {
+	Now() time.Time
+}
+
+// TimeSource returns the server time in Unix milliseconds.
+// *services.SystemService implements TimeSource.
+type TimeSource interface {
+	GetTime(ctx context.Context) (int64, error)
+}
+
+// Stats describes the most recent clock measurement.
+type Stats struct {
+	Offset    time.Duration // Server time minus local time
+	RTT       time.Duration // Round-trip latency of the best sample
+	LastSync  time.Time     // Local time of the last successful measurement
+	LastError error         // Error of the last measurement attempt, if it failed
+}
+
+// Synced reports whether at least one measurement has succeeded.
+func (s Stats) Synced() bool {
+	return !s.LastSync.IsZero()
+}
+
+// Syncer periodically measures the offset between the local and server
+// clocks. It implements Clock, returning the local time corrected by the
+// measured offset. It is safe for concurrent use.
+type Syncer struct {
+	source   TimeSource
+	interval time.Duration
+	samples  int
+	onSync   func(Stats)
+	now      func() time.Time
+
+	mu     sync.RWMutex
+	stats  Stats
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Option is a functional option for configuring a Syncer.
+type Option func(*Syncer)
+
+// WithInterval sets how often the clock offset is measured.
+func WithInterval(interval time.Duration) Option {
+	return func(s *Syncer) {
+		s.interval = interval
+	}
+}
+
+// WithSamples sets how many requests are made per measurement. The sample
+// with the lowest round-trip time is used.
+func WithSamples(samples int) Option {
+	return func(s *Syncer) {
+		s.samples = samples
+	}
+}
+
+// WithOnSync sets a callback invoked after every measurement attempt,
+// e.g. to alert when the drift exceeds a threshold.
+func WithOnSync(fn func(Stats)) Option {
+	return func(s *Syncer) {
+		s.onSync = fn
+	}
+}
+
+// NewSyncer creates a new Syncer measuring against the given time source.
+func NewSyncer(source TimeSource, opts ...Option) *Syncer {
+	s := &Syncer{
+		source:   source,
+		interval: DefaultInterval,
+		samples:  DefaultSamples,
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.interval <= 0 {
+		s.interval = DefaultInterval
+	}
+	if s.samples < 1 {
+		s.samples = 1
+	}
+	return s
+}
+
+// Sync measures the clock offset once.
+func (s *Syncer) Sync(ctx context.Context) error {
+	var best Stats
+	var err error
+	for i := 0; i < s.samples; i++ {
+		sent := s.now()
+		var serverMs int64
+		serverMs, err = s.source.GetTime(ctx)
+		received := s.now()
+		if err != nil {
+			break
+		}
+
+		// Assume the server stamped the response halfway through the round trip.
+		rtt := received.Sub(sent)
+		server := time.UnixMilli(serverMs)
+		offset := server.Sub(sent.Add(rtt / 2))
+		if best.LastSync.IsZero() || rtt < best.RTT {
+			best = Stats{Offset: offset, RTT: rtt, LastSync: received}
+		}
+	}
+
+	s.mu.Lock()
+	if best.LastSync.IsZero() {
+		s.stats.LastError = fmt.Errorf("timesync: failed to get server time: %w", err)
+	} else {
+		s.stats = best
+	}
+	stats := s.stats
+	s.mu.Unlock()
+
+	if s.onSync != nil {
+		s.onSync(stats)
+	}
+	return stats.LastError
+}
+
+// Start measures the offset periodically in the background until Stop is
+// called or ctx is done. The first measurement is immediate unless Sync
+// has already succeeded.
+func (s *Syncer) Start(ctx context.Context) {
+	s.mu.Lock()
+	if s.cancel != nil {
+		s.mu.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	done := s.done
+	synced := s.stats.Synced()
+	s.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			if !synced {
+				_ = s.Sync(ctx)
+			}
+			synced = false
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops background measurements started by Start.
+func (s *Syncer) Stop() {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel, s.done = nil, nil
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// Now returns the local time corrected by the measured offset.
+func (s *Syncer) Now() time.Time {
+	return s.now().Add(s.Offset())
+}
+
+// Offset returns the measured server time minus local time.
+func (s *Syncer) Offset() time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.stats.Offset
+}
+
+// RTT returns the round-trip latency of the most recent measurement.
+func (s *Syncer) RTT() time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.stats.RTT
+}
+
+// Stats returns the most recent measurement.
+func (s *Syncer) Stats() Stats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.stats
+}
@@ -0,0 +1,185 @@
+package timesync
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a local clock that only moves when advanced.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+// sample is one server response: the server clock runs offset ahead of the
+// local one, and the request takes out on the way there and back on the
+// way back.
+type sample struct {
+	offset    time.Duration
+	out, back time.Duration
+	err       error
+}
+
+// fakeServer answers with the samples in turn, then repeats the last one.
+type fakeServer struct {
+	clock   *fakeClock
+	samples []sample
+
+	mu    sync.Mutex
+	calls int
+}
+
+func (s *fakeServer) GetTime(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	r := s.samples[min(s.calls, len(s.samples)-1)]
+	s.calls++
+	s.mu.Unlock()
+
+	s.clock.advance(r.out)
+	server := s.clock.Now().Add(r.offset)
+	s.clock.advance(r.back)
+	if r.err != nil {
+		return 0, r.err
+	}
+	return server.UnixMilli(), nil
+}
+
+func (s *fakeServer) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+func newFake(samples ...sample) (*Syncer, *fakeServer) {
+	clock := &fakeClock{now: time.UnixMilli(1700000000000)}
+	server := &fakeServer{clock: clock, samples: samples}
+	s := NewSyncer(server, WithSamples(len(samples)))
+	s.now = clock.Now
+	return s, server
+}
+
+func TestSyncUsesFastestSample(t *testing.T) {
+	s, server := newFake(
+		sample{offset: 3 * time.Second, out: 90 * time.Millisecond, back: 10 * time.Millisecond},
+		sample{offset: 2 * time.Second, out: 5 * time.Millisecond, back: 5 * time.Millisecond},
+		sample{offset: time.Second, out: 30 * time.Millisecond, back: 30 * time.Millisecond},
+	)
+	if err := s.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+
+	stats := s.Stats()
+	if stats.RTT != 10*time.Millisecond {
+		t.Errorf("RTT = %v, want 10ms", stats.RTT)
+	}
+	if stats.Offset != 2*time.Second {
+		t.Errorf("Offset = %v, want 2s", stats.Offset)
+	}
+	// The second sample was received 110ms after the start.
+	if want := time.UnixMilli(1700000000110); !stats.LastSync.Equal(want) {
+		t.Errorf("LastSync = %v, want %v", stats.LastSync, want)
+	}
+	if got, want := s.Now(), server.clock.Now().Add(2*time.Second); !got.Equal(want) {
+		t.Errorf("Now = %v, want %v", got, want)
+	}
+}
+
+func TestSyncAssumesSymmetricLatency(t *testing.T) {
+	// The server stamps the response when the request arrives, 30ms into a
+	// 40ms round trip; the 10ms error is half the asymmetry.
+	s, _ := newFake(sample{offset: time.Second, out: 30 * time.Millisecond, back: 10 * time.Millisecond})
+	if err := s.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if got := s.Offset(); got != time.Second+10*time.Millisecond {
+		t.Errorf("Offset = %v, want 1.01s", got)
+	}
+}
+
+func TestSyncFailure(t *testing.T) {
+	unavailable := errors.New("unavailable")
+	s, server := newFake(sample{offset: time.Second, out: time.Millisecond, back: time.Millisecond})
+	if err := s.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	synced := s.Stats()
+
+	server.samples = []sample{{err: unavailable}}
+	if err := s.Sync(context.Background()); !errors.Is(err, unavailable) {
+		t.Fatalf("Sync error = %v, want %v", err, unavailable)
+	}
+	stats := s.Stats()
+	if !errors.Is(stats.LastError, unavailable) {
+		t.Errorf("LastError = %v, want %v", stats.LastError, unavailable)
+	}
+	// The last good measurement is kept.
+	if stats.Offset != synced.Offset || !stats.LastSync.Equal(synced.LastSync) {
+		t.Errorf("stats = %+v after a failure, want the offset of %+v", stats, synced)
+	}
+}
+
+func TestSyncKeepsSamplesBeforeFailure(t *testing.T) {
+	s, _ := newFake(
+		sample{offset: time.Second, out: time.Millisecond, back: time.Millisecond},
+		sample{err: errors.New("unavailable")},
+	)
+	if err := s.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if got := s.Offset(); got != time.Second {
+		t.Errorf("Offset = %v, want 1s", got)
+	}
+}
+
+func TestStartStop(t *testing.T) {
+	s, server := newFake(sample{offset: time.Second, out: time.Millisecond, back: time.Millisecond})
+	s.interval = time.Millisecond
+	s.Start(context.Background())
+
+	deadline := time.Now().Add(5 * time.Second)
+	for server.count() < 3 {
+		if time.Now().After(deadline) {
+			t.Fatal("Start did not measure periodically")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	s.Stop()
+	if !s.Stats().Synced() {
+		t.Error("not synced after Start")
+	}
+
+	calls := server.count()
+	time.Sleep(20 * time.Millisecond)
+	if server.count() != calls {
+		t.Errorf("measured %d times after Stop", server.count()-calls)
+	}
+	// Stopping again is a no-op.
+	s.Stop()
+}
+
+func TestStartAfterSync(t *testing.T) {
+	s, server := newFake(sample{offset: time.Second, out: time.Millisecond, back: time.Millisecond})
+	if err := s.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	s.Start(context.Background())
+	s.Stop()
+	if server.count() != 1 {
+		t.Errorf("measured %d times, want only the explicit Sync", server.count())
+	}
+}
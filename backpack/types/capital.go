@@ -124,3 +124,66 @@ type DustConversion struct {
 	USDCReceived string `json:"usdcReceived"`
 	Timestamp    string `json:"timestamp"`
 }
+
+// AvailableDecimal returns the available quantity as a Decimal.
+func (b *Balance) AvailableDecimal() (Decimal, error) {
+	return parseDecimalField(b.Available)
+}
+
+// LockedDecimal returns the locked quantity as a Decimal.
+func (b *Balance) LockedDecimal() (Decimal, error) {
+	return parseDecimalField(b.Locked)
+}
+
+// StakedDecimal returns the staked quantity as a Decimal.
+func (b *Balance) StakedDecimal() (Decimal, error) {
+	return parseDecimalField(b.Staked)
+}
+
+// TotalDecimal returns the sum of the available, locked and staked quantities.
+func (b *Balance) TotalDecimal() (Decimal, error) {
+	total := Decimal{}
+	for _, s := range []string{b.Available, b.Locked, b.Staked} {
+		d, err := parseDecimalField(s)
+		if err != nil {
+			return Decimal{}, err
+		}
+		total = total.Add(d)
+	}
+	return total, nil
+}
+
+// NetEquityDecimal returns the net equity as a Decimal.
+func (m *MarginAccountSummary) NetEquityDecimal() (Decimal, error) {
+	return parseDecimalField(m.NetEquity)
+}
+
+// NetEquityAvailableDecimal returns the available net equity as a Decimal.
+func (m *MarginAccountSummary) NetEquityAvailableDecimal() (Decimal, error) {
+	return parseDecimalField(m.NetEquityAvailable)
+}
+
+// MarginFractionDecimal returns the margin fraction as a Decimal.
+func (m *MarginAccountSummary) MarginFractionDecimal() (Decimal, error) {
+	return parseDecimalField(m.MarginFraction)
+}
+
+// PnlUnrealizedDecimal returns the unrealized PnL as a Decimal.
+func (m *MarginAccountSummary) PnlUnrealizedDecimal() (Decimal, error) {
+	return parseDecimalField(m.PnlUnrealized)
+}
+
+// TotalQuantityDecimal returns the total quantity as a Decimal.
+func (c *CollateralItem) TotalQuantityDecimal() (Decimal, error) {
+	return parseDecimalField(c.TotalQuantity)
+}
+
+// AvailableQuantityDecimal returns the available quantity as a Decimal.
+func (c *CollateralItem) AvailableQuantityDecimal() (Decimal, error) {
+	return parseDecimalField(c.AvailableQuantity)
+}
+
+// CollateralValueDecimal returns the collateral value as a Decimal.
+func (c *CollateralItem) CollateralValueDecimal() (Decimal, error) {
+	return parseDecimalField(c.CollateralValue)
+}
@@ -0,0 +1,426 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal is an exact decimal number for prices, quantities and amounts.
+// Its value is coef * 10^exp. The zero value is 0. Decimals are immutable;
+// arithmetic methods return new values.
+//
+// The API types keep their string fields and offer accessors such as
+// Order.PriceDecimal, which return zero for fields the API left empty.
+type Decimal struct {
+	coef *big.Int
+	exp  int32
+}
+
+var (
+	bigOne = big.NewInt(1)
+	bigTen = big.NewInt(10)
+)
+
+// NewDecimal returns the decimal coef * 10^exp.
+func NewDecimal(coef int64, exp int32) Decimal {
+	return Decimal{coef: big.NewInt(coef), exp: exp}
+}
+
+// NewDecimalFromInt returns the decimal value of an integer.
+func NewDecimalFromInt(value int64) Decimal {
+	return NewDecimal(value, 0)
+}
+
+// NewDecimalFromFloat returns the shortest decimal that represents value.
+func NewDecimalFromFloat(value float64) (Decimal, error) {
+	return ParseDecimal(strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+// ParseDecimal parses a decimal string such as "123", "-0.001" or "1.5e-8".
+func ParseDecimal(s string) (Decimal, error) {
+	orig := s
+	if s == "" {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", orig)
+	}
+
+	var exp int64
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.ParseInt(s[i+1:], 10, 32)
+		if err != nil {
+			return Decimal{}, fmt.Errorf("invalid decimal %q", orig)
+		}
+		exp = e
+		s = s[:i]
+	}
+
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		frac := s[i+1:]
+		if strings.ContainsAny(frac, ".+-") {
+			return Decimal{}, fmt.Errorf("invalid decimal %q", orig)
+		}
+		exp -= int64(len(frac))
+		s = s[:i] + frac
+	}
+
+	if exp < -1<<31 || exp > 1<<31-1 {
+		return Decimal{}, fmt.Errorf("decimal exponent out of range %q", orig)
+	}
+
+	coef, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", orig)
+	}
+	return Decimal{coef: coef, exp: int32(exp)}, nil
+}
+
+// MustParseDecimal is like ParseDecimal but panics if s is invalid.
+// It is intended for constants.
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// parseDecimalField parses an optional decimal field, treating an empty
+// string as zero.
+func parseDecimalField(s string) (Decimal, error) {
+	if s == "" {
+		return Decimal{}, nil
+	}
+	return ParseDecimal(s)
+}
+
+func (d Decimal) coefficient() *big.Int {
+	if d.coef == nil {
+		return new(big.Int)
+	}
+	return d.coef
+}
+
+// rescale returns the coefficient of d expressed with the smaller exponent exp.
+func (d Decimal) rescale(exp int32) *big.Int {
+	coef := new(big.Int).Set(d.coefficient())
+	if d.exp > exp {
+		coef.Mul(coef, pow10(int64(d.exp)-int64(exp)))
+	}
+	return coef
+}
+
+// align returns the coefficients of d and other expressed with a common exponent.
+func (d Decimal) align(other Decimal) (*big.Int, *big.Int, int32) {
+	exp := min(d.exp, other.exp)
+	return d.rescale(exp), other.rescale(exp), exp
+}
+
+func pow10(n int64) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(n), nil)
+}
+
+// Add returns d + other.
+func (d Decimal) Add(other Decimal) Decimal {
+	a, b, exp := d.align(other)
+	return Decimal{coef: a.Add(a, b), exp: exp}
+}
+
+// Sub returns d - other.
+func (d Decimal) Sub(other Decimal) Decimal {
+	a, b, exp := d.align(other)
+	return Decimal{coef: a.Sub(a, b), exp: exp}
+}
+
+// Mul returns d * other.
+func (d Decimal) Mul(other Decimal) Decimal {
+	coef := new(big.Int).Mul(d.coefficient(), other.coefficient())
+	return Decimal{coef: coef, exp: d.exp + other.exp}
+}
+
+// Div returns d / other rounded half away from zero to the given number of
+// decimal places. It panics if other is zero.
+func (d Decimal) Div(other Decimal, places int32) Decimal {
+	if other.IsZero() {
+		panic("types: decimal division by zero")
+	}
+	num := new(big.Int).Set(d.coefficient())
+	den := new(big.Int).Set(other.coefficient())
+	if shift := int64(d.exp) - int64(other.exp) + int64(places); shift >= 0 {
+		num.Mul(num, pow10(shift))
+	} else {
+		den.Mul(den, pow10(-shift))
+	}
+	return Decimal{coef: divRound(num, den), exp: -places}
+}
+
+// divRound returns num / den rounded half away from zero.
+func divRound(num, den *big.Int) *big.Int {
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Sign() != 0 {
+		twice := new(big.Int).Abs(rem)
+		twice.Lsh(twice, 1)
+		if twice.Cmp(new(big.Int).Abs(den)) >= 0 {
+			if num.Sign()*den.Sign() < 0 {
+				quo.Sub(quo, bigOne)
+			} else {
+				quo.Add(quo, bigOne)
+			}
+		}
+	}
+	return quo
+}
+
+// Neg returns -d.
+func (d Decimal) Neg() Decimal {
+	return Decimal{coef: new(big.Int).Neg(d.coefficient()), exp: d.exp}
+}
+
+// Abs returns the absolute value of d.
+func (d Decimal) Abs() Decimal {
+	return Decimal{coef: new(big.Int).Abs(d.coefficient()), exp: d.exp}
+}
+
+// Round rounds d half away from zero to the given number of decimal places.
+func (d Decimal) Round(places int32) Decimal {
+	if -d.exp <= places {
+		return d
+	}
+	den := pow10(int64(-d.exp) - int64(places))
+	return Decimal{coef: divRound(d.coefficient(), den), exp: -places}
+}
+
+// Truncate drops digits beyond the given number of decimal places,
+// rounding toward zero.
+func (d Decimal) Truncate(places int32) Decimal {
+	if -d.exp <= places {
+		return d
+	}
+	den := pow10(int64(-d.exp) - int64(places))
+	return Decimal{coef: new(big.Int).Quo(d.coefficient(), den), exp: -places}
+}
+
+// QuantizeDown rounds d down to a multiple of step, e.g. a market's step
+// size, with the precision of step. It returns d unchanged if step is not
+// positive.
+func (d Decimal) QuantizeDown(step Decimal) Decimal {
+	return d.quantize(step, func(q, r, _ *big.Int) {})
+}
+
+// QuantizeUp rounds d up to a multiple of step. It returns d unchanged if
+// step is not positive.
+func (d Decimal) QuantizeUp(step Decimal) Decimal {
+	return d.quantize(step, func(q, r, _ *big.Int) {
+		if r.Sign() != 0 {
+			q.Add(q, bigOne)
+		}
+	})
+}
+
+// Quantize rounds d to the nearest multiple of step, rounding halves up.
+// It returns d unchanged if step is not positive.
+func (d Decimal) Quantize(step Decimal) Decimal {
+	return d.quantize(step, func(q, r, s *big.Int) {
+		if new(big.Int).Lsh(r, 1).Cmp(s) >= 0 {
+			q.Add(q, bigOne)
+		}
+	})
+}
+
+// IsMultipleOf reports whether d is an exact multiple of step.
+func (d Decimal) IsMultipleOf(step Decimal) bool {
+	if step.Sign() <= 0 {
+		return true
+	}
+	a, s, _ := d.align(step)
+	return new(big.Int).Mod(a, s).Sign() == 0
+}
+
+func (d Decimal) quantize(step Decimal, adjust func(q, r, s *big.Int)) Decimal {
+	if step.Sign() <= 0 {
+		return d
+	}
+	a, s, _ := d.align(step)
+	// DivMod uses Euclidean division, which floors for a positive divisor.
+	q, r := new(big.Int).DivMod(a, s, new(big.Int))
+	adjust(q, r, s)
+	// Express the result with the precision of step, e.g. "123.45" for a
+	// tick size of "0.01".
+	return Decimal{coef: q.Mul(q, step.coefficient()), exp: step.exp}
+}
+
+// Cmp compares d and other and returns -1, 0 or +1.
+func (d Decimal) Cmp(other Decimal) int {
+	a, b, _ := d.align(other)
+	return a.Cmp(b)
+}
+
+// Equal reports whether d and other have the same value.
+func (d Decimal) Equal(other Decimal) bool {
+	return d.Cmp(other) == 0
+}
+
+// LessThan reports whether d < other.
+func (d Decimal) LessThan(other Decimal) bool {
+	return d.Cmp(other) < 0
+}
+
+// GreaterThan reports whether d > other.
+func (d Decimal) GreaterThan(other Decimal) bool {
+	return d.Cmp(other) > 0
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of d.
+func (d Decimal) Sign() int {
+	return d.coefficient().Sign()
+}
+
+// IsZero reports whether d is zero.
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// IsNegative reports whether d < 0.
+func (d Decimal) IsNegative() bool {
+	return d.Sign() < 0
+}
+
+// IsPositive reports whether d > 0.
+func (d Decimal) IsPositive() bool {
+	return d.Sign() > 0
+}
+
+// Places returns the number of digits after the decimal point.
+func (d Decimal) Places() int32 {
+	if d.exp >= 0 {
+		return 0
+	}
+	return -d.exp
+}
+
+// Float64 returns the nearest float64 value of d.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// String returns d in plain notation, keeping trailing zeros of the
+// original precision (e.g. "1.50").
+func (d Decimal) String() string {
+	coef := d.coefficient()
+	if d.exp >= 0 {
+		if d.exp == 0 || coef.Sign() == 0 {
+			return coef.String()
+		}
+		return new(big.Int).Mul(coef, pow10(int64(d.exp))).String()
+	}
+
+	digits := new(big.Int).Abs(coef).String()
+	places := int(-d.exp)
+	if len(digits) <= places {
+		digits = strings.Repeat("0", places-len(digits)+1) + digits
+	}
+	point := len(digits) - places
+
+	var sb strings.Builder
+	if coef.Sign() < 0 {
+		sb.WriteByte('-')
+	}
+	sb.WriteString(digits[:point])
+	sb.WriteByte('.')
+	sb.WriteString(digits[point:])
+	return sb.String()
+}
+
+// StringFixed returns d rounded to the given number of decimal places,
+// padded with trailing zeros.
+func (d Decimal) StringFixed(places int32) string {
+	r := d.Round(places)
+	if r.exp > -places {
+		r = Decimal{coef: r.rescale(-places), exp: -places}
+	}
+	return r.String()
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Decimal) UnmarshalText(text []byte) error {
+	parsed, err := ParseDecimal(string(text))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// MarshalJSON encodes d as a JSON string, as the API expects.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON accepts a JSON string or number. Null and empty strings
+// decode as zero.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	if len(data) == 0 || string(data) == "null" {
+		*d = Decimal{}
+		return nil
+	}
+	s := string(data)
+	if data[0] == '"' {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		if s == "" {
+			*d = Decimal{}
+			return nil
+		}
+	}
+	return d.UnmarshalText([]byte(s))
+}
+
+// MinDecimal returns the smaller of a and b.
+func MinDecimal(a, b Decimal) Decimal {
+	if a.Cmp(b) <= 0 {
+		return a
+	}
+	return b
+}
+
+// MaxDecimal returns the larger of a and b.
+func MaxDecimal(a, b Decimal) Decimal {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
+
+// PriceLevel is a price level of an order book.
+type PriceLevel struct {
+	Price    Decimal
+	Quantity Decimal
+}
+
+// parsePriceLevels converts [price, quantity] string pairs to price levels.
+func parsePriceLevels(levels [][]string) ([]PriceLevel, error) {
+	result := make([]PriceLevel, 0, len(levels))
+	for _, level := range levels {
+		if len(level) < 2 {
+			return nil, fmt.Errorf("invalid price level %v", level)
+		}
+		price, err := ParseDecimal(level[0])
+		if err != nil {
+			return nil, err
+		}
+		quantity, err := ParseDecimal(level[1])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, PriceLevel{Price: price, Quantity: quantity})
+	}
+	return result, nil
+}
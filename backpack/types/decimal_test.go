@@ -0,0 +1,224 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"0", "0"},
+		{"123", "123"},
+		{"-0.001", "-0.001"},
+		{"1.50", "1.50"},
+		{".5", "0.5"},
+		{"5.", "5"},
+		{"+7", "7"},
+		{"1.5e-8", "0.000000015"},
+		{"1.5E3", "1500"},
+		{"12e2", "1200"},
+		{"0.000", "0.000"},
+		{"123456789012345678901234567890.123456789", "123456789012345678901234567890.123456789"},
+	}
+	for _, tt := range tests {
+		d, err := ParseDecimal(tt.in)
+		if err != nil {
+			t.Errorf("ParseDecimal(%q): %v", tt.in, err)
+			continue
+		}
+		if got := d.String(); got != tt.want {
+			t.Errorf("ParseDecimal(%q).String() = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseDecimalInvalid(t *testing.T) {
+	for _, in := range []string{"", "abc", "1.2.3", "1e", "1e2.5", "--1", "1.-2", "0x10", "1,5", "NaN", "1e99999999999"} {
+		if d, err := ParseDecimal(in); err == nil {
+			t.Errorf("ParseDecimal(%q) = %s, want error", in, d)
+		}
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	tests := []struct {
+		a, b          string
+		sum, diff, pr string
+	}{
+		{"0.1", "0.2", "0.3", "-0.1", "0.02"},
+		{"1.50", "2.5", "4.00", "-1.00", "3.750"},
+		{"-3", "0.001", "-2.999", "-3.001", "-0.003"},
+		{"100", "1e2", "200", "0", "10000"},
+		{"0", "-0.5", "-0.5", "0.5", "0.0"},
+	}
+	for _, tt := range tests {
+		a, b := MustParseDecimal(tt.a), MustParseDecimal(tt.b)
+		if got := a.Add(b).String(); got != tt.sum {
+			t.Errorf("%s + %s = %s, want %s", tt.a, tt.b, got, tt.sum)
+		}
+		if got := a.Sub(b).String(); got != tt.diff {
+			t.Errorf("%s - %s = %s, want %s", tt.a, tt.b, got, tt.diff)
+		}
+		if got := a.Mul(b).String(); got != tt.pr {
+			t.Errorf("%s * %s = %s, want %s", tt.a, tt.b, got, tt.pr)
+		}
+	}
+}
+
+func TestDecimalZeroValue(t *testing.T) {
+	var d Decimal
+	if !d.IsZero() || d.String() != "0" {
+		t.Errorf("zero value = %s, IsZero %v", d, d.IsZero())
+	}
+	if got := d.Add(MustParseDecimal("1.5")).String(); got != "1.5" {
+		t.Errorf("0 + 1.5 = %s, want 1.5", got)
+	}
+}
+
+func TestDecimalDiv(t *testing.T) {
+	tests := []struct {
+		a, b   string
+		places int32
+		want   string
+	}{
+		{"1", "3", 4, "0.3333"},
+		{"2", "3", 4, "0.6667"},
+		{"-2", "3", 4, "-0.6667"},
+		{"2", "-3", 4, "-0.6667"},
+		{"1", "8", 2, "0.13"},   // 0.125 rounds half away from zero
+		{"-1", "8", 2, "-0.13"}, // -0.125 rounds half away from zero
+		{"10", "4", 0, "3"},     // 2.5 rounds to 3
+		{"100", "0.25", 2, "400.00"},
+		{"0.0001", "100", 8, "0.00000100"},
+		{"12345", "1", -2, "12300"},
+	}
+	for _, tt := range tests {
+		got := MustParseDecimal(tt.a).Div(MustParseDecimal(tt.b), tt.places).String()
+		if got != tt.want {
+			t.Errorf("%s / %s (%d places) = %s, want %s", tt.a, tt.b, tt.places, got, tt.want)
+		}
+	}
+}
+
+func TestDecimalDivByZeroPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Div by zero did not panic")
+		}
+	}()
+	MustParseDecimal("1").Div(Decimal{}, 2)
+}
+
+func TestDecimalRounding(t *testing.T) {
+	tests := []struct {
+		in           string
+		places       int32
+		round, trunc string
+	}{
+		{"1.2345", 2, "1.23", "1.23"},
+		{"1.235", 2, "1.24", "1.23"},
+		{"-1.235", 2, "-1.24", "-1.23"},
+		{"1.5", 0, "2", "1"},
+		{"-1.5", 0, "-2", "-1"},
+		{"1.2", 4, "1.2", "1.2"},
+	}
+	for _, tt := range tests {
+		d := MustParseDecimal(tt.in)
+		if got := d.Round(tt.places).String(); got != tt.round {
+			t.Errorf("Round(%s, %d) = %s, want %s", tt.in, tt.places, got, tt.round)
+		}
+		if got := d.Truncate(tt.places).String(); got != tt.trunc {
+			t.Errorf("Truncate(%s, %d) = %s, want %s", tt.in, tt.places, got, tt.trunc)
+		}
+	}
+}
+
+func TestDecimalQuantize(t *testing.T) {
+	tests := []struct {
+		in, step          string
+		down, up, nearest string
+	}{
+		{"123.456", "0.01", "123.45", "123.46", "123.46"},
+		{"123.454", "0.01", "123.45", "123.46", "123.45"},
+		{"123.455", "0.01", "123.45", "123.46", "123.46"},
+		{"123.45", "0.01", "123.45", "123.45", "123.45"},
+		{"7", "0.5", "7.0", "7.0", "7.0"},
+		{"7.3", "0.5", "7.0", "7.5", "7.5"},
+		{"7.2", "0.5", "7.0", "7.5", "7.0"},
+		{"-7.3", "0.5", "-7.5", "-7.0", "-7.5"},
+		{"0.0004", "0.001", "0.000", "0.001", "0.000"},
+		{"1234", "100", "1200", "1300", "1200"},
+		{"1.5", "0", "1.5", "1.5", "1.5"},
+	}
+	for _, tt := range tests {
+		d, step := MustParseDecimal(tt.in), MustParseDecimal(tt.step)
+		if got := d.QuantizeDown(step).String(); got != tt.down {
+			t.Errorf("QuantizeDown(%s, %s) = %s, want %s", tt.in, tt.step, got, tt.down)
+		}
+		if got := d.QuantizeUp(step).String(); got != tt.up {
+			t.Errorf("QuantizeUp(%s, %s) = %s, want %s", tt.in, tt.step, got, tt.up)
+		}
+		if got := d.Quantize(step).String(); got != tt.nearest {
+			t.Errorf("Quantize(%s, %s) = %s, want %s", tt.in, tt.step, got, tt.nearest)
+		}
+	}
+}
+
+func TestDecimalCompare(t *testing.T) {
+	a, b := MustParseDecimal("1.50"), MustParseDecimal("1.5")
+	if !a.Equal(b) || a.Cmp(b) != 0 {
+		t.Errorf("1.50 and 1.5 compare unequal")
+	}
+	if !MustParseDecimal("-0.01").LessThan(Decimal{}) {
+		t.Error("-0.01 < 0 = false")
+	}
+	if !MustParseDecimal("1e-9").IsPositive() {
+		t.Error("1e-9 is not positive")
+	}
+	if !MustParseDecimal("0.30").IsMultipleOf(MustParseDecimal("0.1")) || MustParseDecimal("0.35").IsMultipleOf(MustParseDecimal("0.1")) {
+		t.Error("IsMultipleOf mismatch")
+	}
+}
+
+func TestDecimalStringFixed(t *testing.T) {
+	tests := []struct {
+		in     string
+		places int32
+		want   string
+	}{
+		{"1.5", 3, "1.500"},
+		{"1.2345", 2, "1.23"},
+		{"2", 2, "2.00"},
+		{"-0.005", 2, "-0.01"},
+	}
+	for _, tt := range tests {
+		if got := MustParseDecimal(tt.in).StringFixed(tt.places); got != tt.want {
+			t.Errorf("StringFixed(%s, %d) = %s, want %s", tt.in, tt.places, got, tt.want)
+		}
+	}
+}
+
+func TestDecimalJSON(t *testing.T) {
+	var v struct {
+		A Decimal `json:"a"`
+		B Decimal `json:"b"`
+		C Decimal `json:"c"`
+		D Decimal `json:"d"`
+	}
+	if err := json.Unmarshal([]byte(`{"a":"1.25","b":0.5,"c":"","d":null}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.A.String() != "1.25" || v.B.String() != "0.5" || !v.C.IsZero() || !v.D.IsZero() {
+		t.Errorf("decoded %s %s %s %s", v.A, v.B, v.C, v.D)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"a":"1.25","b":"0.5","c":"0","d":"0"}`; string(data) != want {
+		t.Errorf("encoded %s, want %s", data, want)
+	}
+}
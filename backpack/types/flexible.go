@@ -1,6 +1,11 @@
 package types
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
 
 // TimeString accepts either a JSON string or number and stores it as a string.
 type TimeString string
@@ -26,3 +31,23 @@ func (t *TimeString) UnmarshalJSON(data []byte) error {
 	*t = TimeString(n.String())
 	return nil
 }
+
+// Time parses the timestamp. Numbers are Unix timestamps in milliseconds,
+// or microseconds if too large for milliseconds; strings are UTC times
+// such as "2024-01-02T15:04:05.000".
+func (t TimeString) Time() (time.Time, error) {
+	if n, err := strconv.ParseInt(string(t), 10, 64); err == nil {
+		if n < 1e14 {
+			return time.UnixMilli(n), nil
+		}
+		return time.UnixMicro(n), nil
+	}
+	if v, err := time.Parse(time.RFC3339Nano, string(t)); err == nil {
+		return v, nil
+	}
+	v, err := time.Parse("2006-01-02T15:04:05.999999999", string(t))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q", string(t))
+	}
+	return v, nil
+}
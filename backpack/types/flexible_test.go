@@ -0,0 +1,23 @@
+package types
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeStringTime(t *testing.T) {
+	want := time.Date(2024, 1, 2, 15, 4, 5, 123000000, time.UTC)
+	for _, in := range []TimeString{"1704207845123", "1704207845123000", "2024-01-02T15:04:05.123", "2024-01-02T15:04:05.123Z"} {
+		got, err := in.Time()
+		if err != nil {
+			t.Errorf("TimeString(%q).Time(): %v", in, err)
+			continue
+		}
+		if !got.Equal(want) {
+			t.Errorf("TimeString(%q).Time() = %s, want %s", in, got, want)
+		}
+	}
+	if _, err := TimeString("yesterday").Time(); err == nil {
+		t.Error("TimeString(\"yesterday\").Time() succeeded")
+	}
+}
@@ -250,3 +250,18 @@ type PositionHistoryItem struct {
 	OpenedAt            string               `json:"openedAt,omitempty"`
 	ClosedAt            string               `json:"closedAt,omitempty"`
 }
+
+// PriceDecimal returns the fill price as a Decimal.
+func (f *Fill) PriceDecimal() (Decimal, error) {
+	return parseDecimalField(f.Price)
+}
+
+// QuantityDecimal returns the fill quantity as a Decimal.
+func (f *Fill) QuantityDecimal() (Decimal, error) {
+	return parseDecimalField(f.Quantity)
+}
+
+// FeeDecimal returns the fee as a Decimal.
+func (f *Fill) FeeDecimal() (Decimal, error) {
+	return parseDecimalField(f.Fee)
+}
@@ -98,3 +98,73 @@ type FundingRate struct {
 	IntervalEndTimestamp string `json:"intervalEndTimestamp"`
 	FundingRate          string `json:"fundingRate"`
 }
+
+// TickSizeDecimal returns the tick size as a Decimal.
+func (f *PriceFilter) TickSizeDecimal() (Decimal, error) {
+	return parseDecimalField(f.TickSize)
+}
+
+// MinPriceDecimal returns the minimum price as a Decimal.
+func (f *PriceFilter) MinPriceDecimal() (Decimal, error) {
+	return parseDecimalField(f.MinPrice)
+}
+
+// MaxPriceDecimal returns the maximum price as a Decimal.
+func (f *PriceFilter) MaxPriceDecimal() (Decimal, error) {
+	return parseDecimalField(f.MaxPrice)
+}
+
+// StepSizeDecimal returns the step size as a Decimal.
+func (f *QuantityFilter) StepSizeDecimal() (Decimal, error) {
+	return parseDecimalField(f.StepSize)
+}
+
+// MinQuantityDecimal returns the minimum quantity as a Decimal.
+func (f *QuantityFilter) MinQuantityDecimal() (Decimal, error) {
+	return parseDecimalField(f.MinQuantity)
+}
+
+// MaxQuantityDecimal returns the maximum quantity as a Decimal.
+func (f *QuantityFilter) MaxQuantityDecimal() (Decimal, error) {
+	return parseDecimalField(f.MaxQuantity)
+}
+
+// LastPriceDecimal returns the last price as a Decimal.
+func (t *Ticker) LastPriceDecimal() (Decimal, error) {
+	return parseDecimalField(t.LastPrice)
+}
+
+// VolumeDecimal returns the base volume as a Decimal.
+func (t *Ticker) VolumeDecimal() (Decimal, error) {
+	return parseDecimalField(t.Volume)
+}
+
+// QuoteVolumeDecimal returns the quote volume as a Decimal.
+func (t *Ticker) QuoteVolumeDecimal() (Decimal, error) {
+	return parseDecimalField(t.QuoteVolume)
+}
+
+// MarkPriceDecimal returns the mark price as a Decimal.
+func (m *MarkPrice) MarkPriceDecimal() (Decimal, error) {
+	return parseDecimalField(m.MarkPrice)
+}
+
+// IndexPriceDecimal returns the index price as a Decimal.
+func (m *MarkPrice) IndexPriceDecimal() (Decimal, error) {
+	return parseDecimalField(m.IndexPrice)
+}
+
+// FundingRateDecimal returns the funding rate as a Decimal.
+func (m *MarkPrice) FundingRateDecimal() (Decimal, error) {
+	return parseDecimalField(m.FundingRate)
+}
+
+// AskLevels returns the ask levels as decimals.
+func (b *OrderBook) AskLevels() ([]PriceLevel, error) {
+	return parsePriceLevels(b.Asks)
+}
+
+// BidLevels returns the bid levels as decimals.
+func (b *OrderBook) BidLevels() ([]PriceLevel, error) {
+	return parsePriceLevels(b.Bids)
+}
@@ -176,8 +176,66 @@ type CancelAllOrdersParams struct {
 	OrderType enums.CancelOrderType `json:"orderType,omitempty"`
 }
 
+// ReplaceOrderParams represents parameters for replacing a resting order.
+type ReplaceOrderParams struct {
+	Symbol   string  `json:"symbol"`
+	OrderID  string  `json:"orderId,omitempty"`
+	ClientID *uint32 `json:"clientId,omitempty"` // Identifies the order if OrderID is empty
+	// Price is the new limit price. Empty keeps the current price.
+	Price string `json:"price,omitempty"`
+	// Quantity is the new total quantity, including what has already been
+	// executed. Empty keeps the current quantity.
+	Quantity string `json:"quantity,omitempty"`
+	// NewClientID is the client ID of the replacement. Nil reuses the
+	// client ID of the replaced order.
+	NewClientID *uint32 `json:"newClientId,omitempty"`
+	// ReduceOnly makes the replacement reduce-only. Set it when replacing
+	// a reduce-only order, since orders do not report the flag.
+	ReduceOnly bool `json:"reduceOnly,omitempty"`
+}
+
+// ReplaceOrderResult reports the outcome of replacing an order.
+type ReplaceOrderResult struct {
+	// Cancelled is the replaced order as cancelled, including any fills
+	// that happened before the cancel took effect.
+	Cancelled *Order `json:"cancelled,omitempty"`
+	// Replacement is the order placed in its stead, or nil if nothing was
+	// placed because the new quantity was already executed.
+	Replacement *Order `json:"replacement,omitempty"`
+}
+
 // BatchOrderResult represents the result of a batch order.
 type BatchOrderResult struct {
 	Order *Order `json:"order,omitempty"`
 	Error string `json:"error,omitempty"`
 }
+
+// PriceDecimal returns the limit price as a Decimal.
+func (o *Order) PriceDecimal() (Decimal, error) {
+	return parseDecimalField(o.Price)
+}
+
+// QuantityDecimal returns the order quantity as a Decimal.
+func (o *Order) QuantityDecimal() (Decimal, error) {
+	return parseDecimalField(o.Quantity)
+}
+
+// QuoteQuantityDecimal returns the order quote quantity as a Decimal.
+func (o *Order) QuoteQuantityDecimal() (Decimal, error) {
+	return parseDecimalField(o.QuoteQuantity)
+}
+
+// ExecutedQuantityDecimal returns the executed quantity as a Decimal.
+func (o *Order) ExecutedQuantityDecimal() (Decimal, error) {
+	return parseDecimalField(o.ExecutedQuantity)
+}
+
+// ExecutedQuoteQuantityDecimal returns the executed quote quantity as a Decimal.
+func (o *Order) ExecutedQuoteQuantityDecimal() (Decimal, error) {
+	return parseDecimalField(o.ExecutedQuoteQuantity)
+}
+
+// TriggerPriceDecimal returns the trigger price as a Decimal.
+func (o *Order) TriggerPriceDecimal() (Decimal, error) {
+	return parseDecimalField(o.TriggerPrice)
+}
@@ -25,3 +25,43 @@ type Position struct {
 }
 
 // Note: PositionImfFunction is defined in assets.go
+
+// EntryPriceDecimal returns the entry price as a Decimal.
+func (p *Position) EntryPriceDecimal() (Decimal, error) {
+	return parseDecimalField(p.EntryPrice)
+}
+
+// BreakEvenPriceDecimal returns the break-even price as a Decimal.
+func (p *Position) BreakEvenPriceDecimal() (Decimal, error) {
+	return parseDecimalField(p.BreakEvenPrice)
+}
+
+// EstLiquidationPriceDecimal returns the estimated liquidation price as a Decimal.
+func (p *Position) EstLiquidationPriceDecimal() (Decimal, error) {
+	return parseDecimalField(p.EstLiquidationPrice)
+}
+
+// MarkPriceDecimal returns the mark price as a Decimal.
+func (p *Position) MarkPriceDecimal() (Decimal, error) {
+	return parseDecimalField(p.MarkPrice)
+}
+
+// NetQuantityDecimal returns the signed net quantity as a Decimal.
+func (p *Position) NetQuantityDecimal() (Decimal, error) {
+	return parseDecimalField(p.NetQuantity)
+}
+
+// NetExposureNotionalDecimal returns the net exposure notional as a Decimal.
+func (p *Position) NetExposureNotionalDecimal() (Decimal, error) {
+	return parseDecimalField(p.NetExposureNotional)
+}
+
+// PnlRealizedDecimal returns the realized PnL as a Decimal.
+func (p *Position) PnlRealizedDecimal() (Decimal, error) {
+	return parseDecimalField(p.PnlRealized)
+}
+
+// PnlUnrealizedDecimal returns the unrealized PnL as a Decimal.
+func (p *Position) PnlUnrealizedDecimal() (Decimal, error) {
+	return parseDecimalField(p.PnlUnrealized)
+}
@@ -213,3 +213,125 @@ type WSRFQUpdate struct {
 	Status         enums.OrderStatus `json:"X,omitempty"`
 	EngineTimestamp int64            `json:"T,omitempty"`
 }
+
+// AskPriceDecimal returns the best ask price as a Decimal.
+func (t *WSBookTicker) AskPriceDecimal() (Decimal, error) {
+	return parseDecimalField(t.AskPrice)
+}
+
+// AskQtyDecimal returns the best ask quantity as a Decimal.
+func (t *WSBookTicker) AskQtyDecimal() (Decimal, error) {
+	return parseDecimalField(t.AskQty)
+}
+
+// BidPriceDecimal returns the best bid price as a Decimal.
+func (t *WSBookTicker) BidPriceDecimal() (Decimal, error) {
+	return parseDecimalField(t.BidPrice)
+}
+
+// BidQtyDecimal returns the best bid quantity as a Decimal.
+func (t *WSBookTicker) BidQtyDecimal() (Decimal, error) {
+	return parseDecimalField(t.BidQty)
+}
+
+// PriceDecimal returns the trade price as a Decimal.
+func (t *WSTrade) PriceDecimal() (Decimal, error) {
+	return parseDecimalField(t.Price)
+}
+
+// QuantityDecimal returns the trade quantity as a Decimal.
+func (t *WSTrade) QuantityDecimal() (Decimal, error) {
+	return parseDecimalField(t.Quantity)
+}
+
+// MarkPriceDecimal returns the mark price as a Decimal.
+func (m *WSMarkPrice) MarkPriceDecimal() (Decimal, error) {
+	return parseDecimalField(m.MarkPrice)
+}
+
+// IndexPriceDecimal returns the index price as a Decimal.
+func (m *WSMarkPrice) IndexPriceDecimal() (Decimal, error) {
+	return parseDecimalField(m.IndexPrice)
+}
+
+// FundingRateDecimal returns the funding rate as a Decimal.
+func (m *WSMarkPrice) FundingRateDecimal() (Decimal, error) {
+	return parseDecimalField(m.FundingRate)
+}
+
+// PriceDecimal returns the limit price as a Decimal.
+func (u *WSOrderUpdate) PriceDecimal() (Decimal, error) {
+	return parseDecimalField(u.Price)
+}
+
+// QuantityDecimal returns the order quantity as a Decimal.
+func (u *WSOrderUpdate) QuantityDecimal() (Decimal, error) {
+	return parseDecimalField(u.Quantity)
+}
+
+// TriggerPriceDecimal returns the trigger price as a Decimal.
+func (u *WSOrderUpdate) TriggerPriceDecimal() (Decimal, error) {
+	return parseDecimalField(u.TriggerPrice)
+}
+
+// FillPriceDecimal returns the price of the latest fill as a Decimal.
+func (u *WSOrderUpdate) FillPriceDecimal() (Decimal, error) {
+	return parseDecimalField(u.FillPrice)
+}
+
+// FillQuantityDecimal returns the quantity of the latest fill as a Decimal.
+func (u *WSOrderUpdate) FillQuantityDecimal() (Decimal, error) {
+	return parseDecimalField(u.FillQuantity)
+}
+
+// ExecutedQuantityDecimal returns the cumulative executed quantity as a Decimal.
+func (u *WSOrderUpdate) ExecutedQuantityDecimal() (Decimal, error) {
+	return parseDecimalField(u.ExecutedQuantity)
+}
+
+// ExecutedQuoteQuantityDecimal returns the cumulative executed quote quantity as a Decimal.
+func (u *WSOrderUpdate) ExecutedQuoteQuantityDecimal() (Decimal, error) {
+	return parseDecimalField(u.ExecutedQuoteQuantity)
+}
+
+// FeeDecimal returns the fee of the latest fill as a Decimal.
+func (u *WSOrderUpdate) FeeDecimal() (Decimal, error) {
+	return parseDecimalField(u.Fee)
+}
+
+// EntryPriceDecimal returns the entry price as a Decimal.
+func (u *WSPositionUpdate) EntryPriceDecimal() (Decimal, error) {
+	return parseDecimalField(u.EntryPrice)
+}
+
+// MarkPriceDecimal returns the mark price as a Decimal.
+func (u *WSPositionUpdate) MarkPriceDecimal() (Decimal, error) {
+	return parseDecimalField(u.MarkPrice)
+}
+
+// NetQuantityDecimal returns the signed net quantity as a Decimal.
+func (u *WSPositionUpdate) NetQuantityDecimal() (Decimal, error) {
+	return parseDecimalField(u.NetQuantity)
+}
+
+// PnlRealizedDecimal returns the realized PnL as a Decimal.
+func (u *WSPositionUpdate) PnlRealizedDecimal() (Decimal, error) {
+	return parseDecimalField(u.PnlRealized)
+}
+
+// PnlUnrealizedDecimal returns the unrealized PnL as a Decimal.
+func (u *WSPositionUpdate) PnlUnrealizedDecimal() (Decimal, error) {
+	return parseDecimalField(u.PnlUnrealized)
+}
+
+// AskLevels returns the updated ask levels as decimals.
+// A zero quantity means the level was removed.
+func (d *WSDepth) AskLevels() ([]PriceLevel, error) {
+	return parsePriceLevels(d.Asks)
+}
+
+// BidLevels returns the updated bid levels as decimals.
+// A zero quantity means the level was removed.
+func (d *WSDepth) BidLevels() ([]PriceLevel, error) {
+	return parsePriceLevels(d.Bids)
+}
@@ -4,14 +4,18 @@ package websocket
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/internal/auth"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/signing"
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/timesync"
 )
 
 const (
@@ -29,15 +33,47 @@ const (
 type Client struct {
 	url       string
 	signer    *auth.Signer
+	clock     timesync.Clock
 	window    int64
 	conn      *websocket.Conn
 	mu        sync.RWMutex
-	callbacks map[string][]func(json.RawMessage)
+	dialMu    sync.Mutex
+	callbacks map[string][]*subscriber
 	privateStreams map[string]bool
 	done      chan struct{}
 	reconnect bool
 	connected bool
 	dialer    *websocket.Dialer
+
+	// Ordered delivery
+	ordered   bool
+	queueSize int
+	overflow  OverflowPolicy
+	queues    map[string]*streamQueue
+	dropped   atomic.Uint64
+
+	// Lifecycle
+	state     atomic.Int32
+	writeMu   sync.Mutex
+	nextID    atomic.Uint64
+	nextSubID atomic.Uint64
+	requests  map[uint64]request
+	eventsMu  sync.RWMutex
+	events    events
+
+	// Stream monitor
+	monitor    *MonitorConfig
+	healthMu   sync.Mutex
+	health     map[string]*streamHealth
+	dropReason error
+
+	recorder *Recorder
+}
+
+// request is a SUBSCRIBE or UNSUBSCRIBE request awaiting its acknowledgement.
+type request struct {
+	method  string
+	streams []string
 }
 
 // Option is a functional option for configuring the WebSocket client.
@@ -63,6 +99,15 @@ func WithCredentials(publicKey, secretKey string) Option {
 	}
 }
 
+// WithSigner sets a custom signer for authenticated streams, e.g. one that
+// keeps the private key in a separate process.
+func WithSigner(signer signing.Signer) Option {
+	return func(c *Client) error {
+		c.signer = auth.New(signer)
+		return nil
+	}
+}
+
 // WithWSWindow sets the signature validity window.
 func WithWSWindow(window int64) Option {
 	return func(c *Client) error {
@@ -71,6 +116,15 @@ func WithWSWindow(window int64) Option {
 	}
 }
 
+// WithClock sets the clock used to timestamp subscription signatures,
+// e.g. a *timesync.Syncer shared with the REST client.
+func WithClock(clock timesync.Clock) Option {
+	return func(c *Client) error {
+		c.clock = clock
+		return nil
+	}
+}
+
 // WithAutoReconnect enables/disables automatic reconnection.
 func WithAutoReconnect(enabled bool) Option {
 	return func(c *Client) error {
@@ -82,13 +136,15 @@ func WithAutoReconnect(enabled bool) Option {
 // NewClient creates a new WebSocket client.
 func NewClient(opts ...Option) (*Client, error) {
 	c := &Client{
-		url:       DefaultWSURL,
-		window:    DefaultWindow,
-		callbacks: make(map[string][]func(json.RawMessage)),
+		url:            DefaultWSURL,
+		window:         DefaultWindow,
+		callbacks:      make(map[string][]*subscriber),
 		privateStreams: make(map[string]bool),
-		done:      make(chan struct{}),
-		reconnect: true,
-		dialer:    websocket.DefaultDialer,
+		queues:         make(map[string]*streamQueue),
+		requests:       make(map[uint64]request),
+		done:           make(chan struct{}),
+		reconnect:      true,
+		dialer:         websocket.DefaultDialer,
 	}
 
 	for _, opt := range opts {
@@ -102,32 +158,75 @@ func NewClient(opts ...Option) (*Client, error) {
 
 // Connect establishes a WebSocket connection.
 func (c *Client) Connect(ctx context.Context) error {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	connected, err := c.dial(ctx, StateConnecting)
+	if err != nil {
+		return err
+	}
+	if connected {
+		c.emitConnect()
+	}
+	return nil
+}
 
+// dial establishes a new connection unless one is already up, and reports
+// whether it did. pending is the state reported while dialing.
+func (c *Client) dial(ctx context.Context, pending State) (bool, error) {
+	// dialMu keeps dials one at a time; c.mu is not held while dialing so
+	// that subscribing, Close and the accessors do not wait on the network.
+	c.dialMu.Lock()
+	defer c.dialMu.Unlock()
+
+	c.mu.Lock()
 	if c.connected {
-		return nil
+		c.mu.Unlock()
+		return false, nil
+	}
+	if pending == StateReconnecting && !c.reconnect {
+		c.mu.Unlock()
+		return false, fmt.Errorf("websocket: client closed")
 	}
+	prev := c.State()
+	c.setState(pending)
+	c.mu.Unlock()
 
 	conn, resp, err := c.dialer.DialContext(ctx, c.url, http.Header{})
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if err != nil {
+		if c.State() == pending {
+			c.setState(prev)
+		}
 		if resp != nil {
-			return fmt.Errorf("websocket dial failed with status %d: %w", resp.StatusCode, err)
+			return false, fmt.Errorf("websocket dial failed with status %d: %w", resp.StatusCode, err)
 		}
-		return fmt.Errorf("websocket dial failed: %w", err)
+		return false, fmt.Errorf("websocket dial failed: %w", err)
+	}
+	if c.State() == StateClosed {
+		// Closed while dialing.
+		conn.Close()
+		return false, fmt.Errorf("websocket: client closed")
 	}
 
 	c.conn = conn
 	c.connected = true
 	c.done = make(chan struct{})
+	c.requests = make(map[uint64]request)
+	c.setState(StateConnected)
 
 	// Start message reader
-	go c.readLoop()
+	go c.readLoop(conn, c.done)
 
 	// Start ping sender
-	go c.pingLoop()
+	go c.pingLoop(conn, c.done)
 
-	return nil
+	// Start stream monitor
+	if c.monitor != nil {
+		c.resetHealth()
+		go c.monitorLoop(c.done)
+	}
+
+	return true, nil
 }
 
 // Close closes the WebSocket connection.
@@ -135,24 +234,44 @@ func (c *Client) Close() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	if c.State() == StateClosed {
+		return nil
+	}
 	c.reconnect = false
+	c.setState(StateClosed)
+
+	for stream := range c.queues {
+		c.stopQueueLocked(stream)
+	}
 
 	if c.conn != nil {
-		close(c.done)
+		closeDone(c.done)
+		c.connected = false
+		c.writeMu.Lock()
 		err := c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
-		if err != nil {
-			return err
+		c.writeMu.Unlock()
+		if closeErr := c.conn.Close(); err == nil {
+			err = closeErr
 		}
-		c.connected = false
-		return c.conn.Close()
+		return err
 	}
 
 	return nil
 }
 
+// State returns the connection state.
+func (c *Client) State() State {
+	return State(c.state.Load())
+}
+
+func (c *Client) setState(s State) {
+	c.state.Store(int32(s))
+}
+
 // Subscribe subscribes to one or more streams.
 func (c *Client) Subscribe(streams []string, callback func(json.RawMessage)) error {
-	return c.subscribe(streams, callback, false)
+	_, err := c.subscribe(streams, callback, false)
+	return err
 }
 
 // SubscribePrivate subscribes to private authenticated streams.
@@ -160,53 +279,85 @@ func (c *Client) SubscribePrivate(streams []string, callback func(json.RawMessag
 	if c.signer == nil {
 		return fmt.Errorf("signer required for private streams")
 	}
-	return c.subscribe(streams, callback, true)
+	_, err := c.subscribe(streams, callback, true)
+	return err
 }
 
-func (c *Client) subscribe(streams []string, callback func(json.RawMessage), private bool) error {
-	c.mu.Lock()
+// Listen subscribes callback to one or more streams and returns a
+// Subscription that removes only this callback when closed. Unlike
+// Subscribe, nothing stays registered if the request cannot be sent.
+func (c *Client) Listen(streams []string, callback func(json.RawMessage)) (*Subscription, error) {
+	return c.listen(streams, callback, false)
+}
 
-	// Register callbacks
-	for _, stream := range streams {
-		c.callbacks[stream] = append(c.callbacks[stream], callback)
-		if private {
-			c.privateStreams[stream] = true
-		} else if _, ok := c.privateStreams[stream]; !ok {
-			c.privateStreams[stream] = false
-		}
+// ListenPrivate is like Listen for private authenticated streams.
+func (c *Client) ListenPrivate(streams []string, callback func(json.RawMessage)) (*Subscription, error) {
+	if c.signer == nil {
+		return nil, fmt.Errorf("signer required for private streams")
 	}
+	return c.listen(streams, callback, true)
+}
 
-	// Build subscription message
-	msg := map[string]any{
-		"method": "SUBSCRIBE",
-		"params": streams,
+func (c *Client) listen(streams []string, callback func(json.RawMessage), private bool) (*Subscription, error) {
+	id, err := c.subscribe(streams, callback, private)
+	if err != nil {
+		c.remove(id, streams)
+		return nil, err
 	}
+	return newSubscription(streams, func() error {
+		if empty := c.remove(id, streams); len(empty) > 0 && c.IsConnected() {
+			return c.request("UNSUBSCRIBE", empty, nil)
+		}
+		return nil
+	}), nil
+}
+
+// subscribe registers callback and sends the subscription request, and
+// returns the callback's ID. The callback stays registered even if the
+// request fails, so it is subscribed again after reconnecting.
+func (c *Client) subscribe(streams []string, callback func(json.RawMessage), private bool) (uint64, error) {
+	s := c.register(streams, callback, private)
 
 	// Add authentication for private streams
+	var signature []string
 	if private && c.signer != nil {
-		signature, timestamp := c.signer.GenerateWSSignature(c.window)
-		msg["signature"] = []string{
-			c.signer.PublicKey(),
-			signature,
-			strconv.FormatInt(timestamp, 10),
-			strconv.FormatInt(c.window, 10),
+		var err error
+		if signature, err = c.signature(); err != nil {
+			return s.id, err
 		}
 	}
 
-	msgBytes, err := json.Marshal(msg)
-	if err != nil {
-		c.mu.Unlock()
-		return err
-	}
+	return s.id, c.request("SUBSCRIBE", streams, signature)
+}
 
-	conn := c.conn
-	c.mu.Unlock()
+// remove unregisters a callback and returns the streams left without any
+// callback.
+func (c *Client) remove(id uint64, streams []string) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	if conn == nil {
-		return fmt.Errorf("not connected")
+	var empty []string
+	for _, stream := range streams {
+		subs := c.callbacks[stream]
+		for i, s := range subs {
+			if s.id == id {
+				subs = append(subs[:i:i], subs[i+1:]...)
+				break
+			}
+		}
+		if len(subs) > 0 {
+			c.callbacks[stream] = subs
+			continue
+		}
+		if _, ok := c.callbacks[stream]; ok {
+			delete(c.callbacks, stream)
+			delete(c.privateStreams, stream)
+			c.stopQueueLocked(stream)
+			c.untrack(stream)
+			empty = append(empty, stream)
+		}
 	}
-
-	return conn.WriteMessage(websocket.TextMessage, msgBytes)
+	return empty
 }
 
 // Unsubscribe unsubscribes from one or more streams.
@@ -217,102 +368,224 @@ func (c *Client) Unsubscribe(streams []string) error {
 	for _, stream := range streams {
 		delete(c.callbacks, stream)
 		delete(c.privateStreams, stream)
+		c.stopQueueLocked(stream)
+		c.untrack(stream)
 	}
+	c.mu.Unlock()
+
+	return c.request("UNSUBSCRIBE", streams, nil)
+}
 
+// request sends a SUBSCRIBE or UNSUBSCRIBE request. The server's response is
+// reported to OnSubscribeAck listeners.
+func (c *Client) request(method string, streams []string, signature []string) error {
+	id := c.nextID.Add(1)
 	msg := map[string]any{
-		"method": "UNSUBSCRIBE",
+		"method": method,
 		"params": streams,
+		"id":     id,
+	}
+	if signature != nil {
+		msg["signature"] = signature
 	}
 
+	c.mu.Lock()
+	c.requests[id] = request{method: method, streams: streams}
+	c.mu.Unlock()
+
+	if err := c.send(msg); err != nil {
+		c.mu.Lock()
+		delete(c.requests, id)
+		c.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// send writes a JSON message to the connection.
+func (c *Client) send(msg any) error {
 	msgBytes, err := json.Marshal(msg)
 	if err != nil {
-		c.mu.Unlock()
 		return err
 	}
 
+	c.mu.RLock()
 	conn := c.conn
-	c.mu.Unlock()
+	c.mu.RUnlock()
 
 	if conn == nil {
 		return fmt.Errorf("not connected")
 	}
 
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if err := conn.SetWriteDeadline(time.Now().Add(writeWait)); err != nil {
+		return err
+	}
 	return conn.WriteMessage(websocket.TextMessage, msgBytes)
 }
 
-func (c *Client) readLoop() {
+// signature builds the signature parameter for private stream subscriptions.
+func (c *Client) signature() ([]string, error) {
+	timestamp := c.now().UnixMilli()
+	signature, err := c.signer.GenerateWSSignature(timestamp, c.window)
+	if err != nil {
+		return nil, err
+	}
+	return []string{
+		c.signer.PublicKey(),
+		signature,
+		strconv.FormatInt(timestamp, 10),
+		strconv.FormatInt(c.window, 10),
+	}, nil
+}
+
+// now returns the current time from the configured clock.
+func (c *Client) now() time.Time {
+	if c.clock != nil {
+		return c.clock.Now()
+	}
+	return time.Now()
+}
+
+// drop closes the current connection with the given reason, which is
+// reported to OnDisconnect listeners. The client reconnects if enabled.
+func (c *Client) drop(reason error) {
+	c.mu.Lock()
+	conn := c.conn
+	if c.connected {
+		c.dropReason = reason
+	}
+	c.mu.Unlock()
+
+	if conn != nil {
+		conn.Close()
+	}
+}
+
+func (c *Client) readLoop(conn *websocket.Conn, done chan struct{}) {
+	var readErr error
 	defer func() {
 		c.mu.Lock()
-		c.connected = false
+		if c.conn == conn {
+			// Stop the ping and monitor loops of this connection.
+			closeDone(done)
+			c.connected = false
+			if c.dropReason != nil {
+				readErr = c.dropReason
+				c.dropReason = nil
+			}
+		}
+		closed := c.State() == StateClosed
+		reconnect := c.reconnect && !closed
+		switch {
+		case reconnect:
+			c.setState(StateReconnecting)
+		case !closed:
+			c.setState(StateDisconnected)
+		}
 		c.mu.Unlock()
 
-		if c.reconnect {
+		if closed {
+			readErr = nil
+		}
+		c.emitDisconnect(readErr)
+
+		if reconnect {
 			go c.reconnectLoop()
 		}
 	}()
 
-	c.conn.SetPongHandler(func(string) error {
-		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
 	})
 
 	for {
 		select {
-		case <-c.done:
+		case <-done:
 			return
 		default:
-			_, message, err := c.conn.ReadMessage()
+			_, message, err := conn.ReadMessage()
 			if err != nil {
-				if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
-					return
-				}
+				readErr = err
 				return
 			}
 
-			c.handleMessage(message)
+			if c.recorder != nil {
+				if err := c.recorder.Write(time.Now(), message); err != nil {
+					c.emitError(fmt.Errorf("websocket: failed to record message: %w", err))
+				}
+			}
+
+			if err := c.handleMessage(message); err != nil {
+				readErr = err
+				conn.Close()
+				return
+			}
 		}
 	}
 }
 
-func (c *Client) handleMessage(message []byte) {
+// closeDone closes a connection's done channel unless it is already
+// closed. c.mu must be held.
+func closeDone(done chan struct{}) {
+	select {
+	case <-done:
+	default:
+		close(done)
+	}
+}
+
+func (c *Client) handleMessage(message []byte) error {
 	var msg struct {
 		Stream string          `json:"stream"`
 		Data   json.RawMessage `json:"data"`
+		ID     *uint64         `json:"id"`
+		Error  *ServerError    `json:"error"`
 	}
 
 	if err := json.Unmarshal(message, &msg); err != nil {
-		return
+		c.emitError(fmt.Errorf("websocket: invalid message: %w", err))
+		return nil
 	}
 
-	if msg.Stream == "" {
-		return
+	if msg.Stream != "" {
+		c.observe(msg.Stream, msg.Data)
+		return c.dispatch(msg.Stream, msg.Data)
 	}
 
-	c.mu.RLock()
-	callbacks := c.callbacks[msg.Stream]
-	c.mu.RUnlock()
+	if msg.ID != nil {
+		c.mu.Lock()
+		req, ok := c.requests[*msg.ID]
+		delete(c.requests, *msg.ID)
+		c.mu.Unlock()
+
+		if ok {
+			ack := SubscribeAck{ID: *msg.ID, Method: req.method, Streams: req.streams}
+			if msg.Error != nil {
+				ack.Err = msg.Error
+			}
+			c.emitSubscribeAck(ack)
+		}
+	}
 
-	for _, cb := range callbacks {
-		go cb(msg.Data)
+	if msg.Error != nil {
+		c.emitError(msg.Error)
 	}
+	return nil
 }
 
-func (c *Client) pingLoop() {
+func (c *Client) pingLoop(conn *websocket.Conn, done chan struct{}) {
 	ticker := time.NewTicker(pingInterval)
 	defer ticker.Stop()
 
 	for {
 		select {
-		case <-c.done:
+		case <-done:
 			return
 		case <-ticker.C:
-			c.mu.RLock()
-			conn := c.conn
-			c.mu.RUnlock()
-
-			if conn != nil {
-				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
-					return
-				}
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				return
 			}
 		}
 	}
@@ -321,7 +594,7 @@ func (c *Client) pingLoop() {
 func (c *Client) reconnectLoop() {
 	delay := reconnectMinDelay
 
-	for {
+	for attempt := 1; ; attempt++ {
 		c.mu.RLock()
 		shouldReconnect := c.reconnect
 		c.mu.RUnlock()
@@ -333,50 +606,31 @@ func (c *Client) reconnectLoop() {
 		time.Sleep(delay)
 
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		err := c.Connect(ctx)
+		connected, err := c.dial(ctx, StateReconnecting)
 		cancel()
 
 		if err == nil {
-			// Resubscribe to all streams
-			c.mu.RLock()
-			publicStreams := make([]string, 0, len(c.callbacks))
-			privateStreams := make([]string, 0, len(c.callbacks))
-			for stream := range c.callbacks {
-				if c.privateStreams[stream] {
-					privateStreams = append(privateStreams, stream)
-				} else {
-					publicStreams = append(publicStreams, stream)
-				}
+			if connected {
+				c.emitConnect()
 			}
-			c.mu.RUnlock()
-
-			if len(publicStreams) > 0 {
-				msg := map[string]any{
-					"method": "SUBSCRIBE",
-					"params": publicStreams,
-				}
-				msgBytes, _ := json.Marshal(msg)
-				c.conn.WriteMessage(websocket.TextMessage, msgBytes)
-			}
-			if len(privateStreams) > 0 && c.signer != nil {
-				signature, timestamp := c.signer.GenerateWSSignature(c.window)
-				msg := map[string]any{
-					"method": "SUBSCRIBE",
-					"params": privateStreams,
-					"signature": []string{
-						c.signer.PublicKey(),
-						signature,
-						strconv.FormatInt(timestamp, 10),
-						strconv.FormatInt(c.window, 10),
-					},
-				}
-				msgBytes, _ := json.Marshal(msg)
-				c.conn.WriteMessage(websocket.TextMessage, msgBytes)
+			if err := c.resubscribe(); err != nil {
+				// The streams are not flowing on this connection; drop it
+				// so that the next one subscribes again.
+				c.emitError(err)
+				c.drop(err)
+				return
 			}
-
+			c.emitReconnect(attempt)
 			return
 		}
 
+		c.mu.RLock()
+		shouldReconnect = c.reconnect
+		c.mu.RUnlock()
+		if shouldReconnect {
+			c.emitError(fmt.Errorf("websocket: reconnect attempt %d failed: %w", attempt, err))
+		}
+
 		// Exponential backoff
 		delay *= 2
 		if delay > reconnectMaxDelay {
@@ -385,6 +639,55 @@ func (c *Client) reconnectLoop() {
 	}
 }
 
+// resubscribe subscribes the new connection to every registered stream.
+func (c *Client) resubscribe() error {
+	c.mu.RLock()
+	publicStreams := make([]string, 0, len(c.callbacks))
+	privateStreams := make([]string, 0, len(c.callbacks))
+	for stream := range c.callbacks {
+		if c.privateStreams[stream] {
+			privateStreams = append(privateStreams, stream)
+		} else {
+			publicStreams = append(publicStreams, stream)
+		}
+	}
+	c.mu.RUnlock()
+
+	var errs []error
+	if len(publicStreams) > 0 {
+		errs = append(errs, c.request("SUBSCRIBE", publicStreams, nil))
+	}
+	if len(privateStreams) > 0 && c.signer != nil {
+		signature, err := c.signature()
+		if err == nil {
+			err = c.request("SUBSCRIBE", privateStreams, signature)
+		}
+		errs = append(errs, err)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("websocket: resubscribe failed: %w", err)
+	}
+	return nil
+}
+
+// register adds callback to the streams without sending a request; the
+// streams are subscribed when the client next connects.
+func (c *Client) register(streams []string, callback func(json.RawMessage), private bool) *subscriber {
+	c.mu.Lock()
+	s := &subscriber{id: c.nextSubID.Add(1), fn: callback}
+	for _, stream := range streams {
+		c.callbacks[stream] = append(c.callbacks[stream], s)
+		if private {
+			c.privateStreams[stream] = true
+		} else if _, ok := c.privateStreams[stream]; !ok {
+			c.privateStreams[stream] = false
+		}
+	}
+	c.mu.Unlock()
+	c.track(streams)
+	return s
+}
+
 // IsConnected returns whether the client is connected.
 func (c *Client) IsConnected() bool {
 	c.mu.RLock()
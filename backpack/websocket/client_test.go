@@ -0,0 +1,122 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestCloseDuringDial(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	c, err := NewClient(WithWSURL("ws" + strings.TrimPrefix(server.URL, "http")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := make(chan error, 1)
+	go func() { result <- c.Connect(context.Background()) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for c.State() != StateConnecting {
+		if time.Now().After(deadline) {
+			t.Fatal("dial did not start")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Neither call may wait for the handshake.
+	closed := make(chan struct{})
+	go func() {
+		c.IsConnected()
+		c.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Close blocked on a dial in progress")
+	}
+
+	release <- struct{}{}
+	if err := <-result; err == nil {
+		t.Error("Connect succeeded on a closed client")
+	}
+	if c.IsConnected() || c.State() != StateClosed {
+		t.Errorf("after Close: connected %v, state %v", c.IsConnected(), c.State())
+	}
+}
+
+// monitors counts the running stream monitor loops.
+func monitors() int {
+	buf := make([]byte, 1<<20)
+	return strings.Count(string(buf[:runtime.Stack(buf, true)]), "(*Client).monitorLoop")
+}
+
+// waitMonitors waits for the number of running monitor loops to settle at n.
+func waitMonitors(t *testing.T, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for monitors() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d stream monitors running, want %d", monitors(), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestReconnectStopsMonitor(t *testing.T) {
+	server := echoServer(t)
+	c, err := NewClient(
+		WithWSURL("ws"+strings.TrimPrefix(server.URL, "http")),
+		WithAutoReconnect(false),
+		WithStreamMonitor(MonitorConfig{CheckInterval: time.Millisecond}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	for i := 0; i < 5; i++ {
+		if err := c.Connect(context.Background()); err != nil {
+			t.Fatalf("Connect: %v", err)
+		}
+		c.drop(errors.New("dropped"))
+		deadline := time.Now().Add(5 * time.Second)
+		for c.IsConnected() {
+			if time.Now().After(deadline) {
+				t.Fatal("connection was not dropped")
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	waitMonitors(t, 0)
+
+	if err := c.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	waitMonitors(t, 1)
+	c.Close()
+	waitMonitors(t, 0)
+}
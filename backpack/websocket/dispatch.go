@@ -0,0 +1,204 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+)
+
+// DefaultQueueSize is the per-stream buffer size used by ordered delivery
+// when no size is given.
+const DefaultQueueSize = 1024
+
+// ErrQueueOverflow is reported when a stream's delivery queue is full and
+// the overflow policy is OverflowDisconnect.
+var ErrQueueOverflow = errors.New("websocket: delivery queue overflow")
+
+// OverflowPolicy decides what happens to a message when its stream's
+// delivery queue is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock stops reading from the connection until the queue has
+	// room. A slow callback therefore delays every stream.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropOldest discards the oldest queued message to make room.
+	OverflowDropOldest
+	// OverflowDisconnect drops the message and closes the connection, so a
+	// consumer that falls behind resynchronizes after reconnecting.
+	OverflowDisconnect
+)
+
+// String returns the name of the policy.
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowBlock:
+		return "block"
+	case OverflowDropOldest:
+		return "dropOldest"
+	case OverflowDisconnect:
+		return "disconnect"
+	default:
+		return "unknown"
+	}
+}
+
+// WithOrderedDelivery delivers the messages of each stream in order, one
+// at a time, from a dedicated goroutine with a queue of size messages.
+// Without it every message is handed to its callbacks in a new goroutine,
+// so messages may be processed out of order.
+func WithOrderedDelivery(size int, policy OverflowPolicy) Option {
+	return func(c *Client) error {
+		if size <= 0 {
+			size = DefaultQueueSize
+		}
+		c.ordered = true
+		c.queueSize = size
+		c.overflow = policy
+		return nil
+	}
+}
+
+// streamQueue is the ordered delivery queue of a single stream.
+type streamQueue struct {
+	ch      chan json.RawMessage
+	stop    chan struct{}
+	dropped atomic.Uint64
+}
+
+// Dropped returns the number of messages of the given stream discarded
+// because its delivery queue was full.
+func (c *Client) Dropped(stream string) uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if q := c.queues[stream]; q != nil {
+		return q.dropped.Load()
+	}
+	return 0
+}
+
+// TotalDropped returns the number of messages discarded across all streams.
+func (c *Client) TotalDropped() uint64 {
+	return c.dropped.Load()
+}
+
+// QueueLen returns the number of messages of the given stream waiting to be
+// delivered.
+func (c *Client) QueueLen(stream string) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if q := c.queues[stream]; q != nil {
+		return len(q.ch)
+	}
+	return 0
+}
+
+// dispatch hands a message to the callbacks of its stream.
+func (c *Client) dispatch(stream string, data json.RawMessage) error {
+	if !c.ordered {
+		c.mu.RLock()
+		callbacks := c.callbacks[stream]
+		c.mu.RUnlock()
+
+		for _, s := range callbacks {
+			go s.fn(data)
+		}
+		return nil
+	}
+
+	q := c.queue(stream)
+	if q == nil {
+		return nil
+	}
+	return c.enqueue(q, data)
+}
+
+// queue returns the delivery queue of a stream, starting it if needed. It
+// returns nil if nothing is subscribed to the stream or the client is
+// closed.
+func (c *Client) queue(stream string) *streamQueue {
+	c.mu.RLock()
+	q := c.queues[stream]
+	c.mu.RUnlock()
+	if q != nil {
+		return q
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if q := c.queues[stream]; q != nil {
+		return q
+	}
+	// Close stops every queue under c.mu; a message read just before it
+	// must not start a new one.
+	if c.State() == StateClosed || len(c.callbacks[stream]) == 0 {
+		return nil
+	}
+	q = &streamQueue{
+		ch:   make(chan json.RawMessage, c.queueSize),
+		stop: make(chan struct{}),
+	}
+	c.queues[stream] = q
+	go c.deliver(stream, q)
+	return q
+}
+
+func (c *Client) enqueue(q *streamQueue, data json.RawMessage) error {
+	switch c.overflow {
+	case OverflowDropOldest:
+		for {
+			select {
+			case q.ch <- data:
+				return nil
+			default:
+			}
+			select {
+			case <-q.ch:
+				q.dropped.Add(1)
+				c.dropped.Add(1)
+			default:
+			}
+		}
+	case OverflowDisconnect:
+		select {
+		case q.ch <- data:
+			return nil
+		default:
+			q.dropped.Add(1)
+			c.dropped.Add(1)
+			return ErrQueueOverflow
+		}
+	default:
+		select {
+		case q.ch <- data:
+		case <-q.stop:
+		}
+		return nil
+	}
+}
+
+// deliver runs the callbacks of a stream for each queued message in order.
+func (c *Client) deliver(stream string, q *streamQueue) {
+	for {
+		select {
+		case <-q.stop:
+			return
+		case data := <-q.ch:
+			c.mu.RLock()
+			callbacks := c.callbacks[stream]
+			c.mu.RUnlock()
+
+			for _, s := range callbacks {
+				s.fn(data)
+			}
+		}
+	}
+}
+
+// stopQueueLocked stops and removes the delivery queue of a stream.
+func (c *Client) stopQueueLocked(stream string) {
+	if q := c.queues[stream]; q != nil {
+		close(q.stop)
+		delete(c.queues, stream)
+	}
+}
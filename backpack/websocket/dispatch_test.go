@@ -0,0 +1,26 @@
+package websocket
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDispatchAfterClose(t *testing.T) {
+	c, err := NewClient(WithOrderedDelivery(4, OverflowBlock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.callbacks["trade.BTC_USDC"] = []*subscriber{{fn: func(json.RawMessage) {
+		t.Error("callback ran after Close")
+	}}}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.dispatch("trade.BTC_USDC", json.RawMessage(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(c.queues); n != 0 {
+		t.Errorf("dispatch after Close started %d queues", n)
+	}
+}
@@ -0,0 +1,157 @@
+package websocket
+
+import (
+	"fmt"
+	"time"
+)
+
+// State is the connection state of a Client.
+type State int
+
+const (
+	// StateDisconnected means the client is not connected and will not reconnect on its own.
+	StateDisconnected State = iota
+	// StateConnecting means Connect is dialing the server.
+	StateConnecting
+	// StateConnected means the connection is up.
+	StateConnected
+	// StateReconnecting means the connection was lost and the client is retrying.
+	StateReconnecting
+	// StateClosed means Close was called.
+	StateClosed
+)
+
+// String returns the name of the state.
+func (s State) String() string {
+	switch s {
+	case StateDisconnected:
+		return "disconnected"
+	case StateConnecting:
+		return "connecting"
+	case StateConnected:
+		return "connected"
+	case StateReconnecting:
+		return "reconnecting"
+	case StateClosed:
+		return "closed"
+	default:
+		return "unknown"
+	}
+}
+
+// ServerError is an error message sent by the server.
+type ServerError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *ServerError) Error() string {
+	return fmt.Sprintf("websocket: server error %d: %s", e.Code, e.Message)
+}
+
+// SubscribeAck is the server's response to a SUBSCRIBE or UNSUBSCRIBE request.
+type SubscribeAck struct {
+	ID      uint64
+	Method  string   // "SUBSCRIBE" or "UNSUBSCRIBE"
+	Streams []string // Streams named in the request
+	Err     error    // Non-nil if the server rejected the request
+}
+
+// events holds the registered lifecycle listeners.
+type events struct {
+	onConnect      []func()
+	onDisconnect   []func(error)
+	onReconnect    []func(int)
+	onError        []func(error)
+	onSubscribeAck []func(SubscribeAck)
+	onStale        []func(string, time.Duration)
+}
+
+// OnConnect registers a listener invoked every time a connection is
+// established, including after a reconnect.
+func (c *Client) OnConnect(fn func()) {
+	c.eventsMu.Lock()
+	c.events.onConnect = append(c.events.onConnect, fn)
+	c.eventsMu.Unlock()
+}
+
+// OnDisconnect registers a listener invoked when the connection is lost,
+// with the reason. The error is nil if the connection was closed by Close.
+func (c *Client) OnDisconnect(fn func(error)) {
+	c.eventsMu.Lock()
+	c.events.onDisconnect = append(c.events.onDisconnect, fn)
+	c.eventsMu.Unlock()
+}
+
+// OnReconnect registers a listener invoked after a lost connection has been
+// re-established and its streams resubscribed, with the number of attempts
+// it took. REST state should be resynchronized at this point since
+// messages sent while disconnected are lost.
+func (c *Client) OnReconnect(fn func(attempt int)) {
+	c.eventsMu.Lock()
+	c.events.onReconnect = append(c.events.onReconnect, fn)
+	c.eventsMu.Unlock()
+}
+
+// OnError registers a listener invoked for errors that do not close the
+// connection, such as undecodable messages, errors sent by the server
+// (including rejected subscriptions), failed reconnect attempts and failed
+// resubscriptions.
+func (c *Client) OnError(fn func(error)) {
+	c.eventsMu.Lock()
+	c.events.onError = append(c.events.onError, fn)
+	c.eventsMu.Unlock()
+}
+
+// OnSubscribeAck registers a listener invoked when the server responds to a
+// SUBSCRIBE or UNSUBSCRIBE request.
+func (c *Client) OnSubscribeAck(fn func(SubscribeAck)) {
+	c.eventsMu.Lock()
+	c.events.onSubscribeAck = append(c.events.onSubscribeAck, fn)
+	c.eventsMu.Unlock()
+}
+
+func (c *Client) emitConnect() {
+	c.eventsMu.RLock()
+	listeners := c.events.onConnect
+	c.eventsMu.RUnlock()
+	for _, fn := range listeners {
+		fn()
+	}
+}
+
+func (c *Client) emitDisconnect(err error) {
+	c.eventsMu.RLock()
+	listeners := c.events.onDisconnect
+	c.eventsMu.RUnlock()
+	for _, fn := range listeners {
+		fn(err)
+	}
+}
+
+func (c *Client) emitReconnect(attempt int) {
+	c.eventsMu.RLock()
+	listeners := c.events.onReconnect
+	c.eventsMu.RUnlock()
+	for _, fn := range listeners {
+		fn(attempt)
+	}
+}
+
+func (c *Client) emitError(err error) {
+	c.eventsMu.RLock()
+	listeners := c.events.onError
+	c.eventsMu.RUnlock()
+	for _, fn := range listeners {
+		fn(err)
+	}
+}
+
+func (c *Client) emitSubscribeAck(ack SubscribeAck) {
+	c.eventsMu.RLock()
+	listeners := c.events.onSubscribeAck
+	c.eventsMu.RUnlock()
+	for _, fn := range listeners {
+		fn(ack)
+	}
+}
@@ -0,0 +1,65 @@
+package websocket
+
+import (
+	"context"
+
+	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/types"
+)
+
+// BookTickers returns a feed of book ticker updates.
+func (h *Handler) BookTickers(ctx context.Context, symbol string) (*Feed[types.WSBookTicker], error) {
+	return NewFeed[types.WSBookTicker](ctx, h.client, BookTickerStream(symbol), false)
+}
+
+// Depth returns a feed of order book depth updates.
+func (h *Handler) Depth(ctx context.Context, symbol string) (*Feed[types.WSDepth], error) {
+	return NewFeed[types.WSDepth](ctx, h.client, DepthStream(symbol), false)
+}
+
+// Klines returns a feed of kline/candlestick updates.
+func (h *Handler) Klines(ctx context.Context, symbol, interval string) (*Feed[types.WSKline], error) {
+	return NewFeed[types.WSKline](ctx, h.client, KlineStream(symbol, interval), false)
+}
+
+// Tickers returns a feed of ticker updates.
+func (h *Handler) Tickers(ctx context.Context, symbol string) (*Feed[types.WSTicker], error) {
+	return NewFeed[types.WSTicker](ctx, h.client, TickerStream(symbol), false)
+}
+
+// Trades returns a feed of trade updates.
+func (h *Handler) Trades(ctx context.Context, symbol string) (*Feed[types.WSTrade], error) {
+	return NewFeed[types.WSTrade](ctx, h.client, TradeStream(symbol), false)
+}
+
+// MarkPrices returns a feed of mark price updates.
+func (h *Handler) MarkPrices(ctx context.Context, symbol string) (*Feed[types.WSMarkPrice], error) {
+	return NewFeed[types.WSMarkPrice](ctx, h.client, MarkPriceStream(symbol), false)
+}
+
+// OpenInterest returns a feed of open interest updates.
+func (h *Handler) OpenInterest(ctx context.Context, symbol string) (*Feed[types.WSOpenInterest], error) {
+	return NewFeed[types.WSOpenInterest](ctx, h.client, OpenInterestStream(symbol), false)
+}
+
+// Liquidations returns a feed of liquidation updates.
+func (h *Handler) Liquidations(ctx context.Context, symbol string) (*Feed[types.WSLiquidation], error) {
+	return NewFeed[types.WSLiquidation](ctx, h.client, LiquidationStream(symbol), false)
+}
+
+// OrderUpdates returns a feed of order updates (private stream).
+// If symbol is empty, the feed includes all markets.
+func (h *Handler) OrderUpdates(ctx context.Context, symbol string) (*Feed[types.WSOrderUpdate], error) {
+	return NewFeed[types.WSOrderUpdate](ctx, h.client, OrderUpdateStream(symbol), true)
+}
+
+// PositionUpdates returns a feed of position updates (private stream).
+// If symbol is empty, the feed includes all markets.
+func (h *Handler) PositionUpdates(ctx context.Context, symbol string) (*Feed[types.WSPositionUpdate], error) {
+	return NewFeed[types.WSPositionUpdate](ctx, h.client, PositionUpdateStream(symbol), true)
+}
+
+// RFQUpdates returns a feed of RFQ updates (private stream).
+// If symbol is empty, the feed includes all markets.
+func (h *Handler) RFQUpdates(ctx context.Context, symbol string) (*Feed[types.WSRFQUpdate], error) {
+	return NewFeed[types.WSRFQUpdate](ctx, h.client, RFQUpdateStream(symbol), true)
+}
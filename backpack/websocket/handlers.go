@@ -6,13 +6,23 @@ import (
 	"github.com/solomeowl/backpack-exchange-sdk-go/backpack/types"
 )
 
+// Subscriber registers stream callbacks. It is implemented by Client and
+// by Manager, so a Handler works on a single connection or a pool.
+type Subscriber interface {
+	Subscribe(streams []string, callback func(json.RawMessage)) error
+	SubscribePrivate(streams []string, callback func(json.RawMessage)) error
+	Listen(streams []string, callback func(json.RawMessage)) (*Subscription, error)
+	ListenPrivate(streams []string, callback func(json.RawMessage)) (*Subscription, error)
+	Unsubscribe(streams []string) error
+}
+
 // Handler wraps callbacks for type-safe message handling.
 type Handler struct {
-	client *Client
+	client Subscriber
 }
 
-// NewHandler creates a new Handler for the given client.
-func NewHandler(client *Client) *Handler {
+// NewHandler creates a new Handler for the given client or manager.
+func NewHandler(client Subscriber) *Handler {
 	return &Handler{client: client}
 }
 
@@ -0,0 +1,432 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultMaxStreams is the default number of public streams per connection
+// of a Manager.
+const DefaultMaxStreams = 50
+
+// Manager spreads subscriptions across a pool of connections. Public
+// streams are assigned to the least loaded connection with room, opening a
+// new one when all are full; private streams share a dedicated
+// authenticated connection. Manager implements Subscriber, so it can be
+// used with NewHandler and NewFeed in place of a Client. It is safe for
+// concurrent use.
+type Manager struct {
+	clientOpts  []Option
+	maxStreams  int
+	dialTimeout time.Duration
+
+	mu        sync.RWMutex
+	connected bool
+	closed    bool
+	shards    []*shard
+	private   *shard
+	streams   map[string]*managedStream
+	nextID    uint64
+	onError   []func(error)
+}
+
+// shard is one connection of a Manager.
+type shard struct {
+	client  *Client
+	streams map[string]bool
+}
+
+// managedStream is a stream of a Manager and the callbacks subscribed to it.
+type managedStream struct {
+	name    string
+	private bool
+	shard   *shard
+	subs    []*subscriber
+}
+
+// ManagerOption is a functional option for configuring a Manager.
+type ManagerOption func(*Manager)
+
+// WithMaxStreams sets how many public streams a single connection carries.
+func WithMaxStreams(n int) ManagerOption {
+	return func(m *Manager) {
+		m.maxStreams = n
+	}
+}
+
+// WithClientOptions sets the options used to create each connection, such
+// as the URL, credentials for the private connection, or ordered delivery.
+func WithClientOptions(opts ...Option) ManagerOption {
+	return func(m *Manager) {
+		m.clientOpts = append(m.clientOpts, opts...)
+	}
+}
+
+// NewManager creates a new Manager. Connections are opened as streams are
+// subscribed.
+func NewManager(opts ...ManagerOption) *Manager {
+	m := &Manager{
+		maxStreams:  DefaultMaxStreams,
+		dialTimeout: 10 * time.Second,
+		streams:     make(map[string]*managedStream),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	if m.maxStreams < 1 {
+		m.maxStreams = 1
+	}
+	return m
+}
+
+// Connect connects every connection of the pool and sends the
+// subscriptions registered before connecting. Connections opened later are
+// connected as they are created.
+func (m *Manager) Connect(ctx context.Context) error {
+	m.mu.Lock()
+	m.connected = true
+	shards := m.allShardsLocked()
+	m.mu.Unlock()
+
+	var errs []error
+	for _, sh := range shards {
+		if sh.client.IsConnected() {
+			continue
+		}
+		if err := sh.client.Connect(ctx); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := sh.client.resubscribe(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes every connection of the pool.
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	m.closed = true
+	m.connected = false
+	shards := m.allShardsLocked()
+	m.mu.Unlock()
+
+	var errs []error
+	for _, sh := range shards {
+		if err := sh.client.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Shards returns the connections of the pool, the private connection last
+// if it exists. They can be used to inspect state and stream statistics or
+// to register lifecycle listeners.
+func (m *Manager) Shards() []*Client {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	shards := m.allShardsLocked()
+	clients := make([]*Client, len(shards))
+	for i, sh := range shards {
+		clients[i] = sh.client
+	}
+	return clients
+}
+
+// OnError registers a listener invoked for errors of any connection of the pool.
+func (m *Manager) OnError(fn func(error)) {
+	m.mu.Lock()
+	m.onError = append(m.onError, fn)
+	m.mu.Unlock()
+}
+
+func (m *Manager) emitError(err error) {
+	m.mu.RLock()
+	listeners := m.onError
+	m.mu.RUnlock()
+	for _, fn := range listeners {
+		fn(err)
+	}
+}
+
+// Subscribe subscribes to one or more public streams.
+func (m *Manager) Subscribe(streams []string, callback func(json.RawMessage)) error {
+	_, err := m.subscribe(streams, callback, false)
+	return err
+}
+
+// SubscribePrivate subscribes to private authenticated streams on the
+// private connection.
+func (m *Manager) SubscribePrivate(streams []string, callback func(json.RawMessage)) error {
+	_, err := m.subscribe(streams, callback, true)
+	return err
+}
+
+// Listen subscribes callback to one or more public streams and returns a
+// Subscription that removes only this callback when closed.
+func (m *Manager) Listen(streams []string, callback func(json.RawMessage)) (*Subscription, error) {
+	return m.listen(streams, callback, false)
+}
+
+// ListenPrivate is like Listen for private authenticated streams.
+func (m *Manager) ListenPrivate(streams []string, callback func(json.RawMessage)) (*Subscription, error) {
+	return m.listen(streams, callback, true)
+}
+
+func (m *Manager) listen(streams []string, callback func(json.RawMessage), private bool) (*Subscription, error) {
+	id, err := m.subscribe(streams, callback, private)
+	if err != nil {
+		m.remove(id, streams)
+		return nil, err
+	}
+	return newSubscription(streams, func() error {
+		return m.remove(id, streams)
+	}), nil
+}
+
+// Unsubscribe unsubscribes from one or more streams, removing all of their callbacks.
+func (m *Manager) Unsubscribe(streams []string) error {
+	m.mu.Lock()
+	byShard := make(map[*shard][]string)
+	for _, name := range streams {
+		if ms := m.streams[name]; ms != nil {
+			byShard[ms.shard] = append(byShard[ms.shard], name)
+			delete(ms.shard.streams, name)
+			delete(m.streams, name)
+		}
+	}
+	m.mu.Unlock()
+
+	var errs []error
+	for sh, names := range byShard {
+		if err := sh.client.Unsubscribe(names); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// subscribe registers callback on the streams, assigning new streams to a
+// connection, and returns the callback's ID.
+func (m *Manager) subscribe(streams []string, callback func(json.RawMessage), private bool) (uint64, error) {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return 0, fmt.Errorf("websocket: manager closed")
+	}
+
+	m.nextID++
+	s := &subscriber{id: m.nextID, fn: callback}
+	added := make(map[*shard][]*managedStream)
+	var errs []error
+	for _, name := range streams {
+		if ms := m.streams[name]; ms != nil {
+			ms.subs = append(ms.subs, s)
+			continue
+		}
+		sh, err := m.assignLocked(private)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		ms := &managedStream{name: name, private: private, shard: sh, subs: []*subscriber{s}}
+		m.streams[name] = ms
+		sh.streams[name] = true
+		added[sh] = append(added[sh], ms)
+	}
+	connected := m.connected
+	m.mu.Unlock()
+
+	for sh, list := range added {
+		if connected && !sh.client.IsConnected() {
+			ctx, cancel := context.WithTimeout(context.Background(), m.dialTimeout)
+			if err := sh.client.Connect(ctx); err != nil {
+				errs = append(errs, err)
+			}
+			cancel()
+		}
+		for _, ms := range list {
+			if err := m.attach(sh, ms, connected); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return s.id, errors.Join(errs...)
+}
+
+// remove unregisters a callback, unsubscribing streams left without callbacks.
+func (m *Manager) remove(id uint64, streams []string) error {
+	m.mu.Lock()
+	byShard := make(map[*shard][]string)
+	for _, name := range streams {
+		ms := m.streams[name]
+		if ms == nil {
+			continue
+		}
+		for i, s := range ms.subs {
+			if s.id == id {
+				ms.subs = append(ms.subs[:i:i], ms.subs[i+1:]...)
+				break
+			}
+		}
+		if len(ms.subs) == 0 {
+			byShard[ms.shard] = append(byShard[ms.shard], name)
+			delete(ms.shard.streams, name)
+			delete(m.streams, name)
+		}
+	}
+	m.mu.Unlock()
+
+	var errs []error
+	for sh, names := range byShard {
+		if err := sh.client.Unsubscribe(names); err != nil && sh.client.IsConnected() {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Rebalance evens out the number of public streams across connections by
+// moving streams from the most to the least loaded connection. It runs
+// automatically whenever a connection reconnects.
+func (m *Manager) Rebalance() error {
+	type move struct {
+		ms       *managedStream
+		from, to *shard
+	}
+	var moves []move
+
+	m.mu.Lock()
+	connected := m.connected
+	for len(m.shards) > 1 {
+		most, least := m.shards[0], m.shards[0]
+		for _, sh := range m.shards[1:] {
+			if len(sh.streams) > len(most.streams) {
+				most = sh
+			}
+			if len(sh.streams) < len(least.streams) {
+				least = sh
+			}
+		}
+		if len(most.streams)-len(least.streams) <= 1 {
+			break
+		}
+		var name string
+		for name = range most.streams {
+			break
+		}
+		ms := m.streams[name]
+		delete(most.streams, name)
+		least.streams[name] = true
+		ms.shard = least
+		moves = append(moves, move{ms: ms, from: most, to: least})
+	}
+	m.mu.Unlock()
+
+	var errs []error
+	for _, mv := range moves {
+		// Subscribe on the new connection first; messages still arriving on
+		// the old one are ignored since the stream no longer belongs to it.
+		if err := m.attach(mv.to, mv.ms, connected); err != nil {
+			errs = append(errs, err)
+		}
+		if err := mv.from.client.Unsubscribe([]string{mv.ms.name}); err != nil && mv.from.client.IsConnected() {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// attach subscribes a stream on a connection. Before the manager connects
+// the stream is only registered, and Connect subscribes it.
+func (m *Manager) attach(sh *shard, ms *managedStream, send bool) error {
+	fanout := m.fanout(sh, ms)
+	if !send {
+		sh.client.register([]string{ms.name}, fanout, ms.private)
+		return nil
+	}
+	if ms.private {
+		return sh.client.SubscribePrivate([]string{ms.name}, fanout)
+	}
+	return sh.client.Subscribe([]string{ms.name}, fanout)
+}
+
+// fanout returns the connection-level callback of a stream, which hands
+// messages to the stream's callbacks while the stream belongs to sh.
+func (m *Manager) fanout(sh *shard, ms *managedStream) func(json.RawMessage) {
+	return func(data json.RawMessage) {
+		m.mu.RLock()
+		if ms.shard != sh {
+			m.mu.RUnlock()
+			return
+		}
+		subs := ms.subs
+		m.mu.RUnlock()
+
+		for _, s := range subs {
+			s.fn(data)
+		}
+	}
+}
+
+// assignLocked returns the connection for a new stream, creating it if needed.
+func (m *Manager) assignLocked(private bool) (*shard, error) {
+	if private {
+		if m.private == nil {
+			sh, err := m.newShardLocked()
+			if err != nil {
+				return nil, err
+			}
+			if sh.client.signer == nil {
+				sh.client.Close()
+				return nil, fmt.Errorf("signer required for private streams")
+			}
+			m.private = sh
+		}
+		return m.private, nil
+	}
+
+	var best *shard
+	for _, sh := range m.shards {
+		if len(sh.streams) < m.maxStreams && (best == nil || len(sh.streams) < len(best.streams)) {
+			best = sh
+		}
+	}
+	if best != nil {
+		return best, nil
+	}
+	sh, err := m.newShardLocked()
+	if err != nil {
+		return nil, err
+	}
+	m.shards = append(m.shards, sh)
+	return sh, nil
+}
+
+func (m *Manager) newShardLocked() (*shard, error) {
+	client, err := NewClient(m.clientOpts...)
+	if err != nil {
+		return nil, err
+	}
+	client.OnError(m.emitError)
+	client.OnReconnect(func(int) {
+		if err := m.Rebalance(); err != nil {
+			m.emitError(err)
+		}
+	})
+	return &shard{client: client, streams: make(map[string]bool)}, nil
+}
+
+func (m *Manager) allShardsLocked() []*shard {
+	shards := append([]*shard(nil), m.shards...)
+	if m.private != nil {
+		shards = append(shards, m.private)
+	}
+	return shards
+}
@@ -0,0 +1,71 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// echoServer answers every SUBSCRIBE with a message on each stream.
+func echoServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			var req struct {
+				Method string   `json:"method"`
+				Params []string `json:"params"`
+			}
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			if req.Method != "SUBSCRIBE" {
+				continue
+			}
+			for _, stream := range req.Params {
+				conn.WriteJSON(map[string]any{"stream": stream, "data": map[string]string{"s": stream}})
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestManagerListenBeforeConnect(t *testing.T) {
+	server := echoServer(t)
+	m := NewManager(WithClientOptions(WithWSURL("ws" + strings.TrimPrefix(server.URL, "http"))))
+	defer m.Close()
+
+	received := make(chan string, 1)
+	sub, err := m.Listen([]string{"trade.BTC_USDC"}, func(data json.RawMessage) {
+		var msg struct{ S string }
+		json.Unmarshal(data, &msg)
+		received <- msg.S
+	})
+	if err != nil {
+		t.Fatalf("Listen before Connect: %v", err)
+	}
+	defer sub.Close()
+
+	if err := m.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case stream := <-received:
+		if stream != "trade.BTC_USDC" {
+			t.Errorf("received %q", stream)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream registered before Connect was not subscribed")
+	}
+}
@@ -0,0 +1,323 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrStaleStream is reported when a subscribed stream stops receiving messages.
+var ErrStaleStream = errors.New("websocket: stream is stale")
+
+// StaleAction decides how the client recovers a stale stream.
+type StaleAction int
+
+const (
+	// StaleResubscribe unsubscribes and resubscribes the stale stream.
+	StaleResubscribe StaleAction = iota
+	// StaleReconnect drops the connection so it is re-established with all
+	// streams resubscribed. It requires automatic reconnection.
+	StaleReconnect
+)
+
+// MonitorConfig configures per-stream liveness tracking.
+type MonitorConfig struct {
+	// Thresholds maps a stream type, such as StreamDepth or StreamBookTicker,
+	// to how long a stream of that type may stay silent before it is
+	// considered stale. The longest matching type wins, so StreamDepth200ms
+	// can be set apart from StreamDepth.
+	Thresholds map[string]time.Duration
+	// Default applies to stream types without a threshold. Zero disables
+	// stale detection for them; only their metrics are tracked.
+	Default time.Duration
+	// Action is taken when a stream goes stale.
+	Action StaleAction
+	// CheckInterval is how often streams are checked. It defaults to a
+	// second.
+	CheckInterval time.Duration
+}
+
+// DefaultMonitorConfig returns thresholds for streams that update
+// continuously. Trade, liquidation and private streams can be legitimately
+// quiet, so they are not checked.
+func DefaultMonitorConfig() MonitorConfig {
+	return MonitorConfig{
+		Thresholds: map[string]time.Duration{
+			StreamDepth:      30 * time.Second,
+			StreamBookTicker: 30 * time.Second,
+			StreamMarkPrice:  30 * time.Second,
+			StreamTicker:     time.Minute,
+		},
+		Action:        StaleResubscribe,
+		CheckInterval: time.Second,
+	}
+}
+
+// WithStreamMonitor tracks the liveness and latency of every subscribed
+// stream, and recovers streams that go stale. Metrics are available through
+// Client.StreamStats.
+func WithStreamMonitor(cfg MonitorConfig) Option {
+	return func(c *Client) error {
+		if cfg.CheckInterval <= 0 {
+			cfg.CheckInterval = time.Second
+		}
+		c.monitor = &cfg
+		c.health = make(map[string]*streamHealth)
+		return nil
+	}
+}
+
+// StreamStats describes the activity of a stream.
+type StreamStats struct {
+	Stream       string
+	Messages     uint64        // Messages received since subscribing
+	LastReceived time.Time     // Local receive time of the last message
+	LastEvent    time.Time     // Event time (E) of the last message
+	Latency      time.Duration // Receive time minus engine time (T, or E if absent) of the last message
+	AvgLatency   time.Duration // Moving average of Latency
+	MaxLatency   time.Duration // Highest Latency observed
+	Stale        bool          // Whether the stream is currently stale
+	Recoveries   int           // Number of times the stream was resubscribed or reconnected for being stale
+}
+
+type streamHealth struct {
+	stats StreamStats
+	since time.Time // Start of the current silence: last message, subscription or recovery
+}
+
+// OnStale registers a listener invoked when a stream goes stale, before it
+// is recovered, with how long it has been silent.
+func (c *Client) OnStale(fn func(stream string, silence time.Duration)) {
+	c.eventsMu.Lock()
+	c.events.onStale = append(c.events.onStale, fn)
+	c.eventsMu.Unlock()
+}
+
+func (c *Client) emitStale(stream string, silence time.Duration) {
+	c.eventsMu.RLock()
+	listeners := c.events.onStale
+	c.eventsMu.RUnlock()
+	for _, fn := range listeners {
+		fn(stream, silence)
+	}
+}
+
+// StreamStats returns the activity of a stream, or false if it is not
+// tracked. Tracking requires WithStreamMonitor.
+func (c *Client) StreamStats(stream string) (StreamStats, bool) {
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+	if h := c.health[stream]; h != nil {
+		return h.stats, true
+	}
+	return StreamStats{}, false
+}
+
+// AllStreamStats returns the activity of every tracked stream, sorted by name.
+func (c *Client) AllStreamStats() []StreamStats {
+	c.healthMu.Lock()
+	stats := make([]StreamStats, 0, len(c.health))
+	for _, h := range c.health {
+		stats = append(stats, h.stats)
+	}
+	c.healthMu.Unlock()
+
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Stream < stats[j].Stream })
+	return stats
+}
+
+// track starts tracking streams that are not tracked yet.
+func (c *Client) track(streams []string) {
+	if c.monitor == nil {
+		return
+	}
+	now := c.now()
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+	for _, stream := range streams {
+		if c.health[stream] == nil {
+			c.health[stream] = &streamHealth{stats: StreamStats{Stream: stream}, since: now}
+		}
+	}
+}
+
+// untrack stops tracking a stream.
+func (c *Client) untrack(stream string) {
+	if c.monitor == nil {
+		return
+	}
+	c.healthMu.Lock()
+	delete(c.health, stream)
+	c.healthMu.Unlock()
+}
+
+// resetHealth restarts the silence of every stream, e.g. after reconnecting.
+func (c *Client) resetHealth() {
+	if c.monitor == nil {
+		return
+	}
+	now := c.now()
+	c.healthMu.Lock()
+	for _, h := range c.health {
+		h.since = now
+		h.stats.Stale = false
+	}
+	c.healthMu.Unlock()
+}
+
+// observe records a message received on a stream.
+func (c *Client) observe(stream string, data json.RawMessage) {
+	if c.monitor == nil {
+		return
+	}
+	received := c.now()
+
+	var ts struct {
+		EventTime       int64 `json:"E"`
+		EngineTimestamp int64 `json:"T"`
+	}
+	_ = json.Unmarshal(data, &ts)
+
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+	h := c.health[stream]
+	if h == nil {
+		return
+	}
+	h.since = received
+	h.stats.Stale = false
+	h.stats.Messages++
+	h.stats.LastReceived = received
+	if ts.EventTime > 0 {
+		h.stats.LastEvent = unixTime(ts.EventTime)
+	}
+
+	engine := ts.EngineTimestamp
+	if engine == 0 {
+		engine = ts.EventTime
+	}
+	if engine > 0 {
+		latency := received.Sub(unixTime(engine))
+		h.stats.Latency = latency
+		if h.stats.AvgLatency == 0 {
+			h.stats.AvgLatency = latency
+		} else {
+			h.stats.AvgLatency += (latency - h.stats.AvgLatency) / 10
+		}
+		if latency > h.stats.MaxLatency {
+			h.stats.MaxLatency = latency
+		}
+	}
+}
+
+// monitorLoop checks the streams of a connection until it is done.
+func (c *Client) monitorLoop(done chan struct{}) {
+	ticker := time.NewTicker(c.monitor.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			c.checkStale()
+		}
+	}
+}
+
+// checkStale recovers streams that have been silent for longer than their threshold.
+func (c *Client) checkStale() {
+	now := c.now()
+	type staleStream struct {
+		stream  string
+		silence time.Duration
+	}
+	var stale []staleStream
+
+	c.healthMu.Lock()
+	for stream, h := range c.health {
+		threshold := c.monitor.threshold(stream)
+		if threshold <= 0 || h.stats.Stale {
+			continue
+		}
+		if silence := now.Sub(h.since); silence >= threshold {
+			h.stats.Stale = true
+			h.stats.Recoveries++
+			stale = append(stale, staleStream{stream, silence})
+		}
+	}
+	c.healthMu.Unlock()
+
+	if len(stale) == 0 {
+		return
+	}
+	sort.Slice(stale, func(i, j int) bool { return stale[i].stream < stale[j].stream })
+
+	for _, s := range stale {
+		c.emitStale(s.stream, s.silence)
+	}
+
+	if c.monitor.Action == StaleReconnect {
+		c.drop(fmt.Errorf("%w: %s", ErrStaleStream, stale[0].stream))
+		return
+	}
+	for _, s := range stale {
+		if err := c.resubscribeStream(s.stream); err != nil {
+			c.emitError(err)
+		}
+	}
+}
+
+// resubscribeStream unsubscribes and resubscribes a single stream, keeping
+// its callbacks.
+func (c *Client) resubscribeStream(stream string) error {
+	c.mu.RLock()
+	private := c.privateStreams[stream]
+	c.mu.RUnlock()
+
+	if err := c.request("UNSUBSCRIBE", []string{stream}, nil); err != nil {
+		return fmt.Errorf("websocket: resubscribe %s failed: %w", stream, err)
+	}
+	var signature []string
+	if private && c.signer != nil {
+		var err error
+		if signature, err = c.signature(); err != nil {
+			return fmt.Errorf("websocket: resubscribe %s failed: %w", stream, err)
+		}
+	}
+	if err := c.request("SUBSCRIBE", []string{stream}, signature); err != nil {
+		return fmt.Errorf("websocket: resubscribe %s failed: %w", stream, err)
+	}
+
+	// Give the stream a full threshold to resume.
+	c.healthMu.Lock()
+	if h := c.health[stream]; h != nil {
+		h.since = c.now()
+		h.stats.Stale = false
+	}
+	c.healthMu.Unlock()
+	return nil
+}
+
+// threshold returns the staleness threshold of a stream.
+func (cfg *MonitorConfig) threshold(stream string) time.Duration {
+	best, threshold := -1, cfg.Default
+	for kind, d := range cfg.Thresholds {
+		if (stream == kind || strings.HasPrefix(stream, kind+".")) && len(kind) > best {
+			best, threshold = len(kind), d
+		}
+	}
+	return threshold
+}
+
+// unixTime converts a Unix timestamp in microseconds, as sent in stream
+// messages, to a time. Millisecond timestamps are also accepted.
+func unixTime(v int64) time.Time {
+	if v < 1e14 {
+		return time.UnixMilli(v)
+	}
+	return time.UnixMicro(v)
+}
@@ -0,0 +1,94 @@
+package websocket
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Record is a frame received from the server. Recordings are gzip
+// compressed files with one JSON encoded Record per line.
+type Record struct {
+	Time  time.Time `json:"t"` // Local receive time
+	Frame string    `json:"f"` // Raw frame as received
+}
+
+// Recorder writes received frames to a recording. It is safe for concurrent use.
+type Recorder struct {
+	mu     sync.Mutex
+	gz     *gzip.Writer
+	enc    *json.Encoder
+	closer io.Closer
+}
+
+// NewRecorder creates a Recorder writing a recording to w. Close must be
+// called to flush the recording; it does not close w.
+func NewRecorder(w io.Writer) *Recorder {
+	gz := gzip.NewWriter(w)
+	return &Recorder{gz: gz, enc: json.NewEncoder(gz)}
+}
+
+// CreateRecording creates or truncates the file at path and returns a
+// Recorder writing to it. Close closes the file.
+func CreateRecording(path string) (*Recorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("websocket: failed to create recording: %w", err)
+	}
+	r := NewRecorder(f)
+	r.closer = f
+	return r, nil
+}
+
+// Write records a frame received at the given time. The client records the
+// local time rather than that of its WithClock clock, so the intervals
+// between frames do not jump when the measured server offset changes.
+func (r *Recorder) Write(t time.Time, frame []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.enc == nil {
+		return fmt.Errorf("websocket: recorder closed")
+	}
+	return r.enc.Encode(Record{Time: t, Frame: string(frame)})
+}
+
+// Flush writes buffered records to the underlying writer.
+func (r *Recorder) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.enc == nil {
+		return nil
+	}
+	return r.gz.Flush()
+}
+
+// Close flushes the recording and closes the file if the Recorder was
+// created by CreateRecording.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.enc == nil {
+		return nil
+	}
+	r.enc = nil
+	err := r.gz.Close()
+	if r.closer != nil {
+		if closeErr := r.closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// WithRecorder records every frame received by the client, including
+// subscription responses, to r.
+func WithRecorder(r *Recorder) Option {
+	return func(c *Client) error {
+		c.recorder = r
+		return nil
+	}
+}
@@ -0,0 +1,234 @@
+package websocket
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Replayer feeds a recording to registered callbacks. It implements
+// Subscriber, so the Handler and feeds used with a live Client can be
+// driven by a recording instead. Messages are delivered one at a time, in
+// recorded order, from the goroutine calling Run, which makes replays
+// deterministic.
+type Replayer struct {
+	src    io.Reader
+	closer io.Closer
+	speed  float64
+
+	mu        sync.RWMutex
+	callbacks map[string][]*subscriber
+	nextID    uint64
+	onError   []func(error)
+	now       time.Time
+}
+
+// ReplayOption is a functional option for configuring a Replayer.
+type ReplayOption func(*Replayer)
+
+// WithSpeed sets the replay speed relative to the recording: 1 replays at
+// the original pace, 10 ten times faster, and 0 (the default) as fast as
+// possible.
+func WithSpeed(speed float64) ReplayOption {
+	return func(r *Replayer) {
+		r.speed = speed
+	}
+}
+
+// NewReplayer creates a Replayer reading a recording from src.
+func NewReplayer(src io.Reader, opts ...ReplayOption) *Replayer {
+	r := &Replayer{
+		src:       src,
+		callbacks: make(map[string][]*subscriber),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// OpenRecording opens the recording at path for replay. Close closes the file.
+func OpenRecording(path string, opts ...ReplayOption) (*Replayer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("websocket: failed to open recording: %w", err)
+	}
+	r := NewReplayer(f, opts...)
+	r.closer = f
+	return r, nil
+}
+
+// Close closes the recording file if the Replayer was created by OpenRecording.
+func (r *Replayer) Close() error {
+	if r.closer != nil {
+		return r.closer.Close()
+	}
+	return nil
+}
+
+// Run replays the recording until it ends or ctx is done. It returns nil
+// at the end of the recording.
+func (r *Replayer) Run(ctx context.Context) error {
+	gz, err := gzip.NewReader(r.src)
+	if err != nil {
+		return fmt.Errorf("websocket: invalid recording: %w", err)
+	}
+	defer gz.Close()
+
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	var first, start time.Time
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return fmt.Errorf("websocket: invalid recording: %w", err)
+		}
+
+		if r.speed > 0 {
+			if first.IsZero() {
+				first, start = rec.Time, time.Now()
+			}
+			offset := time.Duration(float64(rec.Time.Sub(first)) / r.speed)
+			if wait := time.Until(start.Add(offset)); wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return ctx.Err()
+				case <-timer.C:
+				}
+			}
+		}
+
+		r.mu.Lock()
+		r.now = rec.Time
+		r.mu.Unlock()
+		r.deliver([]byte(rec.Frame))
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("websocket: failed to read recording: %w", err)
+	}
+	return nil
+}
+
+// Now returns the receive time of the frame being replayed. It implements
+// timesync.Clock, so components can run on recorded time.
+func (r *Replayer) Now() time.Time {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.now
+}
+
+// OnError registers a listener invoked for frames that cannot be decoded.
+func (r *Replayer) OnError(fn func(error)) {
+	r.mu.Lock()
+	r.onError = append(r.onError, fn)
+	r.mu.Unlock()
+}
+
+func (r *Replayer) emitError(err error) {
+	r.mu.RLock()
+	listeners := r.onError
+	r.mu.RUnlock()
+	for _, fn := range listeners {
+		fn(err)
+	}
+}
+
+func (r *Replayer) deliver(frame []byte) {
+	var msg struct {
+		Stream string          `json:"stream"`
+		Data   json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(frame, &msg); err != nil {
+		r.emitError(fmt.Errorf("websocket: invalid message: %w", err))
+		return
+	}
+	if msg.Stream == "" {
+		return
+	}
+
+	r.mu.RLock()
+	callbacks := r.callbacks[msg.Stream]
+	r.mu.RUnlock()
+
+	for _, s := range callbacks {
+		s.fn(msg.Data)
+	}
+}
+
+// Subscribe registers callback on the streams.
+func (r *Replayer) Subscribe(streams []string, callback func(json.RawMessage)) error {
+	r.add(streams, callback)
+	return nil
+}
+
+// SubscribePrivate registers callback on the streams. Recordings need no
+// authentication, so it is the same as Subscribe.
+func (r *Replayer) SubscribePrivate(streams []string, callback func(json.RawMessage)) error {
+	return r.Subscribe(streams, callback)
+}
+
+// Listen registers callback on the streams and returns a Subscription that
+// removes it.
+func (r *Replayer) Listen(streams []string, callback func(json.RawMessage)) (*Subscription, error) {
+	id := r.add(streams, callback)
+	return newSubscription(streams, func() error {
+		r.remove(id, streams)
+		return nil
+	}), nil
+}
+
+// ListenPrivate is the same as Listen.
+func (r *Replayer) ListenPrivate(streams []string, callback func(json.RawMessage)) (*Subscription, error) {
+	return r.Listen(streams, callback)
+}
+
+// Unsubscribe removes every callback of the streams.
+func (r *Replayer) Unsubscribe(streams []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, stream := range streams {
+		delete(r.callbacks, stream)
+	}
+	return nil
+}
+
+func (r *Replayer) add(streams []string, callback func(json.RawMessage)) uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	s := &subscriber{id: r.nextID, fn: callback}
+	for _, stream := range streams {
+		r.callbacks[stream] = append(r.callbacks[stream], s)
+	}
+	return s.id
+}
+
+func (r *Replayer) remove(id uint64, streams []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, stream := range streams {
+		subs := r.callbacks[stream]
+		for i, s := range subs {
+			if s.id == id {
+				r.callbacks[stream] = append(subs[:i:i], subs[i+1:]...)
+				break
+			}
+		}
+		if len(r.callbacks[stream]) == 0 {
+			delete(r.callbacks, stream)
+		}
+	}
+}
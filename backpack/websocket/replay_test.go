@@ -0,0 +1,169 @@
+package websocket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// frame is a message sent by pacedServer after a pause.
+type frame struct {
+	stream string
+	pause  time.Duration
+}
+
+// pacedServer sends the frames once the client has sent a subscription
+// request for each of their streams.
+func pacedServer(t *testing.T, frames []frame) *httptest.Server {
+	t.Helper()
+	streams := make(map[string]bool)
+	for _, f := range frames {
+		streams[f.stream] = true
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for range streams {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+		for i, f := range frames {
+			time.Sleep(f.pause)
+			conn.WriteJSON(map[string]any{"stream": f.stream, "data": map[string]int{"i": i}})
+		}
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// delivery is a message received by a callback.
+type delivery struct {
+	stream string
+	i      int
+	at     time.Time
+}
+
+// collector records the deliveries of several streams.
+type collector struct {
+	mu         sync.Mutex
+	deliveries []delivery
+}
+
+func (c *collector) on(stream string) func(json.RawMessage) {
+	return func(data json.RawMessage) {
+		var msg struct{ I int }
+		json.Unmarshal(data, &msg)
+		c.mu.Lock()
+		c.deliveries = append(c.deliveries, delivery{stream, msg.I, time.Now()})
+		c.mu.Unlock()
+	}
+}
+
+func (c *collector) get() []delivery {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]delivery(nil), c.deliveries...)
+}
+
+func TestRecordReplay(t *testing.T) {
+	frames := []frame{
+		{"depth.SOL_USDC", 0},
+		{"trade.SOL_USDC", 50 * time.Millisecond},
+		{"depth.SOL_USDC", 0},
+		{"trade.SOL_USDC", 100 * time.Millisecond},
+	}
+	server := pacedServer(t, frames)
+
+	var recording bytes.Buffer
+	recorder := NewRecorder(&recording)
+	// A clock far off the local one must not affect the recorded times.
+	c, err := NewClient(
+		WithWSURL("ws"+strings.TrimPrefix(server.URL, "http")),
+		WithRecorder(recorder),
+		WithClock(offsetClock(time.Hour)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	live := &collector{}
+	for _, stream := range []string{"depth.SOL_USDC", "trade.SOL_USDC"} {
+		if err := c.Subscribe([]string{stream}, live.on(stream)); err != nil {
+			t.Fatalf("Subscribe: %v", err)
+		}
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for len(live.get()) < len(frames) {
+		if time.Now().After(deadline) {
+			t.Fatalf("received %d of %d frames", len(live.get()), len(frames))
+		}
+		time.Sleep(time.Millisecond)
+	}
+	c.Close()
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("Close recorder: %v", err)
+	}
+
+	r := NewReplayer(&recording, WithSpeed(1))
+	replayed := &collector{}
+	for _, stream := range []string{"depth.SOL_USDC", "trade.SOL_USDC"} {
+		r.Subscribe([]string{stream}, replayed.on(stream))
+	}
+	var first time.Time
+	r.Subscribe([]string{"depth.SOL_USDC"}, func(json.RawMessage) {
+		if first.IsZero() {
+			first = r.Now()
+		}
+	})
+	if err := r.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	got := replayed.get()
+	if len(got) != len(frames) {
+		t.Fatalf("replayed %d frames, want %d", len(got), len(frames))
+	}
+	for i, d := range got {
+		if d.i != i || d.stream != frames[i].stream {
+			t.Errorf("frame %d replayed as %s #%d, want %s #%d", i, d.stream, d.i, frames[i].stream, i)
+		}
+	}
+	if since := time.Since(first); since > time.Minute || since < 0 {
+		t.Errorf("recorded time is %v before now, want the local time", since)
+	}
+
+	// Replayed at the original pace, frames keep their spacing.
+	want := live.get()
+	for i := 1; i < len(got); i++ {
+		recorded := want[i].at.Sub(want[0].at)
+		if replay := got[i].at.Sub(got[0].at); replay < recorded-20*time.Millisecond || replay > recorded+20*time.Millisecond {
+			t.Errorf("frame %d replayed %v after the first, want %v", i, replay, recorded)
+		}
+	}
+}
+
+// offsetClock is a clock running ahead of the local one.
+type offsetClock time.Duration
+
+func (o offsetClock) Now() time.Time {
+	return time.Now().Add(time.Duration(o))
+}
@@ -0,0 +1,147 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// DefaultFeedBuffer is the channel buffer size of a Feed.
+const DefaultFeedBuffer = 256
+
+// subscriber is a callback registered on one or more streams.
+type subscriber struct {
+	id uint64
+	fn func(json.RawMessage)
+}
+
+// Subscription is a callback registered with Listen or ListenPrivate.
+type Subscription struct {
+	streams []string
+	close   func() error
+	once    sync.Once
+	err     error
+}
+
+func newSubscription(streams []string, close func() error) *Subscription {
+	return &Subscription{streams: streams, close: close}
+}
+
+// Streams returns the streams of the subscription.
+func (s *Subscription) Streams() []string {
+	return s.streams
+}
+
+// Close removes the subscription's callback. Streams left without any
+// callback are unsubscribed from the server. Close is idempotent.
+func (s *Subscription) Close() error {
+	s.once.Do(func() {
+		s.err = s.close()
+	})
+	return s.err
+}
+
+// errorReporter is implemented by subscribers that report errors to
+// OnError listeners.
+type errorReporter interface {
+	emitError(err error)
+}
+
+// Feed delivers the decoded messages of a stream on a channel. Messages
+// that cannot be decoded are reported to the subscriber's OnError listeners.
+type Feed[T any] struct {
+	// C receives the messages. It is closed when the feed is closed.
+	C <-chan T
+
+	sub      *Subscription
+	ch       chan T
+	done     chan struct{}
+	once     sync.Once
+	closeErr error
+
+	mu     sync.RWMutex
+	closed bool
+	err    error
+}
+
+// NewFeed subscribes to stream and delivers its messages decoded as T on
+// the returned feed's channel until Close is called or ctx is done. The
+// client's delivery mode applies: with ordered delivery a consumer that
+// falls behind triggers the overflow policy, otherwise each pending message
+// waits in its own goroutine.
+func NewFeed[T any](ctx context.Context, s Subscriber, stream string, private bool) (*Feed[T], error) {
+	f := &Feed[T]{
+		ch:   make(chan T, DefaultFeedBuffer),
+		done: make(chan struct{}),
+	}
+	f.C = f.ch
+
+	listen := s.Listen
+	if private {
+		listen = s.ListenPrivate
+	}
+	sub, err := listen([]string{stream}, func(data json.RawMessage) {
+		var msg T
+		if err := json.Unmarshal(data, &msg); err != nil {
+			if r, ok := s.(errorReporter); ok {
+				r.emitError(fmt.Errorf("websocket: invalid %s message: %w", stream, err))
+			}
+			return
+		}
+		f.send(msg)
+	})
+	if err != nil {
+		return nil, err
+	}
+	f.sub = sub
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			f.close(ctx.Err())
+		case <-f.done:
+		}
+	}()
+	return f, nil
+}
+
+// Close unsubscribes the feed and closes its channel.
+func (f *Feed[T]) Close() error {
+	return f.close(nil)
+}
+
+// Err returns the context error if the feed was closed because its
+// context was done, and nil otherwise.
+func (f *Feed[T]) Err() error {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.err
+}
+
+func (f *Feed[T]) send(msg T) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.closed {
+		return
+	}
+	select {
+	case f.ch <- msg:
+	case <-f.done:
+	}
+}
+
+func (f *Feed[T]) close(reason error) error {
+	f.once.Do(func() {
+		// Unblock pending sends before taking the write lock.
+		close(f.done)
+		f.closeErr = f.sub.Close()
+
+		f.mu.Lock()
+		f.closed = true
+		f.err = reason
+		close(f.ch)
+		f.mu.Unlock()
+	})
+	return f.closeErr
+}
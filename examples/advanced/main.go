@@ -115,22 +115,24 @@ func errorHandlingExample(ctx context.Context, client *backpack.Client) {
 	})
 
 	if err != nil {
+		// Handle specific error codes with errors.Is
+		switch {
+		case errors.Is(err, errors.ErrInsufficientFunds):
+			fmt.Println("Action: Deposit more funds")
+		case errors.Is(err, errors.ErrResourceNotFound):
+			fmt.Println("Action: Resource may have been deleted or not found")
+		case errors.IsRateLimited(err):
+			fmt.Println("Action: Wait before retrying")
+		case errors.IsAuthFailure(err):
+			fmt.Println("Action: Check API credentials and clock")
+		case errors.IsRetryable(err):
+			fmt.Println("Action: Retry with backoff")
+		}
+
 		// Check if it's an API error
 		if apiErr, ok := errors.IsAPIError(err); ok {
 			fmt.Printf("API Error: Status=%d, Code=%s, Message=%s\n",
 				apiErr.StatusCode, apiErr.Code, apiErr.Message)
-
-			// Handle specific error codes
-			switch {
-			case apiErr.HasCode(errors.ErrCodeInsufficientFunds):
-				fmt.Println("Action: Deposit more funds")
-			case apiErr.HasCode(errors.ErrCodeResourceNotFound):
-				fmt.Println("Action: Resource may have been deleted or not found")
-			case apiErr.HasCode(errors.ErrCodeTooManyRequests):
-				fmt.Println("Action: Wait before retrying")
-			default:
-				fmt.Printf("Unhandled error code: %s\n", apiErr.Code)
-			}
 		} else if reqErr, ok := errors.IsRequestError(err); ok {
 			// Network or request-level error
 			fmt.Printf("Request Error: %s %s - %s\n", reqErr.Method, reqErr.URL, reqErr.Message)
@@ -1,5 +1,7 @@
 module github.com/solomeowl/backpack-exchange-sdk-go
 
-go 1.23
+go 1.23.0
 
 require github.com/gorilla/websocket v1.5.0
+
+require golang.org/x/crypto v0.41.0